@@ -22,16 +22,18 @@ import (
 	"github.com/door2doc/d2d-uploader/pkg/uploader"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+	pwd "github.com/door2doc/d2d-uploader/pkg/uploader/password"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
 )
 
 var (
 	username = flag.String("username", "test", "Username for connecting to the upload service")
 	password = flag.String("password", "", "Password for connecting to the upload service")
-	server   = flag.String("server", config.Server, "Server to upload to")
+	server   = flag.String("server", config.ServerProduction, "Server to upload to")
 	from     = flag.Int("from", 0, "Skip all mutaties < from")
 	test     = flag.Bool("test", true, "Use test mode")
 	batch    = flag.Int("batch", 100, "Batch size")
+	stream   = flag.Bool("stream", false, "Upload all records in a single streamed request, instead of in batches")
 )
 
 type record struct {
@@ -107,6 +109,23 @@ func run() error {
 		log.Printf("Got  %v", header)
 		return errors.New("unsupported format")
 	}
+
+	// all batches share one configuration, such that connections are reused
+	cfg := config.NewConfiguration()
+	cfg.Load(config.DataV2{
+		Version:  2,
+		Username: *username,
+		Password: pwd.Password(*password),
+		Server:   *server,
+	})
+	u := uploader.Uploader{
+		Configuration: cfg,
+	}
+
+	if *stream {
+		return runStream(r, &u)
+	}
+
 	for {
 		csvRecords, err := readBatch(r, *batch)
 		if err == io.EOF {
@@ -136,13 +155,6 @@ func run() error {
 			return err
 		}
 
-		cfg := config.NewConfiguration()
-		cfg.SetCredentials(*username, *password)
-
-		u := uploader.Uploader{
-			Configuration: cfg,
-		}
-
 		if *test {
 			log.Println(buf)
 			break
@@ -161,13 +173,62 @@ func run() error {
 	return nil
 }
 
+// runStream converts all records read from r, and uploads them as newline-delimited JSON in a single request. The
+// request cannot be repeated, so it is not retried on failure.
+func runStream(r *csv.Reader, u *uploader.Uploader) error {
+	write := func(enc *rest.StreamEncoder) error {
+		for {
+			csvRecords, err := readBatch(r, *batch)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			visitorRecords, err := toVisitorRecords(csvRecords)
+			if err != nil {
+				return err
+			}
+			upload, err := rest.VisitorRecordsFromDB(visitorRecords, timezone)
+			if err != nil {
+				return err
+			}
+			for i := range upload {
+				if err := enc.Encode(&upload[i]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if *test {
+		enc := rest.NewStreamEncoder(os.Stdout)
+		if err := write(enc); err != nil {
+			return err
+		}
+		log.Printf("Converted %d records", enc.Count())
+		return nil
+	}
+
+	n, err := u.UploadStream(context.Background(), config.PathVisitorUpload, true, write)
+	if err != nil {
+		return err
+	}
+	log.Printf("Upload of %d records OK", n)
+	return nil
+}
+
 func ping() error {
-	req, err := http.NewRequest(http.MethodGet, *server, nil)
+	endpoint, err := config.Endpoint(*server, config.PathPing)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
 	if err != nil {
 		return err
 	}
 	req.SetBasicAuth(*username, *password)
-	req.URL.Path = config.PathPing
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
@@ -13,13 +13,17 @@ import (
 	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
 	"github.com/kardianos/service"
 	"github.com/lib/pq"
+	"github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
 )
 
 var _ mssql.Driver
 var _ pq.Driver
+var _ driver.SQLite
 
 var (
 	DevelopmentMode = flag.Bool("dev", false, "Run in development mode - this will cause live reloading of HTML templates.")
+	Listen          = flag.String("listen", "", "Address of the configuration interface, such as localhost:17226. Overrides the configured address.")
 )
 
 func main() {
@@ -30,7 +34,11 @@ func main() {
 		DisplayName: "Door2doc Upload Service",
 		Description: "This service takes care of regular uploads to door2doc",
 	}
-	svc := uploader.NewService(*DevelopmentMode, Version)
+	if *Listen != "" {
+		// pass the address on to the installed service
+		config.Arguments = []string{"-listen", *Listen}
+	}
+	svc := uploader.NewService(*DevelopmentMode, Version, *Listen)
 	s, err := service.New(svc, config)
 	if err != nil {
 		log.Fatalf("Failed to construct service: %v", err)
@@ -61,6 +69,9 @@ func main() {
 			fmt.Println("\tstart       Start the service")
 			fmt.Println("\tstop        Stop the service")
 			fmt.Println("\trestart     Restart the service")
+			fmt.Println()
+			fmt.Println("Use -listen before install to serve the configuration interface on another address, such as")
+			fmt.Println("-listen localhost:8080.")
 			return
 		}
 
@@ -212,44 +212,111 @@ var _escData = map[string]*_escFile{
 	"/_layout.html": {
 		name:    "_layout.html",
 		local:   "pkg/uploader/assets/resources/_layout.html",
-		size:    5132,
-		modtime: 1619966579,
+		size:    7697,
+		modtime: 1792362582,
 		compressed: `
-H4sIAAAAAAAC/9RYS3PbNhC+61escQ7I+nFoO6RmUqfTSzvjxk16BoEViRoEaGApRePRf++AD5l6OGPl
-kMg+iAS4H/DtftiFgexCOUnrBqGi2sxnWXyAEbbMGVoWO1Co+QwAILvgHD7iY6s9KqiRBJAoA3A+fO+6
-ZCV8QMpZSwv+M5t+sqLGnC01rhrniYF0ltBSzlZaUZUrXGqJvGu8A201aWF4kMJgfvkOQuW1feDk+EJT
-bh2bz55p/eYcBfKigdv7+2dGRtsH8GhyFmhtMFSIxKDyuMhZKkJACmkxQpNa20SGwNIT0LIN5OoR1uNI
-k8H5B+f8lXISPjXGCYUeODw9AWHdGEEIrDNjsNlkaY+YZWkf7axwaj2fZUovQRoRQs4W2pgxmFZsu61Y
-FsJD/+BGlxVBUfYvg3kHEbsAXnhh1daXiWVnresSOko5G71gIAzljEHw8tl740qXNLZkUGGcMmfXP02n
-TcWk0Zo9EtGP2nPRkttnYPTElmvCGoQkvcQ9wwPneBRt4titswtdtl6QdnaHT0/Q6Cnd1kxak+gPhAm/
-0B6Bz+iDdjYqm4zvm81kSKWXg2ypFcthiTw9gV5A8odxhTC/e+/8CJrOKgx6gu6XK2FL9NPYVjc7djwu
-HW1LNv9kPUq3RC8Kg4Bx9CytbibQZteH9xbaQww4KVvvUSVwZ1AE7PJVSAI1ruzQNjGTE/inGkEeYw8q
-0OHXSRSal2fPpFM4j/HbDUeWdh9eN8hf4gEhtB6BHEhnDMoYORPTF5fCEgT0sbyAcWWAVYV2dEfbcutR
-cjjboOAoG5qAUasDsbrRtEXPF6bVChYGv/DSuxW/BMVjq++SzrS1ZcfXmXerHSA0a36znxuT9JfO8FDz
-ayicV+i538v7I7lndKA4fNvA82vkHKojwCG/xnQ6+h3gcOw+ZffaPEbb2TEc/7WB9GLNh22AF0grRAvC
-6NJ2gMAlWkI/5As+QnInqAKWxrI5VIROFatgs3mBf/y7J0FtOO7eflE49FsJEoUIeD7+bxmdGIcPAy4u
-fosdnRdthyJ1511hsA7JFjupb0fjFhphx5AUQpUI3e9Qw4ZG021oF1karedfI9E79a3aPbbo1z9MuEPl
-ej4nyvZZB03OQwd+tWB/R+u3pVZXxkLqhdLOuPKchDugdqKGH0fg61T8V3irbRmSZ9y3Kqntwu3oqL+X
-jkYU56dgJHWidn+K4kTVIuLt6SWdDa2h89NsJHaibrc97ETtRtQb0q/tzphnpNtA6ES9+qPyqze43vxt
-7XBCSgzhjJQaCJ36DzXK1mt6fVK976b5mlY/Np92D/+TA/tOVy203Tt8/XLsvFVdjVbVNTTEL6Ep+BWb
-v3QBVF0dDrJjWzi1ZpDsu5SlkdF8/75hdhiI6WF2+xjumdLu8u//AAAA///7uoYJDBQAAA==
+H4sIAAAAAAAC/9RZX4/buBF/308xIfpwB4RSsxvg2sIykO5d24e0SHeTK/pUUOJYYpciFXLojWHsdy+o
+P7b8b2sFF8T7YknUzHDm9+OM6OHslbQFrRqEimo9v5rFC2hhyoyhYXEAhZxfAQDMXnEOd/g5KIcSaiQB
+JEoPnPfv26GiEs4jZSzQgv+BjV8ZUWPGlgofG+uIQWENoaGMPSpJVSZxqQrk7cNrUEaREpr7QmjM3rwG
+XzllHjhZvlCUGcvmV1u3/mwteXKigdv7+61HWpkHcKgz5mml0VeIxKByuMhYKrxH8mk+qCa1MknhPUsn
+aBfBk60HtU6PFGmc/2ytu5a2gE+NtkKiAw7rNRDWjRaEwFoxBk9Ps7TTuJqlHdqz3MrV/Gom1RIKLbzP
+2EJpPYBpxGbYiGUuHHQXrlVZEeRld9OLAwDMxK4Cz50wchPLSBIAYKbqElqXMjZEwUBoyhgD74pt9NqW
+NmlMyaDCOGXGbn4/njYVo4eg95yIcdSOi0B23wOtRrJcEdYgClJL3BM8CI5H0kaB3VqzUGVwgpQ1O/50
+Dmo1djfo7dN6DWoBySePLvmHqBGennZ1F9bVMQ0qKzPWWE+s9dGajLXABGKDY1GUK6OVwRjxzbEolGkC
+QczFjFVKSjSsz5jCu8V/yD7EkaXQATO2XsPvktv7u798jMPw9HTMom+E2YOc8AtFD67ZfL3eDQ5+2Izc
+WR1Hfpyl0cQRy3kgsqZ31oe8VttYczKQk+G+bi82UAybeyyskcKt2Py9LcEGmqWdmX1KIlg7NKCRY/DH
+aTEKaw+BX9F5ZU3UT4b7sZFUqmWfT6kRyz53e9L/qm0u9C/OWTcojWcVGh1B+8ulMCW68aKv3u7I8ZjT
+ypRs/sk4LOwSncg1Akbrs7R6O1JttvcAAO8MhEMdsEURnEOZwAeNwmNbSEVBIPtkBR+aWGIT+FgNSg7j
+CEpQ/k8jFJrTs88KK7FdJrtwzNL2xXlG/i4eEHxwCGShsFpjEZHTsa7iUhgCjy7WfdC29PBYoRnCUabc
+RJQcztYzONCG2rcpekBWa00ZdHyhg5Kw0PiFl84+8jcgeXzqhgqrQ23GRI6MOPu4owjNir/dL1qjulxY
+zX3NbyC3TqLjbq8gHymKWnmK5kMD29vos6+OKPaFb6hzR98DHNruauneM+/q1gDHf4MntVjx/vvMc6RH
+RANCq9K0Cp4XaAhdny/4GZIPgipgafye9aV6m7on/AcAuCdBwR8Pb79aH8YtBYlceLyc+DceTcTh514v
+Ln6DrTsnZfsi9cHZXGPtk43u3hfq2Q9CLmSJ0P72Nax/aNqdxqtTtf90XZ7I3eeAbvXdiDtkrvNnIm2/
+Kq/IOmiVzybsn1H6JbC1BWlnH5X0Yd/b4AoEVumf2EnbG8Kj1OXQ3fs8hey/vf8JavRelOgn4/gc/BuI
+2o+FT52QympbXlJ6HLg2Eby7QfG8XPmXcEaZ0idbva/NF2UWdidb1DevbT1YWuSXx2B0aiJ370U+kbWo
+8fL4KqzxQdPlcTY4NpG3205tIneD1gviL7QtlgvirXdoIl9dp+jsbUQn/rJ2fQU6UgtVCEJ/QXztuDU1
+y0a65yfZSOk5Br9/lo37YLfCABOyVuaM/Z4oCvSXRHLv0NR/qFgEp+j8+vmunebiST1rQ1pUsUhcVKb2
+Hk1N0k7t/5HYi/025K3XoNGMjX7z8iqCVJe0f+n8mcjVu6gE2pbno7DbuodtV3dnqBbK7HXo/nisKVdd
+D1LVDTTE30CT9y3zo8c31fWhkQ4KYwli1fwlRnSK1hOd5VHH/CRU/7bBgbMaQWhtHz2sbACyEA/ZXkMe
+qPWALHRpA1QheKTYW/VgDVClPDSixOQE1psu+XnLtItaGLld9T8YPEjeH6diEXPrGRg+VugQhENo0MRm
+ex+vB6oEQSWW2AKRtwu3abRCCSuk5HSWH5TA3aZ+e8w0v8MIdBtvtLoapj2dqV8B6HbJxWNBBsm+3CyN
+C3u+f7Zx5Ahl3DjfXPrDxrQ9Af7fAN1PWQsRHgAA
+`,
+	},
+
+	"/_preview.html": {
+		name:    "_preview.html",
+		local:   "pkg/uploader/assets/resources/_preview.html",
+		size:    2822,
+		modtime: 1792365287,
+		compressed: `
+H4sIAAAAAAAC/7xW34/jNBB+37/ikwUIHpIgwb1waRG6ReIQWhZ2j3e3njRmHTvYk3ZLr/87cpK26c+9
+itO+JZPxfN83MxnPagVFhbYEUXuaa1oIrNc3ALBaYaG5RHrrZcEbKwDkSs8xNTKEkZhKr1Atk+/FePv9
+lE9SklTkwfTMyaLUTBFAF0jfh7+k0Qrr9WSWaFu41QpkAnUGJe2MfDTZ6HKAAgCPFBieQmM44KtKyVC+
+BZc64J+G/BI6wDqGnLKe0z7HTOn5C7QnTi1PgPbcbyXLiQz0zllLU9bODvO0F7ger1YnD3xE2VTS6n+j
+4jyrT4K1CYmIf0RNP3vv/AtIA8dPRzgf8qQdAFocsHNPyAN7Z2ed0MbLKC99oKmzKuAjaq8tFxBffpt+
+V4j1OuRZfwDSKnjixltSZ5H6nD/6xk4lU2wHyTAkA2/7Y4/Db2Rbwb0J3i2+Dt+cCPMWzpoluKRAZ+Gl
+J4TSLewWLMWHQMipGv8Um0sy5RlVY7BDJZ8ImmPIvvO6bkxP5zc7k+CeayfkLLO8fDPeNFYUGfKsfHO+
+Ym2PB14aGgk3J18Yt0ief0CYemeMOH+wZ5T+2f1s6aOcGLqXM8IX6b2cadtV/L1V9Lxveuh67zyl4z/x
+UOCH2jip8OvD73cvyKs9teWPrtewy7N48lL7tfNwePSSJgDIrZxfzicA5I3ZzJx6F/rvJrAulsnUWSbL
+yZQskxcvhwOA3OhBSEo0U9V3UxyG6S8y3Md575qA9VrpEEupLo3Zs0hyD8ho+yRQeipG4sdoGcVSDLDE
+ePOSZ/ITxWRG/w/ZG3XXiAq1tMe6xm07RUF3TTWhOILhitbwzjWWu2lTS/squvbLeUfP/Bql7HHEOD58
+5hLmWWMue+XZxT9qq/rmuq+nvxxMpcHrzn/3dDPYpdpZn8j2gg/bjWq4XLR7kNezkgcF2mwVhwsXAOST
+htlZ8LKmkQjNpNIsYGVFI9EBCcylaWgklA5xdxEbrAlbTNgmrmGjLSWhvZKlX4rxbecKFSHzrMPYI3SQ
+lytoMAU+4jDAjovbMeQVALK/dY9Aaq+rFmJ3Lw9h+kLuxP03AM0SfuMGCwAA
 `,
 	},
 
 	"/access.html": {
 		name:    "access.html",
 		local:   "pkg/uploader/assets/resources/access.html",
-		size:    1197,
-		modtime: 1619793986,
+		size:    10995,
+		modtime: 1792365845,
 		compressed: `
-H4sIAAAAAAAC/6RTsa7bMAzc31cctCfeiyRbtw4d+lB0lC06FipThkQ5dV3/eyHHSRzjvaJFtUQhebzj
-UR5HGKotE5RYcaQwTV+phGWhUOuKEKlKwcowjiA2mKaXFab0ZsiQFwA4dKf5N58vDcWMFbF8jtCB4NkN
-COS0kIH4pTGfoauKYswhaWzEZU2/xzefUGkG5RA0D0iRAuuWik7HePHB3Fkr35aWtVjPGHzCRbPsr9qK
-Rdxa5CfSfRbgmeADSi8NqO1kwMU6B0e6J0hDz5KQeJZOZtX6eqt9aNGSNN4cVeejKOgqqzmq4jqlerAf
-jO1ROR3jUWXg7hx86lYFc5HTJTnUPhzVbW51el1uHw7FnN9gLHdJIENHRyX0QxSsWcGfSCvPErzDOMLW
-2H8MwQdMk407y7121uS9u0jX2D0yvwSF3G/dudcu0VGNI/Y3jbluNXRhbP8fHtxWrk6fl9tfeHAHzT48
-/v2jD9mGP7jw6Lty4aby2YXt6AvFriYypa6+b0rz2eh6K78kf6FJrWb7k96pWz7jJzGbtby/pfyedsGe
-G9nOUyYRz4vlMZWtlbvFpTBK4V0XbKvDoE6vndFCh+IKepP5UOTFnF4ein8HAAD//3PzUjatBAAA
+H4sIAAAAAAAC/+RaX4/bNhJ/76cY6PrQAra8u2lzQOA1sA1atLhcEmQ3zWNAiSOLXYpUyZEVd2+/+4Gk
+JNOW7XiTTVugecha4nD+cfib4VB3d8CxEAohIUESE7i/f4cZCEVoCpYjWMwbI2h9dweoONzffxXNyTRf
+uylfAQDM64X/6/7dlGjdXCKhlhaYQdBKrsGgZIQcSHeM1RJYnqO17hWVwkIbi0/hlZvGoGS2BF0AlQg1
+s7bVhlsQdpBoSRvkKVwVhAZIa6iYWkPBhEQOjAirmrwUqZcg1AQYVCwvnR0l8wMtEwQZFtogCIKcKSCz
+BrZkQqXBxFm9+Cr8Kp9ALpm1l0n5PdQ0fZIs3lo0dj4rnyxG/vhVYIvGep4W0ZthiVFjgSkOuVaFWDaG
+kdBqAq2gUje0MTSFVzUaRrpjwaTVkJdMLRF+b9AItBOoWWPRsRukmkZBU0vNuJ24AWB1LdfdRJvCFa+E
+EpYizmEMcIVmTaVQywkIlcuGu5VqnIEpPA/zgXR4A8RucRCKRYE5gagq5IIRynUn2yAo7Ywy1K/klt0p
+vOvs9lwnnmIrGkBYaJQPHOTRigAA3N15t0H6ozHa9DHpSbhY9WvFJBoC//+0ZUYJtUw2q9TxSeF/UDYV
+U+IP3GI042I1SOt2gx8glknsZfiHiOucSmQ8fjbbIudULl6yCuczKscjb7Q8MLL9dj6L+c5nI6lus26e
+7+7A+KVOfdjGdu5VMrzkC+cfpyzc389nxA8TOb2PEQ3uwg80NWJZUjKm87SFNhVUSKXml0mtLSXAchcx
+l8ksgMeBmX62UHVDQOsaL5NScI4qAcUqvExya4r3pG/dmxWTDV4md3fwdfr8+s1PN+413N9/GmcXwO5X
+zLf32jGOWUOkVcfSNlklqGcZLB4YGqz0CqdOUNJ7MiMFGamprfwf3ZAUCqfcLbNJFm/8lPksCDng65lz
+9p71Gq3idri5/SAtnhpFkGtpa6YukyfJVhxUDSFPFi97ZCnZCiFDVMA4R56eosdmX3bjm8Cfz/zm7B4e
+FFePF0cxHjkVpka3O0ExIlka3dTOa9OKT7/bE0JzyTKUUGgTRZ9HlWfzmR/bMyc2ybk/AcGj6Vsa5FqR
+0XIc4awhneuqlkh4meiiSMDg740wOFqpAT4fx84+PSaL192vE20dJnp7N09H7N0QbdursJ1u9BiLtRWT
+couxczTEwf4CXZC78mQNpOEWsd4qc0AXwBTgB2Gpz8LpfOY5f2EPG+0ymUPyI561KH3C5x39MTcGhnuh
+Z5OQnLxRQtoSqWu3TWNw9Vus+zGfhfGDcnYgYuO+YMpRp+4+Rv49mMkegOsWaT+o10ZUzKyTxRXnoA00
+NWeEPhjGkB4p2SP6war1JVKrzW1Xhu8vX29KBCksBRw2aC1wJDSVUGhBK2hLkZegOlZDwWb3lPQgLLAV
+E9IhcQpXfcmpi573INbVlbYvKNsSVaib0axE4GPQEjOEfALM1/2CfDEfMkZdS+GOBE77Xu2hes4Q9AqN
+8UgO2To6SDDj91ksy5eW7sU81xwX0+CM+cw/QSHZ8lA5ehUEn1yV9tn684vSv0t684izuyMikGkxm3bL
+kyxebEXZftQ5kLdiPscwaKCJ4OMdZtdoVmj69XI2QS1ZjqWWHM0uWdAzIt5R8QTcH+HPW9vHl9Q5k6W2
+9Oz83xcXT/s4Ix1O0UxK3XYbFgqjq7DNuuPsBLTp+Iym+5kjwTEnTSWanpVN4ZcipKZJEB0L8lstzE13
+IHMnOR0BzdNDxKnu9l2yuHI/kQ9aHggT52xmkG2iY2BxND42VEa31teoW5Fwfpaep2fp2ez8acg5V/2M
+cN7pxD5CRLxSCL+8HsBLmwFiazTgqvt4gRz/3iVAJQstDIMsD9i1g+DM4DgSgmcdZkbr7BBbtmxth/FP
+Xu7HyJH7NvooTb71+fHzUuPPNzevr/dnRAcDIUmM8ptLK+CnQm00YU52p22FjhY5aJ/QrBtahjzq6Lol
+9isb2lCD1AzDciLft1knjlxYn7SQx3pka+BYsEbSBBrr9GZgURZTK5YKOeRoSBQi9xWFkj46BqHxoLBd
+Qwk5ZCh1G3WD2C0+KFdTiev4gNel6wN59B1m//QcCv5nXmJ+mxzJiJ4g0x82WZHkbkZ0JFM/qdfQ03Sq
+nSfOD6KIE95bi/bmxfWxHOi5Ih9ceBzPDyjlqZKFFxGF8AjhT0opn+wxg1wYzOmjbusJ35dE9VEHvuko
+rbPn8dx4TNPOl71k70kgfcCjp2apVw50WmFx4g/6aMl2oFJLJlSQwgyCwd8wJ9+1+fMTBMnHSQ67IPQ8
+QsMYQsYtYPD/u25c5bPJtjU77dgj3bJyEckcd4Ljnut1kzmXw/19CL+vozC7RllcB7i/v4dvIvj/dgi1
+j7XXjmr5K5OCQ6NIyINa9tvixw+1axC5aI+WvcPtTeT7tqkmf6eT/qRNxQiSi7Ozp9Oz8+nZRfK5Kl//
+fDW9+P4pFMIJro1QdNjBoaT2sP3Tht6r4EdO0mS2s+5bHcku1o4t3Da3el/zdKT8D66MRWPB3XgAy3RD
+h7K/u93RVe12L5UY+8XNW0UHYa0QbKlbd3Z2L0ZSsyC1v1Bz2bL252pBk9DAcHUEsJ36wjbIO5ZQalsL
+YnK33KwPNXwfnr0BVR4QpWokCXc55Tf+lDNif8/279OPNO0c+kU+TWL4COtXG7FyD7e4hm9e//hf0AZe
+/+f59b/OL749sYVaCImbdBmL23e0mgby4K8Ws/dbE0JoXCZpjdUkzQ1N0hzNJL3F9SStzy8maV18eLSu
+8gPdF3V3OxcNhfwndZsPsj92JN1x2fsDHedHaFUG+FGa4CQE+rRbq94Hia/PlF65zMEI/eOwIf2BIPdX
+w7MP07Ztp941jZGoHODyUX7fvfF6a/EQ0O2/BtvfG35g5REsO1x6ONj7vHPp1etfwAPOgXbtZhyWYoVb
+X1ggVEyxJVaoyBM2imPfL5qxWsxW511CmwCzwNHmRmQelDfKMigNFpdJN2Gma1SsFulvVqtkIci6DxaU
+Yx/m+378fMYWKVw7D1OJFQgVtVSvGiq1EX+wQOnfgbvCRjMZ5DLbUf+AzLjGd9/YukWVwgtxi8O3A8F8
+f7xk4K4dQkckaluHnvVQwvrjaM4UVOwWD51CX2LbQ/pHT6G22Xc3/VzX6+6Y3wYtQenWH91bIaXfelmf
+Wv2nJ892cr4Zyswqm5654vIi2RQnaVyQ1AYXHz/lDtZ50/6CU/Zf8/nEc4OMkH/RbytuQhj+6R9XeKLO
+wD2FM5x//+zsO1c+Q7aGiPaH9T/1kw3P9P2X/mhjpW9x2ul/6lcbbs7f7KuN7/wnGnEa+nM/y9iqlYRy
+DoPfGkuiWPvSCRVNUfEvU7+Pb5/GEbSnloPKTC92bhJcdE3ANnkJzLosJf3ZzCfMrc5id8ceCzp4194J
+Ykaw0AG6TN6Mr91PuG7/pGv2fUu9e63+gC2Te1w6sGWGoiqgVwjG7a3Sb42NWv8fANqC4QbzKgAA
 `,
 	},
 
@@ -2437,153 +2504,429 @@ AIBqGmo0qNnH/b8AAAD///H03wfSEQAA
 `,
 	},
 
+	"/audit.html": {
+		name:    "audit.html",
+		local:   "pkg/uploader/assets/resources/audit.html",
+		size:    2629,
+		modtime: 1792362582,
+		compressed: `
+H4sIAAAAAAAC/7RWTW/jNhC9+1c8CDlGUjbotkAga7EIsih68AbY7V4LWhxZbCVSJSk7rur/XpD6sBR/
+IAt0L45Izpt58zjDSduCUy4kIbDClhTgcPjYcGFRqk3bgiTH4bCYmK0V3zurBQAkdboAAAB42pLeIyuY
+3BBUDlsQMiVzsWk0s0JJCANNmdKc+C12whbepjGkYQtmUTFOEBZMcn/CONdkjNvaMdMd51pV0Rjz0Ucz
+YJpG19gVJB1+77cd6hbMgIFrlttb735uw+q6FMQjPDNjdkpz442ULUjDUKbJmjGmA0hlx3gdmySu08UC
+ANq2yy160lrpQSkASLjYIiuZMcuAlaQt/G/IXRI6OEoJAF+dAMNNIFNNyX3YtcuU8Qe0LSL8i6KpmBT/
+0CxQzMU2Hdj0d7h4TcHSiw212BQW1Tq8n8RPGApN+TKIPYMPudIVs8vMbIMBvLYSaytDU/k/qrGlkBTW
+WlRM74P06aVWLkGDxy/fkpiliwmx7tuydUkjGb/wv6GpplxsQYxP1/q46A3Sr6KiJLbF6cnvhvT5ky+q
+0dkFVFdXF3BkrZCb84efS37+YEW7+UESTxNJ4pM0XaMd120L7TjhRtzihvCwRPQkrRZkpjffK+TMRQ76
+GzcUfcx89wV9lbvmnYoemibLyJhgLJU5+84ln1WNVDvN6iBtWxfAqR998jWC4Dcm8cc97u/ufsa79w93
+Pz3cvXchk9jys457J+6i3mDW3dpVw5PNXr9rgpzFABiehg5+Q9E30saBDwcwg22/atv52bHrLnCh0tC1
+sP6pugg+7/qyIkmmOPUCfhJUcq+f37wCqvWA+dwjav1GwIp2VwDz0r+gxkmfv65DX7s7pqWQmwCZKk3N
+5DL4JUhXqh9DBgXbEtZE8jge9mTfQmmucRJP+jGJffDX7/0z2wjZTboJsqucjUX0qBpp8e4kUcm2ZzJt
+yiHT+uj3z8ZYke/DTElL0oYZSXsyOUYXpZi4oFBYqno6bpBEvzLzrGkrVOOeEC6MS4qPuV/wCgAJmzku
+hfwr6CfGB7ezdLNp4jtIh8U4CU6LtxTfkcbA9hpJVw+nPNNntiE/PFdNtfZvDlTuN7oLcnXroP8Lz7nc
+K3qxP0Lq3m+Quo/vlDiJm/J1L8wqct4Ms/8J+6//BgB0dBqKRQoAAA==
+`,
+	},
+
+	"/certificates.html": {
+		name:    "certificates.html",
+		local:   "pkg/uploader/assets/resources/certificates.html",
+		size:    7402,
+		modtime: 1792361899,
+		compressed: `
+H4sIAAAAAAAC/+xZX2/bNhB/76c4cHlYAUvOnzUYCttAEbRrsa4N6myvBS2eLa4UyZGUHc/zdx9ISTbl
+f3HSplix9sGN6NPxePe7u9/RiwUwHHOJQBx3Agksl1doHB/zjDq0iwWgZLBcPokkR4rNveATAICeHoT/
+/b+bHC2CRee4nFhg6NAU/pVczcDlCEwpc85UBhbNFA1wC1M0fMyRpfBmDHNVGpDoZsp8Ai6txsxZUKWb
+KC4ncPN2uNorU1Ji5riStgOlFoqysEW2th7UOCzVirwGbdTtPIVrLqV/pKDLkeAZfMI5GPwzbEeFiLWv
+dpzlaDAorK1nCi1I5UAbtCgduJw6ryqtPNPVgyfhr8UC+BjSl8Yo0/gtSDA+hUxQa/uECjQOwmfCqJyg
+IYPFonnpH8jLgkr+N8Jy2esyPh00muv4VFvmF42+/Blol1yQwY0prUMWO8b2uvnFIDbt6kUc9ZaNjo4E
+Nlqrh/CZ2IKsQ1+J5kjZ5pppL9SCg2E58u7udV2+W+CNtSWa/d+/vNXcoN0W6HU39+x1d1rmcdxeWyzA
+eN/DCe/ASQbP+4dcc/CM1RfMx/AkS+vThuA5dpd0dfS7hOvYnWRp5QoPgyZMeLtC0QoitfJ3yr0YOzTp
+K2UK6oCcn55eJqdnyek52bvltk/XyNuQa3u11w1giRbGyhRQoMsV6xOtrCNAQ6b1STfGKIH4MIZPcreJ
+Ny516cDNNfZJzhlDSUDSAvsks2b80alPfmVKRYl974eT9Gr44dWNX4blclPbqHROyVqdLUcFd426ysCV
+KoOFmuLKwJGTMHIyUaUTXOIqfT8EsV63Uhz7xPtgncDCYivj9OC9FPOtakZLlyvDHUcLrk7qUSVm59Zh
+AdQglBZZGkrPrvpwvPNRZpUnilI4rqlxweqEUUcjz325GMTFMOw0MarUm0ESdIQCxsr0SUbJIEpNGJWS
+CYQfr1/+9vR5rxskDwBmzAUS4Cwoam2cKemMEkklUR+Gek9lqF2fpBqLTpoZ10kzNAQM/lX6/NvYzBah
+k0SKPZLBfyRF6ZCtazOXQBnjPhDg1MHIq3EU8V43bBJDa9UaNn26P42OB37VZ7eArw0vqJmTwe/h+12I
+X5nVgH9vv7ped2TNZdSqjgfvfwKe3ngy8EwDmT+N3YNJHxdqkAYohpd2gbGxuhIwamb75IKAFjTDXAmG
+pk9sTs+fXXbTNK2owzWXtqro9Rb3B+h7iTCiFi9/ApSZYshg+PpFcv7sEnJqc9BowNe8FN4inSJgod0c
+nALGbaAKumJa6ddGasuRO3DKqMPPw+mV4ChdnKcRVCNS3NBai1lpuJuDVoJn86Zq2FhD4n3NQr6jdH6J
+K9kJCV+lHpqGbFpwOW+97Amq3OLHctJUlA3yHZHUmAiGU+1jPIsFzLjLIf2DCs6CddtvbHGCPSx3Ro2H
+RoXVvQx3N9f4zkoPxuk7M70nM22/086F4Yd9M9txNMWaNk+xfOJxHwoAWndMW/A6dnWFulYrqaymGTaN
+4WcCBilTUsxDcsUH+Zxu8NqX+FB2atvDWZB1gEoWD+Hcw6Q1csIIhZqlW+G7yRG04VMv4lu+RD9ZC99M
+6gpX0Cz3HeZQB3kwzf0qTMGo2SY+doIIMiWSgiWXZAfMY0SpolAy8caRwVV4CJbuRtLW4XxEa+IbKTpE
+Oiq5j5Xc2gNptfc7WviavZcHt2v55x9fmYnvE6H7kMH76OneDmipOuSBtmCLdSntF6kgB4/9yHQnLhCb
+4+iK9fyCEg112GTvPfjPtzQ3fvF0W5+vXcj9pAnKwPWvV8Mfzs6fHgm/aPCMNN89gcbCu0fRTqrPzjup
+Ht9+tWSMyaum1s6UYWRQewSalSM9s1Kw6Z216oNlav3Cx7UtXzorjyLKD75USrKgl3jvFlJNK56N4XGV
+WlRrUQ8H3dtkNpslwR2lEfWQ9uCrqQP86R7FqD7CY94SDKsr+IOX2m+qe3/8xieHRuCay0cfKyq3XuWU
+y//pPNHY1/OJNKivVCp918EpvW745rGnke1L4WHzK1oM+uY6AFkY/+8e+ztALVhECWOjik2KrXeNQ+3u
+P8GH3dgfKiD1L3SrCnK2t4BZzJRkoYzU6V2fq11OmvqxPsi/AwD78VGk6hwAAA==
+`,
+	},
+
+	"/changes.html": {
+		name:    "changes.html",
+		local:   "pkg/uploader/assets/resources/changes.html",
+		size:    4102,
+		modtime: 1792363254,
+		compressed: `
+H4sIAAAAAAAC/7RXzW7jNhC++ykGRNBTZaW7SQ+uLGCRNoce0mI36HVBiWOLDUWqJOXUVf3uBSnJ0b+9
+29YHwxaH8z/fN6oqYLjjEoFYbgUSOJ0eMir3aKoKUDI4nVYdoUSxo5NZAQBUFfAdrBv59ikAQMT4AVJB
+jdmSlGoG+TG4I/H5fEomyJAy1GDxTxu8ZtwiJPuAy50aXAQA+BUl43IPaW27rzhk/HDBlo9jrDYqxs8A
+AJ4zhJ0SQr12jEJGDwhSWUgQJdCiEBwZHNGu/QWD+sBThBfEwkBp3FWbIdDU8gNCquSO70tNLVdy0mop
+LRf+SmuRamztrMfOhxPeR5YmAtvY6z/+OzA5mQ42sq4Sc2d6+qC5GH9Ca7ncR6HNlgU/+CxclmsqPS8Y
+hXM+ReFiJK4Hps+qCrRLONzwb+GmTj5stpOt/kUZqgVYHKWKYVxVrfL1I0fhZi0K/UkUWnaFlkL3lPzS
+qCj012p4wtcrNcxn/Q03Zu5N5z0KfVvGq9WEwlduM1g/dCdm/aEoxPEnrZWerUd37KlAbcF/B8wFq8l8
+dG58iz7EQKpKwZpxb4dwM5+fUiynv99jqLVvsKXOaj+R4L5m7s7fkJU5lfwv9HUT/KLRhdrUZZhzfAJW
++yrHJd0pnUOONlNsSwplLPHgp+SWhE1a5zCIy6K0YI8FbknGGUNJQNIctyQ1evfZqhf35EBFiVvisrF+
++PTx8dk9htOJxBf7wfkW7LUqC7LQ5YImKGCn9JakKs9RWhI/1D82UehPF253g3C0RoCzN0U9T1IlrVbi
+HGQrUwiaYqYEQ70lqnDJo4J8UYWGkXuC1Xyf2aXIk9JaJRvnTZnk3LbO1TU8Z59x4zj1HE9iJSRWBqq0
+gksMDKZKMqqPJP6xFo3CWvt/Yt6N4nFkvNA89yY9TCwbnMlbFLrKxEu7ReevmwNhsLcFDfj4OUPH3xpB
+qiG8rFcjGh9MVpS9b4PM7qGwwV0dnNs5eruEicLsfbyaYf9OzYdEP6Iux7+/oTZcyTH/ehKv7U8fNnMy
+c1iHPX3Yf9qnmhGpD4m8D6wHD6tNECOiiKxutlj8A2443MLp1E1WYMo0RWPIuRZTGxbzcHxYP5V5gtoD
+sWVLgk3a1o9K59QC+ZlK+PwO3t3efg/f3W9u7za39+QKPU2GFyUvLDi/9xYcp/OKFcc1/aUVZgOjzQS+
+0VTrH2Bi4ZgFrnnGmg35apyrKy+xrfx8wF9PZP8voV2h/VC3fk91p1UvKb4eh7USIqHpywiKTd6jgzMy
+f1RCgLtxBR1MQHG3QwbAO/zUb1z/ur/6QDRjd3L/73alx5VXqiWXewKpEqagckvuSfykBkBev2EO3y6v
+cawfUm/pPi/ab3L/DAApLzajBhAAAA==
+`,
+	},
+
 	"/database.html": {
 		name:    "database.html",
 		local:   "pkg/uploader/assets/resources/database.html",
-		size:    3759,
-		modtime: 1622811287,
+		size:    12358,
+		modtime: 1792362158,
+		compressed: `
+H4sIAAAAAAAC/9Qa227bOvI9XzEQ+tACsZ2m7SmQ4wjIJi1abNqTNkn3JYBBi2OLqES6JOXUx2tgv2Y/
+bL9kQYqUZFuWLcc9i81DLFFzoeY+Q83nQHHEOEKgmU4wgMXiimgyJArnc0BOYbE4qkANBZ0ZoCMAgP5I
+yBRS1LGg58FEKB0AiTQT/DzoUUcmCC2shWd8kmnQswmeBzGjFHkAnKR4HkRKjgZafDcrU5JkeB7M5/Cs
+e3n79f2dWYbFokppxDChCjVECVHqPBgKSVF2hkJrkUI67LyqQFuMBMfmdRx8/FsQfmOKadXv5U9WwCmb
+eljzlp2xFNlkhWZOlwwxgZGQ58HUEBRyoEQmIwzCW/t71u9ZmBpchQlGGhhdw13iHQmupUhgPgc2gm5O
+9p2UQsJiwVSH8SlJGC0U5oW6up+1DZi/vpgYjTni+MPTh6DQICwW+U6x5OG1VKr5W84NfmQoZ/1eTrYt
+zzh528jOPA8/XL+F6SlcXN1BikqRMSp4/un6+ubFvmxHMZONfC1A+P7Dx6+gUE5RwvN3PBIZ1yhBIZFR
+vDfzSE0beZvn4eXtNxixBBUwDgSoFBMYiYSi3My238tp1jxRKUmSJSPT+FOD+ddJM400CP8wHmW1yVAB
+SR7JTEGmEHSM4NUOQ0zEY7ffswRrGFX8yJlpZ4RIhyT6vsEga618Pl9e+SfEWUo4+xPzhy5SrUuAsml4
+tG1pH2ePk7cDQqlEpXKLTJjSyMGtNXh9NQoagQc2AFTp1Xl/AJOERBhbnZ8HZ6dv3r7xjr6EW4bP7ofr
+txf58nL4bGMGngDhFCZCahAcHmMWxcv+RySCxAjZFOkxqCyKgSjoR4JiaPfa79nrLnx1UMu4KhaPHDJO
+UUKfQCxxdB70Cnf3sP0eCeut7VB6NZ4+yGSy5O2ttVlQ2UGVsdYTddbrYSy7+JOkkwS7kUh7hkZPvvZK
+LklWNGz2mPtF9/7r9f5a/pvxZUNBjKx/23f/+tq9fheKYOe0ZSMeUsApyhmkjGcaYTgzuExCQpSGbEKJ
+xr9AWSTTsdOWuUSuWURMRNwt85Y06nVVkX7OacfwXlHNRaZjCBqDfBB+FhzbppA1HkOiWNTIKIcI7xVK
+82a5XxOlHoWkT2ePRGJzJnUg4UUUoVJga762KWwX85HiMWjOR6WVQSSSTXpdCw1OcM7i/O1mW9saJTzB
+reZXQm6IAO75hjCwLrTDSMRbj5OIv20hkYJCKZVyaZtUSsh6qdy4592bhDB+ZyLfrvI5aJjKuxsXpyrG
+v2NuqZFRTnGrgNbaqqp0bHPVJJpDycBUrIO8Yg3Cq7J8bZ1aq4Sa2iTD470Fa2yVllLx1dkD/jRVzgMV
+Qp5SEXlJLnGtiLLk0s1/9s/BDp/5+sqU/Plu8qRrd0q78J4laHelQGkiNTwyHRdVuUK9Wn8N8U+B35F3
+Tk9OT05enbzsRmrq6rFjByMJZSIRY4ad//zr3ysPEzKsrIKQbj0SXGWJXsIw+SQSSZbyfNdmoxQS9t00
+DkKhrzBcY9GF+0kiCEXq2huDk4opUtDCsZlIYdwFacEk4xIJJcMEHVYBOyIsKQC7YJsYX50SCiMpUtAx
+U876jG0C4TOwvTLknfKvaGfWrXE+X1/9tW3NL0iJkZoOKCYsZdr4tTHZ4navlLhMcIfq+fdyhLSEWe+j
+Vx6g3k13bopAMT5OEKKYSBJplMelV2gy9Pa32ZB+QTI2AtAsxUFCZiLTuTbMgnmcEr23PqpEd9DI6cnJ
+b52Tl52TU3j55uzk9dnJm6qOlqjVa+mOpXhtIZ6mpn9Ipm1PrmzIkThCiTzCXCo1+2ylsJWlfs8PJcOj
+o1apcjlNsqnNkH7AYhRSr7lqE+PQmmoBT/loe11/KfiIjbtXFgMC9SPJ+8DGwr6ECm+/XMOtvW6o7Ldx
+NfPksUQVLBbgmULB1TEtgMIbd/UEjupHwjS+2vaWFsa8I9MIzymmYsPob715cSazv3nEZsgefhBqgzdv
+Gi4ZtKZiqb5ESpTLQ2xUCMvwtnDLhdSmiqoYUdkdVNy9Qm5ltv9UGZmKydiDbCcji7avjNbksSIGiiOS
+JdpLI+e1Lg2z6QNLg3GlCTfj/4/uqpVUCvS/SDIlv3Xp+Bc4sIRMySimKAfGRlUQvnf3YO83SMtIiEgk
+eTe2TKE+CkvxqM6D05XX54Jj0a2tbMS2am7N+ImZ4vZ7nvNqMthhos/RvhNMUELCOB6DlgwpMA72/Awe
+Y+Q2VVowMhRTBKaACw0SSRSbirsL9wpdqWPAHrzKjo1V+x5hJCSUacB2BSXK2SqkD96/W+bmqcXwlN1O
+iETIFNJ8myI1yZ2up+wnG0R5puWzcCuXKdAPE3D9HvYIuuVO1p3Jk63p+HfqVa16mcbjXGVEx76z81xt
+a7bai16ePdxIMZYktX2serhyjfZD3gcae2ERPpi02qW+kD68jssx3n3jBG+DjhuHdu117Pewh45rh4Ir
+ZPfT8TWSKQKmEz0DLYznAeMax5JopKAwyiTTs8Orppwn3jSOEhvGY43Tw/bqqR0httZU7aCyicP/UGcb
+vq/QYgIT3Tnd/nHFOx7JWV5vPy/zwIsDfm2BOYeC1W6nPR6rqVMqKG8a6EjCxwjP2DE8SwVFODuHrtvF
+J0FR1c1sNnYhz7wNOAqOZkMPMp8XMLZIYKPivvKoNGZXYhV0mo95msZO+x7GWH3aMUQnirF2VrbkzRZo
+KH7m3qxlpvQgby4HEUrNRuaEbzX4GpyOpeIVuRnRSVLLDANYdvY7g5Sb62WJAouFZVBRR7N5ttm0RQtC
+y9l/X1JBaTDs7RHh9jubKJiizIkZ03N5ep1RFz7bSi8SaYqc1hVXhzwSMKXgwGhqwPiSiGyDa3UIjFc3
+2P6rik0sdphjKcOfKMhb17KNradX39p+Jil+5Mt2FOwxRjpoPHbV9u2X60PGY6USE3mC8Pb2GszVbgHZ
+ozUF5IJ0m4B8e3v9hGDssP/Pg3GLmSRsM6lS0xEZmNI+CC8vqs5p6/1WVbQntIMzXp49mB7jQYuHiHQn
+mBYjZU9j3QEvL+xR2V6F1M27T/Z98oZTizyC2g9clt55tedRbux5+KJYklQF4bufWhKwd6hRqnYjrpzI
+oYpiQ2yvOjjfRV0VbJ7UKKwircZDt0vBOdovkEGLUilMwR9/77bJYVsO91aENZ/76x0O8NZc9ImWYc4y
+7HHPF/PhLbhbeM44KIwEp+rFDkbCs3SI0tVbjmKTndzlMDuYi4Pcw1CKfVQsxVF7go2YP0/l4IZRMY4V
+Cc3nK0uHNRUbuyQbx6ttS3+YaS24U7PKhikrNTvUHIaadyaSpUTOgvDefrTX7+VIa6zzS2MP4dFRsef/
+DgC02Y6TRjAAAA==
+`,
+	},
+
+	"/hl7.html": {
+		name:    "hl7.html",
+		local:   "pkg/uploader/assets/resources/hl7.html",
+		size:    1786,
+		modtime: 1792360443,
+		compressed: `
+H4sIAAAAAAAC/4xUTW/bPAy+91cQRo+JnfRt3w2dY8BYN6xDt0Nb7LiBkRhbqy15kpIiMPzfB8nx15Ju
+9UEwRYoP+fCjroHTRkiCwApbUABN8+nuDZRkDGZk6hpIcmias5HlWvG9MzwDAKhrEBsI74SxJIXMunv3
+xVXS/7vvnhiJnTNKbx57DFASYqY4JXUNYcq5JmOgaeLIX4bwsK0qpS1xoB1JawA1QbpYziBdXLjjP3dc
+uuMtoOQTzHS5DOFLB+VeInuS6rkgnhEH3FjSYHPaQ447gjWRhG1VKOTE3w0x2hwtMLUtOEhlYU29kfM5
+QdT0k5glPgOzZXn70gM4cEPSAmYoZDjQFB14cmwXhiYUcrEDVqAxqwAL0hb8OX9G7dgOpgSPS+fh2lgd
+49pzTzyEByqIWW+LLjGCnTDCKg1GbTUjVw+bT3OKEXJNm1UQcbS4RkPBJKp5IeRTkHRKqDCjOMJk5uoB
+WFXF3iMxJTci22q0Qk0Y4GI3cNB2nBdji+uCOqxW8OfclKPkY5sT8rGsp8zENk8eRUlxZPNjzQNJTvq0
+7r2SVqsCbm9O6z+4njyt+uZ4Pa1K+yYsj57H0Tj6ODrKzQ3gINc1aJQZwbmYwXlpMrheQdi3/KiZDsx0
+bLajS7/8ozBlTxCkqRvsA8FbxsiYoSnba+6wdF+mPzqwheBulL1Xx3n4UekSLQSfUcKPC1heXS8urxdX
+gR9yy//u4Z5KZelVpodS3d68yvpxXzm33zvZF/JfL8WmNfalhaap6yO55eVlL0eXwxrtCzE47qVDEaSS
+NMzIC66ehc0PSWmttAun0v0Ulev5IvDL1sdZaUpe9nicx7Q9T+yskwN4yH4yyf0WA6YKU6FcBf8HyVc1
+7LBua70mimn8cTQakzjyeMnZYPd7AI4bh+z6BgAA
+`,
+	},
+
+	"/login.html": {
+		name:    "login.html",
+		local:   "pkg/uploader/assets/resources/login.html",
+		size:    2091,
+		modtime: 1792361899,
+		compressed: `
+H4sIAAAAAAAC/6xWwY7jNgy95yu4Oq/i7uyiKArLh27bU1EUO91eC9mibXVkyaXoZNPp/Hshx46dzEyA
+oJtDTFF81OMzxSR/Y0LFhx6h5c4Vmzw9wGnfKIFeJAdqU2wAAPI3UsIn/HuwhAY6ZA2smwhSTvujq2o1
+RWQlBq7ld2K95XWHSuws7vtALKAKntGzEntruFUGd7ZCOS7egvWWrXYyVtqhevcWYkvWP0gOsrasfBDF
+ZqH1QwgcmXQPH+/vF0bO+gcgdEpEPjiMLSILaAlrJTIdI3LMyhm67azfVjGK7AZ0NUQO3Qw74tiyw+LH
+EOjOhAo+9y5ogwQSfgkNWJ9nx4hNnh3VzctgDsUmN3YHldMxKlFb52bxvD65vd6VmuD4kM42LUPZHI0p
+fIToc4AsSXtz4r6KHKNt18BISYmZtQDtWAkBkaqlWheasO19I6DFdKQS779ZH5vp1WJVzcSC8QtfnP0H
+UrTBw+MjbGf76WmV0djdJEPm9W6WeJU7dZG2Hgn6g/wgXiZAYQ9/DZFtfZBT28kKPSNdatFp65fUTnZG
+fgvJcI38cBE8Atq7Obx9Dz3Ld9CX8k4U87tu756DHh9hb7mF7U9EgdYFrz/rArRDYhi/pdG+eUb8Iv0W
+/oV26LS3/+Cr+RdxL9DozUugvA7UpZvfBqNEHyIL0BXb4JVIvWH9K6Ry6/uBIQ0aJVprDHoxjYMqUv0n
+h4fk2Wk3oBKJ/sf7Tz//nrzw9HR7Up8abZ3uV/zCVzKtL16gTjYUhv6KwLnTJTqoAykxRKR0qig+T9b3
+eTbuX8GvqY+3AqxZpTojk/qVgptrW4JW9c1HpxpBDxyq0PUO+Sw++etQDRFomuLFLX3xv5XqdYz7QEYU
+v03WjUqdEoxqLasrai1B56pUA1EaAsv+V9EkvUtJF9P4GaAcmIOfiopD2Vk+FVGyh5K97Ml2mg7LHDmC
+bmKXZ0mSc3+epQlXvDxhR/P0mH6VsuNfg/8GAGJfur0rCAAA
+`,
+	},
+
+	"/openapi.json": {
+		name:    "openapi.json",
+		local:   "pkg/uploader/assets/resources/openapi.json",
+		size:    29708,
+		modtime: 1792365710,
 		compressed: `
-H4sIAAAAAAAC/9SWz27bMAzG73kKQqftkOQ+JL60BTaswFp0fQDZYhJhsuRKdNbM87sPkmXH+dOicb0B
-yyGQYZIf9fto2VUFAldSIzCSpJBBXV9z4il3WFWAWkBdT3pRqRE7HzQBAFisjM0hR9oYsWSFccSAZySN
-XrK5iGVYEmJDvJBbyBR3bsl86nRtTVn0AkKQ4ikqWBm7ZMLKLVqWtC0B7Qr8tJiHkKM0hwozAim6tAOp
-zGiyRjHQPMd95YMaoY4p/AagqkCuAJ9gdmX0Sq5n1yEDmHtSDm2oX9eNKIqOFWy5KnHZi0oe7m/hIawX
-86b4AFUPd23RsbqGVhQ61SjaBSV3cXVecTFvSvScmQu5TSbDndp485PPxtEL9khdlBT8WzLCZ2LBqZB2
-zqdIYnZjrbFQ19JNpd5yJQNq5RDqOoZEUl47xHVRAU23YFAonuHGKIF2ydpBaDqIAKvqoJzPGpFRYSx5
-a+xljELaUEYnPI4wCFzxUlFLo9E6peGbHpmG1I64zpAlX+LqIipd+j8is9c7pdNuYGRC+xO0Pf8uItSl
-j/N8tT0MeMb2nZyy6472cdmVDq0XZ8ljXF3Erksfh13bwwB2+05O2bVlD9k1b8OcK3XQvN8c+L9pXhIK
-ltwi3yJgXtAOyEDpEKQmXFvu3y0Os9JK2i3modSYxyB37qexgiV3cfUGa7qk5kjsrkaxp+1jgD37Ts4c
-mvHe/2aP5bljyc0zWQ7hCgmtu+yd1RQZyx5fbJA5TRfnrPF3zhjToxW0pitEkfLsx1HgldEaw6euN6c9
-4EA6+PZ1dvSxFQx5SSXu/SWdI1h+C836N2zKnGv5C/sgXlN+92SQzNGUxJL7Eu0O4iV8kNqPo9HCfXzD
-kOgyT/3Hsx+TtuJrc/K9iXnDuMTIAYPS9dGblFjtHTPif22V0QejNxxHhHrN/41RCWeUlesNHXNJSyL/
-PASbXZnmcu9sShpS0tPCypzbHUseC8EJF/Mm6US6Wfp5SCaTruc/AQAA//+1xYK4rw4AAA==
+H4sIAAAAAAAC/+xdS3PbxrLe81d0IXd1CyZlJXdxvdOxnVTqJGUdyXEWsUoZAg1yImAGmRmQpl3676fm
+AeINgg+9GGbjEMD0vLq/fkxP69sIwOMpMpJS7w1434/Pxt97vn5KWcS9N6C/APAUVTHqL95xLs5DHsBv
+acxJCNcoFjRASAgjM0yQKbi4/NmQAPAWKCTlTDd8nT8LUQaCpso9/9U0lKDmCAFnEZ1lguiXwCPzsKPH
+MbydE6ZbEqG7DxEUBwKhIJGqUvJhOafBHBS5QwkYRRgo4CxAIGkaUwzHcIV/ZyiVJUYyNUemaEAUhrCk
+aq7nBIrfIZM5Mf1hINB8wpkZ6RKnQJlCEZEAQWKQCapWkJIZjuEXelf/IpMopO/owpwsEAgIHuMYPlFc
+opAQEAYCSWjoS0VUJn2YU6m4WPlAspAqiPnMhxRZSNkMgnxRWNiypGYuCZF3GOrxCVRyDB9SFERx1xuJ
+JQeFUhkSlpyh9HeGgqL0YUFiGhKFvlm+lQ8hlQERoWkgeBzDlAR3+Uh8+zhj5t+UZBIhMzspx3ARJpRR
+qer9F1O2gwTKmrPxQWAa64WkyvZimRACFIpGZvvk2BsB3BuOlig0P3pv4I8RADjWBvAyEXtvwJuQlE4W
+r70RAMD9CODGNbP72GxnNk4/vqk1SYmay0J4JnbY6wcA3gxV6SeAJ7MkIUJ34v2Eyky2hZM9v2hRk6Mr
+VJlgsqulD1yYdyRQdFFbSaBG1DTrCwTG69w0hp+V5lZLPeIiIQp4BKETzfFfkrMx/MgFkMqO+usN1IT5
+NMqkESofZKYFck5Uc18NG0xzLsEQyIxQR57rYRoZKdE2ZIz4TBEZSFQOEwyfEwn/6/4bl9dPoEw5kygr
+GwHgnZ+d1R41V/tjfdCeX/0+4EwhUw1CAJ4BncC0muiFa/kGwJPBHBPS+g7A+x+BkR7Hd5OAJylnyJSc
+2CZy8rYysEbz+1Hf7/Kv+/KkvB/OXjfXpXUg66Wd/MY0mHJBv2Lo9VD+fmvKP3IxpWGIlQnej+r/t+7I
+S7NukbtyQLKL2JmWnXJncClHTAlUjaHBOz2KqcqwRkf9i4erOsvqV1Rg6L0BJTL0RwM4cQgf9nHhHjzY
+w3GjFg7ZUlQ3MI4d2RXKLFZ9LLk95fdCcOEdmfyMSt3lmmzi9MJQjfYLlaquUw4Gxe9oFKFApoVwimqJ
+yMqKLjeE+sX6MTBbrVJjQhMhyKrWNwAAgEcVJrKj/UaJM+vqtbS8f54qYCCvOR9iO2Zz+Fnd78Px3EUr
+eR8SLhUIDJApiKiQ6rhY7JNz514Ojz0tTBr/qMy3KZfdjKuZarWDCfJpbV102v6UBXFm0JfEceHJWZ/p
+ztglvc4BVUClNWOetUmS8z+f/oWBahEALxU81e4hdsmAF/AkaRtcoxOpBGWzgwvD87GAnr8cVsmenx9u
+KQZKuAt9DJbxd/b7IVJ+2vJ9oFfHoXQYavDOXKEOqiEQSAUuKM9k39a8NNgrDeuPxttSoLbx7mYnDC3i
+vhswVAdDZyhOIPpsQPTsh4P7vY+JyzZGvm2o1bbKTxykPWE4aLDQjesZRQmv7Yhepm/ojkC28gmdX+YO
+Hw62u1cVskfvA75f6LEfT5QB9XwGA8a1EkgSJ82Qpcbv6vHNrs2Z0yupmcH2NIYL+NOx75/2GWiOINQd
+3ogaPxEJwpztYAjTFUyK0z8WApVgaC/nyIAw10qPTyjpr08D0zmRCFxApA9n5mhHITLWOgLXNVAGqeAz
+gdIOgzAwTAU8gquMgTU3ZHUkuECxAokBZ6E+Ko0LeiJjpl8XDqe8vfviNQijAFoWoXQGWV8FNceVm/jh
+znsuQNqd55Hbxy2kWuEXZfnslaWyj1h3eZ0vQtjMgfVWoJ1zsFPNu5yGlk7JeRyidLA8huvS2aTAkASq
+ceKyD8vEsZZlLvQRZjPofQSq4EIv7HumxOqI9EGOLIMdxzz8tkPk7u0cg7t16gvDQL+QecKENMG6nBaG
+edzusKfYDmLXAmaG9Jzs1E9rdeCdYswlRg2JIhKVnHxjJMH7ichYhWeJIAmqcsILAFR5pHVcRcPJO9uF
+1xjKjT9INFzGFgE3VmB82QvaeRLW2pBxXOna+yaBiWcKloQqHc6OXFYLwy/KZlYtSDxUQM4HCYgbyZxI
+m17igj/9THDy+n84+/8DkNxWCEx+2VOIQU9yyWU55w14VMjDcccUBRJ5uJCio3a8pzINLjulpDwUUPVk
+iYUYY8X4a5wSZMkQYX5cxjjt4gDdoM3n1TPTDW+r+dWrTo5qeA6E5VnztiFlXQ5IYTXptG5tNeVZiLZl
+M/uw4WkDlUWu/AUDTFK1cs1DKsk0zrMP7MjHx63ZLCcdSLHV2fLY9Nop3/IFwOLEHHrj8rl5kB/NRRSL
+NH2eY8bKWGhurnC2QKHymLoNchDzcwVLnsVh+Y5BgZEmUqdBsgGCY7hQ9nTn/P/OQPBlHjkk4Qnv/kF4
+1x9EExljOfvU2RaeOpb2Hz2gSyfqB4ymnbD7KbHbZG3WQ9bPBrxZCPkAByD5RxMArEC57zJQTUhcZkGA
+UkZZrG8eWptUmrd0NldAlmTlQ6zvWhY0UlI6QapcwRzDB3OhrH57UipStagHWb3rw0jGra2NJ8VwMoRP
+hvCzTUJr0eRWuiNCY20UctE0AyEwxqOW8WlxUQ8+zmmeoyABqYEVVTtfc+73GpnWHXTd3i41zrMibPtk
+/MhmBWf4IeqAAWhtsq810tjDfbvrkr8+AAG4OQyodCQrl26qTwJSUeA9UaOPItOaNQyp3lMSV268r8/E
+8tvZYC++P5Ym+vIqxeRVRGPcVR+1I/wJu58rdu8eTv/A9I0nw80m8XYlFSYVbj7diNj/RkQFZKTY4kqE
+QQkgEMTUpOkVlHpM+J+Q6YIiWo0BwyWkwhr/d+iSFkUpOatEEiSdGdfZoZMBMqr+ERfOOLvVHt1e1q/f
+qrfFjDD6lSj6sg9P2+pvdLPOE+XZdXLBED4A8KrCuWmX/K7vamt1+f5XQBZwk4fYs2aDTaL7U+ToMRHb
+YO9Q0/DaXGIzyLoNZptmFpG72IVKmWG4ti7L1xwcBxl3hsDlv99ef/f6HLQJaMLqQJWElEi55CLMwyrW
+0bKsXIH4jshV3er3HF56OeG6zFMzsdYQbKc09wJhO6TdHJdZ7feO4C6Qr8/37L9FR9qKUvqbKWVErDaM
+8WT6H4Hpf4UJXwxBqpPVv5UOWZe7K2ite1zXsrvW4lpZ0XUxu2+jhuDOlUrLO2KE3byZIhHF7d2qFqvg
++Lqb/Eyh0lEO5eZff1SDcF1Nr+4DtEJpOwr14Y+HLEtqagb0tL5y1MtRQ0NBQspjPqNYfxOTaYvNWeey
+mw2Kv43VvQrLVpatbudtHUQsr2q7EtpsHvco0x2jcfcDAaaVlVr8hJZsqsee7LtmCt/weVo1sWmWa6fZ
+hK6fcrINtTZ8qhUQ3TTjdU1WoBISKqVmay6AMsPmL3QJCrQfPv+Qoz10NEUwjczzGCEHyrXN3vSOX8DK
+VBDSkWniY37ZqlWBNfzyjeU8/dxN6Sx2+hu7Y3zJIKIYh3kmkO4Gw+rNQncWVBQ/rV9m/en9R3BlS4wH
+NaMLzdMM0pjoUeirLUTCt8+eeXCrH3z23sBnzxZA/ezdj+H3ZmVhV8xVQUqEar1AacNx+jM6Y1zUjquL
+84XLcuxC69wG11aOcgbuQWdExLPy22C5nNSU8xhJz/khNhBzsyNSZ4nf56uSn+pQtbPHBKUkM9zYZycB
+082tvTYtu8mwLJlij8+gs9K6W+fVVvyNsTXbD/DI5rnpHLfuTpXImOHszVu2ac3RyIruToO5VDxNtcBE
+CsU6665n9ua6bc8CtF2EbVemhpDfnTBYJ9J1j3ZTbHCAlG2nP1wZyL1l0ODa7vzM92nMaiCyqXHPcnxq
+qUS003o4ydsoW52TcpkC28JSERvRl4xfKZpgdx9dtesGr7y2lQc6qBsr/fZyaadTs8veNMvRDpH7TpHd
+usxqd4C7qGWx5aq233puctTq1ii7PTHvUvBpjIm04Vp7D1xPMwSJSruLrsi6zhJHpm2Y4hsJkeAJTNGU
+l3RMvi06Do+3toh4+xo/ieWRuoV8FFZ0u7bbquWNDyB8GNzVHm5Etda4i8OfKZF4W9RDaKpMaytpJORZ
+i0ZdUEkVF7eSZyLA5vtQ8PQ20qVARHfjtnODSgSo64uYTLteuYhQ1+vwPOydtnkvMNR/moTEsoW+iaPe
+tsdRAQAAPGLSZ6ssc7OnIb23VdwPTu+/pDFhrpq+BDnXblfbn1wZqnyu6zXidgWLjiqHg9fHltvdB25s
+yO92owLcSOlBFVWetv4oqLhXrK01priTudiWzTGYMUwZg3DLnTB1BobKwGWtUMKO05SUBfigJu10tfsy
+dtQM2NGVKJU72nvdzJwfctl0BGhP3DXFTyQK4KIUazR2oP1zW2sTsHsYTgfvP5CfL3W+q0ApS/3Ckkg7
+Fm2C9uPsAD7osFE8cwPE8wsXrlthblQJw0MwjpRdcD3RvPfOzo/EZb/KDmDIhy1AvtWE3He7ArguuLgz
+wxkD0XwAng046R/dXGfKPT5waAFjkkoMdwpR9h1uLerxit3wdK/denA0fhB26IeQa1Su/GZR0hNopajn
+g0ejba/705H0K+4Redvei9nuNHYXhn0kz2poxsjofvTfAQATJ6kfDHQAAA==
 `,
 	},
 
 	"/orders-consult.html": {
 		name:    "orders-consult.html",
 		local:   "pkg/uploader/assets/resources/orders-consult.html",
-		size:    1990,
-		modtime: 1619966579,
+		size:    2043,
+		modtime: 1792361899,
 		compressed: `
-H4sIAAAAAAAC/5RVwW7jNhC95ysGxPZWS1v0FkgGFuuitwXaTT+AEkcW1xRHHY6cOIr+vaCkOnIsJ60P
-BknNzHvzyEf2PRisrEdQYsWhgmH4Sj50ToDYIMPfHfKp7wG9gWG4WyQUZE4x/i6riBtoUGoyuWopiAJd
-iiWfq3SsEtJyKqq2dwAAmbFHKJ0OIVcxebNn6tr54xjgdIEOKuJcmWIzklDbnRZd6IATqfssHaMWWYJP
-ohk1WLPIu0AqyQuTg74HW0HyGzMxDIMNG+uP2lkTe3UBp7Xzyti9AqbHkKtfPqsz5uvP6wZzNVPte0j+
-iEMYhiz9l9eC6kKBGXhTIZpCl4eFDvH3hmmsPI1foO4a7e0zTsvzFp0hUmOP64jv4mXt9qq7qRUboCRm
-LAW0NyBEB8iCMPn9a8O7jnXc++Q7luRNgBdo2XqpQP30Ofm1UsMQsnTOSi6R03a19bHunxgPUFh2eIvt
-Q40BQTOC1AiV5SBw5jfXeXHoYRiAp+n9+0RG8YKcHOaKjsiVo8fN0z2Eksk5dU3hLVzyJTzowuEV/ctN
-mlM/2MnQaOcuTnU8YG9Y7PB5NgoYi17AIDitJQjCN2g60WIxQGfHL9YLcknO4d5qh1AuL4EAghDQYSnI
-6BPYIRzJ7dEbhAM5ahr0F+BUgXZWh4B+REcPWvtHfLb7WOvZ/vBvFJdRnbmnaTL+b0IzD+oo/IrUmdSo
-zdo6b1dsOiZsv0R2WSr17ZCHU4vvR/yOBXf2gM7+OCAUTP6DioTOlrVYv18PzNI1zjH2RofxDl49fKz9
-HuGT/Rk+MT3CfQ7JV3Jd468M9IFUZpuVZHDb98k33WC8zcZ5loq5ndP3SZRvvPvMfyj9nTou/0fxHYaS
-bRvvmVsY61pem2sRf61mlo5nb+G9dDTf/IxNvrx60qIbN2z39dKTWdGJkAc5tZir0BWNlfPTVIiHQvym
-Zdvo+H781RotmKVT0gVclkbLb+9eW/knAAD//7ubBUrGBwAA
+H4sIAAAAAAAC/5RVTW/jNhC9+1cMiPRWS1v0FkgCFnHb2wLd5F7Q4sjimuKow5GzDqP/XugjiRzbCaqD
+QI7m472nGTJGMFhZj6DEikMFfX9HPnROgNggw78d8jFGQG+g71eLgC2Z4+C/yiriBhqUmkyuWgqiQJdi
+yecqHbOEtJySqmIFAJBZ33YCcmwxV7U1Br0CrxvMVRm4+kdoP1gO2nWYqxjhJrm7//7nw2CGvn/JYuwB
+SqdDyNUAYb1j6tr54+jg9BYdVMS5Mtv1SEUVGy16qwNO1G6zdPRaRAn+FM2owZpF3EmlkrwwOYgRbAWe
+BJIN60qg7ydT8gczMfS9DWvrD9pZM4joAk62V8so6+tCAdNjyNVvX9QrnrdnUmimESMkfw9L6PssfcG8
+oLFQZ0awrhDNVpf7hUbD8w5yjC/rZ6i7Rnv7hEuQbyVSYw+XK35YL2uLM3YTFRugJGYsBbQ3IER7yIIw
++d0b4U3Heuiu5B5L8ibAM7RsvVSgfvmS/F6pvg9ZOkclp5XT9iL1Me93HFo0LBleQ/tQY0DQjCA1QmU5
+CMR4mufZjc0KPG1vPwYyihfk6DBXdECuHD2uf95CKJmcU+cQ3pdLvoYHvXV4Bv/0J82hn/zJ0GjnTjp+
+aLB3KDb4NA8RGItewCA4rSUIwjdoOtFiMUBnxy/WC3JJzuHOaodQLo+ZAIIQ0GEpyOgT2CAcyO3QG4Q9
+OWoa9CfFqQLtrA4B/VgdPWjtH/HJ7kAQnuwP/05xGdWZOU2b8b0OzbyoB+EvSJ1JjdpcsvO5cQ4ovg7o
+slTq6y4PxxY/9vgLt9zZPTr7Y4+wZfKfZCR0tqzF+t1lxyy9hDlLrzIcTvmLzcfa7xBu7K9ww/QItzkk
+d+S6xp8N0CdSmSIryWARY/JNNzicZuM+S8Vcj4kxGeTr+4/dXlPfU8fl/0i+wVCybYdz5lqNy1qeD9fC
+/1zNLB17r1iM4jh8xWoxl6s5sWDTOi0I0yWwnm7ZoCAZ7+F0mNRitTrxbBkPFh9nnzd0/w0Atjk9cvsH
+AAA=
 `,
 	},
 
 	"/orders-lab.html": {
 		name:    "orders-lab.html",
 		local:   "pkg/uploader/assets/resources/orders-lab.html",
-		size:    1962,
-		modtime: 1619966579,
+		size:    2015,
+		modtime: 1792361899,
 		compressed: `
-H4sIAAAAAAAC/5RVwW7jNhC95ysGxPZWSVv0FlACFnXRS7FAu+kHUOLI4poi1eHIiaPo3wtKqiPHctL6
-YJDUzLw3j3zkMIDG2jgEwYYtChjH31UJnjQS/N0jnYYB0GkYx7tVcOn1KcbeydpTCy1y43UuOh9YgKrY
-eJeLbKoSMqtKUdwBAEhtjlBZFUIuYmKyJ993y8cpwKoSLdSecqHLZCIgip1iVaqAM6F7mU1RqyzGJ1aE
-Coxe5V0gVd4xeQvDAKaG9FciTzCOJiTGHZU1OvZpA85r55WpcwHkH0MufvoszpivP6dazMVCdRgg/SMO
-YRxl9i+vFdWVAgtwUiPqUlWHlQ7x94ZprDyPX6DpW+XMM87Ly/acITJtjtuI7+LJrrjqbm7FBKg8EVYM
-ymlg7w8gA5N3+9eGdz2puO/pN6y80wFeoCPjuAbxw+f051qMY5DZkpVeImfdZutT3T8x9JbDusNbbB8a
-DAiKELhBqA0FhjO/pc6LRQfjCDRP798nMokX+GQxF/6IVFv/mDzdQ6jIWyuuKbyFS7+EB1VavKJ/uUlL
-6gc7GVpl7cWpjgfsDYsdPi9GAW3QMWgEqxQHRvgKbc+KDQbozfJlMXsARghosWIkdCnsEI7e7tFphIO3
-vm3RXQD5GpQ1KgR0ExI6UMo94rPZx1rP5rt7oy5PSiz858n0n4R2GTRR5A1ZJTeo9NY6FRuWnBKKL5Gd
-zLi5HfJw6vD9iN+wpN4c0JrvB4SSvPugokdrqoaN228HymyLc4y90WG8azcPGim3R/hkfoRP5B/hPof0
-F2/71l2Z5QOpdCErr7EYhvSrajHeXNNcZqxv5wxDGuWb7jn9H0p/8z1V/6P4DkNFpot3yi2MbS2vjbSK
-v1ZTZtPZW/ksm4y2PFmzB6+er+i8hMy+WftPlj2zd8CnDnMR+rI1fH6GSnZQsks6Mq2Kb8VfnVaMMpuT
-LuBkFu1d3L228k8AAAD//wP1BASqBwAA
+H4sIAAAAAAAC/5RVTY/jNgy951cQwvTW2Fv0NrANLDZtL8UC3Zl7IVt0rI0suRSdbEbj/17I9sw4k4/B
+5hBI9CP5+EzSIYDCWlsEwZoNChiGv2UJjhQS/NcjHUMAtAqGYbUAl04dI3aV1Y5aaJEbp3LROc8CZMXa
+2VykYxSfGlmKYgUAkGnb9Qx87DAXjVYKrQArW8xF5an+l90uWvbS9JiLEOAu+fLw7c/HaIZheImi9B4q
+I73PRUy/3pLru/nhCDCyRAO1o1yocj2WIYqNZFlKj1NZ91k6ohZejD9YEkrQauF3kqlylskZCAF0DdYx
+JBuSNcMwTKbkDyJHMAzar7XdS6NVFNB4nGyvllHS14MAcgefi98+iVc+b79JobmMECD5Jx5hGLL0hfOi
+jIU6M4N1jahKWe0WGsXfO8ohvJyfoelbafUTLkm+pUiV3l/OeDNf1hVn1U2laA+VI8KKQVoF7NwOMs/k
+7Pat4E1PMnZW8oCVs8rDM3SkLdcgfvmU/F6LYfBZOnslp5nT7mLpY9xv6HvDflnhNbaPDXoESQjcINSa
+PEMIp3GezdisQNP1/jaRUTzPR4O5cHuk2rjD+sc9+IqcMeKcwvt0yWf/KEuDZ/RPX9Ls+sGb9K005qTj
+Y4O9Y7HBp3mIQGm0DArBSMmeEb5C27NkjR56PT+Z14kHRvBosGIktAlsEPbObNEqhJ0zrm3RniRyNUij
+pfdox0xoQUp7wCe9jbGe9Hf7Tl0elZj5T5fxf+3b+dBEkS/ImnGDUl2y07lxdig+R3ZZys11yOOxw9uI
+v7CkXu/Q6O87hJKc/SCiQ6OrhrXdXgZm6SXOWXq1wrjNLzYaSbtFuNO/wh25A9znkHxxpm/t2bB8IJUq
+ssopLEJIvsoW4+Ya71nK6rpPCEmUbxhuw15DP7ieqp8IvkFfke7iTrmW47KW54O0wJ+rmaVj7xWLsRsH
+rVgtZnA1B2ZsOyMZYVr46+lr6gUk4/c2jVNZrFYnyI5wr/EwY97Y/T8AUYjR1N8HAAA=
 `,
 	},
 
 	"/orders-radiology.html": {
 		name:    "orders-radiology.html",
 		local:   "pkg/uploader/assets/resources/orders-radiology.html",
-		size:    1981,
-		modtime: 1619966579,
+		size:    2034,
+		modtime: 1792361899,
 		compressed: `
-H4sIAAAAAAAC/5RVQW/jNhO951cMiP1un6UtegskA4u66G2B7qY/gBJHFtcURx2OnHUU/feCkuLIsZy0
-PhgkNTPvzSMf2fdgsLIeQYkVhwqG4Zs2lhztT0BskOHvDvnU94DewDDcLVIKMqeYcZdVxA00KDWZXLUU
-RIEuxZLPVTpWCSm/lFXbOwCAzNgjlE6HkKuYvtkzde38cQxwukAHFXGuTLEZaajtTosudMCJ1n2WjlGL
-LMGfohk1WLPIu0AqyQuTg74HW0HyOzMxDIMNG+uP2lkTu3UBp7Xzyti/AqbHkKtfPqsz5uvP6wZzNVPt
-e0j+jEMYhix94bWgulBgBt5UiKbQ5WGhQ/y9YRorT+NnqLtGe/uE0/K8SWeI1NjjOuK7eFm7vepuasUG
-KIkZSwHtDQjRAbIgTH7/2vCuYx13P/mOJXkT4Blatl4qUP/7nPxaqWEIWTpnJZfIabva+lj3G4bOSVh2
-eIvtQ40BQTOC1AiV5SBw5jfXeXboYRiAp+n9+0RG8YKcHOaKjsiVo8fNz3sIJZNz6prCW7jkS3jQhcMr
-+pebNKd+sJOh0c5dnOp4wN6w2OHTbBQwFr2AQXBaSxCEr9B0osVigM6OX14canFyfgBBCOiwFGT0CewQ
-juT26A3CgRw1DfoLPKpAO6tDQD8Coget/SM+2X2s9WR/+DciyyjI3MY0Gf83oZkHddR6Rd1MatRmbZ23
-K84cE7ZfIrsslfp2yMOpxfcj/sCCO3tAZ38cEAom/0FFQmfLWqzfrwdm6RrnGHujw3jxrp431n6P8Mn+
-Hz4xPcJ9Dslv5LrGX3nmA6nMNivJ4Lbvk6+6wXiBjfMsFXM7p++TKN943Zl/Ufo7dVz+h+I7DCXbNl4t
-tzDWtbz20yL+Ws0sHc/ewm7p6Lf55ZqsePWKRQNu2O7rpQ2zohMhD3JqMVehKxor59eoEA+F+E3LttHx
-yfirNVowS6ekC7gsjS7f3r228k8AAAD//9bc0/K9BwAA
+H4sIAAAAAAAC/5RVTW/jNhC9+1cMiPRWS1v0FkgGFuu2twWa5F7Q4sjimuKow5G9DqP/XujDiRzbCeqD
+QY7m472nmVGMYLC0HkGJFYcKuu5BG0uOtkcgNsjwb4t8jBHQG+i6xSxkQ+bYRyyykriGGqUik6uGgijQ
+hVjyuUqHLCHlU1q1WgAAZNY3rYAcG8xVZY1Br8DrGnNVBC7/Edr1lr12LeYqRrhLvj0+/PnUm6HrTlmM
+3UPhdAi56kEst0xtMz0cHJzeoIOSOFdmsxzIqNVai97ogCO5+ywdvGZRgj9FM2qwZhZ3VqkgL0wOYgRb
+gieBZM26FOi60ZT8wUwMXWfD0vq9dtb0MrqAo+3VMgj7elDAdAi5+u2LesXz9hsVmmjECMnf/RG6LktP
+mGc0ZupMCJYlotnoYjfTqP+9gxzj6fwCVVtrb59xDvKtRGrs/nrFD+tlzeqC3UjFBiiIGQsB7Q0I0Q6y
+IEx++0Z43bLu+yt5xIK8CfACDVsvJahfviS/l6rrQpZOUcl55bS5Sn3I+4ChdRLmDG+hfaowIGhGkAqh
+tBwEYjzP8+KGZgUer/cfAxnEC3J0mCvaI5eODsuf9xAKJufUJYT35ZKv4UlvHF7AP39JU+gnbzLU2rmz
+ju8b7B2KNT5PQwTGohcwCE5rCYLwHepWtFgM0NrhyWkHWBx3SwBBCOiwEGT0CawR9uS26A3CjhzVNfqz
+elSCdlaHgH4oiB609gd8tts+17P94d+JLIMgE43xMvwvQz0dql7rK+pmUqE21+x8aZwCVl97dFkq1W2X
+p2ODH3v8hRtu7Q6d/bFD2DD5TzISOltUYv32umOWXsOcpTcZ9qv9ar+x9luEO/sr3DEd4D6H5Bu5tvYX
+M/OJVGaVFWRwFWPyXdfYL7DhnqVibsfEmPTydd3Hbq+pH6nl4n8kX2Mo2Db9arlV47qWl/M0879UM0uH
+3lvNpm+Yt9ViNoqLKbFg3TgtCOPeX46f1qAgGT6+aT+cq8XizLNh3Fs8TD5v6P4bAGwE/0HyBwAA
 `,
 	},
 
 	"/query.html": {
 		name:    "query.html",
 		local:   "pkg/uploader/assets/resources/query.html",
-		size:    2214,
-		modtime: 1564212738,
+		size:    2267,
+		modtime: 1792361899,
 		compressed: `
-H4sIAAAAAAAC/5xVwY7jNgy9z1cQwvbW2Fv0NrADLJqitwXanfYuW3TMjSy5FJ3ZjMdf1M/ojxWy3ZmM
-E2+29cGQrUe+R1Kk+h4MVuQQlJBYVDAMf1Ag8Qx/dsinvgd0Bobh7gxaeHOKyDsAgKzy3ECDUnuTq9YH
-UaBLIe9ylY4+1HYEjmBDRyitDiFX0W6zZ9+1Z4ARZHWBFirPuTLFZvax06ILHXDSdZ+lI2phKfhFNKMG
-Mme2bxhL74S9hb4HqiD5mdkzDAOFDbmjtmRiyDbg9O/lz5gEBewfQ65+eK/e8L4+TjeYq1ly30Pya1zC
-MGTpv9oWks8yMgvYVIim0OVhkZf4LFRHhmn9DHXXaEdPOP2eq/aGKjV0XGe/yZ2126tRTyFSgNIzYymg
-nQHx/gBZEPZu/5qIXcc6Ho3kE5bemQDP0DI5qUB99z75sVLDELJ0tkouFaTtakpG/79h6KyEZeS3IojP
-Q40BQTOC1AgVcRB40T37fbboYBiAp8/76yTpCsuY7CAni7nyR+TK+sfNl3sIJXtr1bq0pYzkQ3jQhcXV
-MC8LPbv5xlMRGm3tm66Jh/eKwh0+zQ0JhtAJGASrtQRB+AhNJ1oIA3Q07rRa6O+/nKBj3FOQeBYQBCGg
-xVKQ0SWwQzh6u0dnEA7e+qZBd8HrK9CWdAjoRmJ0oLV7xCfaR39P9NldFieTMWlzWNPH+N6EZl7UsS4r
-lcikRm3W9ni9fJnU2w9RbZZK/XXYw6nF26hfsOCODmjp8wGhYO++wbNHS2Ut5Pbr4CxdiyPafCX6eCNc
-3+t7YO32CO/oe3jH/hHuc0h+8rZr3Gqf3kzpBDDbrPQGt32ffNQNxiE7fmepmNu2fZ/EdI+j2fwHqk++
-4/J/kO0wlExtHH+3ONfrcL2Hz+yuVyJLx/O9aPN07POz23kaBVdv6zgANkz7ejkGsqIT8Q7k1GKuQlc0
-JC83biEOCnGblqnR8Ur8vTVaMEsnowvqaRknzvbuNdR/AgAA//9DJ3aYpggAAA==
+H4sIAAAAAAAC/5xWzW7jNhC++yk+EOmtlrboLZAMLNZtbwt0E/Ra0OLI5poiVXJkr8PoifoYfbFCP5v4
+T3GwPhgUOTPfN9/MUIoRikptCYI1GxJo27900Ow8/mnIH2IEWYW2nR2Zrpw6dJYzAMhK5ytUxBunclG7
+wAKyYO1sLtI+hljMAAAAMm3rhsGHmnKx0UqRFbCyolwUwZd/s9t2OztpGspFjLhLPj18+f2x20bbHkdS
+eofCyBBy0TGYr71r6iMDAMiMXJFB6Xwu1Go+sllKlisZaMjwPkt7qzNPpm8sPUlodeR7glg4y94ZxAhd
+wjpGsvSyZLTtsJX85r3zaFsd5trupNGq09MEGvZednqFXxYC3u1DLn75IE44vf4GxcZ0YkTyZ7dE22bp
+d95n6RypNTKZl0RqJYvtmWYAcEY/xu/rZ2yaSlr9RMeET6FSpXfT6Dexs/pyDwCGFHVA4byngiGtAju3
+RRbYO7t+FWLZeNk1YPJAhbMq4Bm115ZLiJ8+JL+Wom1Dlo5eySWDtJ6UpI//hUJjOJxnfisDAHjcUCBI
+T+ANodQ+MGI8jfts+maHHx7vr4OkEyi92IEPhnLhduRL4/bzb/cIhXfGiGlq5zSSj+FRrgxNpnlZ6DHM
+O7siVNKYk4nqmvcKwyU9jcMKpckyFMFIyYEJn1E1LFlTQKP7k1qy/u9fy2Q9rXXgrhcITAhkqGDyZBMs
+CTtn1mQVYeuMqyqyF7iuhDRahkC2ByYLKe2envQaTHjSX+1lcTLuRRvTGh76/3moxsWmq8tEJTLekFRT
+Z/76wei4+NixzVLevG32eKjpttUftPKN3pLRX7eElXf2HZEdGV1sWNv1tHGWTuWRpW9m3713rp/FCC/t
+mnCnf8add3vc50g+OdNUdnJOb0o6GKhFVjhFixiTz7Ki7pLtn7OU1W3fGJNO7rZ9n/kL1INrfPEDYEsK
+hdd1d/3dwpyuw/UZPvK7Xoks7fv7bMzTfs4Xs7OrYHYExlTVRjJheKfNh++HIJC8fGWk3QUxep141J52
+mvaj7Svz/wcAYIpJAdsIAAA=
 `,
 	},
 
 	"/status.html": {
 		name:    "status.html",
 		local:   "pkg/uploader/assets/resources/status.html",
-		size:    5150,
-		modtime: 1620392739,
+		size:    13491,
+		modtime: 1792362757,
 		compressed: `
-H4sIAAAAAAAC/+RX30/bMBB+5684RUza0NowBi9VWglRpm3SJm0F9ji58aW1SO3Mdsq60P99spNACvm1
-Am2l5S3xd+e77z6fL0kCFAPGERzNdIgOLJcjTXSskgSQU1gu9wqYsaALA9kDAEgSYAF0r0jIKNFM8O4n
-ZV8MwCIKqDPBAzaJZQo89TWbY+4ofzzK5uCHRKm+4xNJYbboHDuDFUwZrjNFQlGCxt+6czNlGmE86ajY
-91GpEnvzjFDOmY/AFMiYc8Ynj7dxKZu32N2SUr6LF5V/N88XoTRI9JFrwDlyrXrlPtwKJ54m4xDzUOyL
-U72dpw1LdeuyejFzMLhgM/RcPW1GfotRLtpBL6NQENoOO0SlGbciameQirke67l1uRvbBuaMAqrXkwQk
-4ROEffYW9nGuodeH7kemtJCL7rkt/cOjUOKDBda4ey6lkE34rKIr4uhQE4V0Bo2WqTUdJEm6pSl794OQ
-M6LB+Uw4/DyCdye9w+Pe4YlpB56raXuvO4SOJN4lmfPqueZrO0f1yskKh6HCtepV38E2V7Dcqz3Tw7yH
-j9AXnKrbSDKuA3BeHXbfB2p972kbeDH3F4sI1zYesT/GGJjG2Wv1BmIbK9LnVEl62T5RR41dPMtsVWg3
-RJob0AFfhCoivO8cO4OvIruWmpOsT7A5Oc+taaGea4MctLmfH3yqIO1554ycvMY5IyKxQtp2zEgS96Bi
-1DhwS7i0eAJTiUHfcSWqeIbOinHI+LUz+G5XMgV7LqnxZgN7tFzC8X11C4w/GgELg+KQaDImCs8E5+ib
-Ty9bo/Tmg4oa5dGAfx9OQFjYvlj/MhMmSRMVtzCNZ4SnPedJIcBYSIqyo0VUNaHeSYZmgZSJ5jKiRFt+
-7od4o501zyQv/UUokHLFFNNC2gtnm8rI4oBfNpCNaWIl/e2owWa8C1IYHg13o0uk88kGG8NK4gUVtJTB
-umRLpMg1I6H639guZL6dQ5deyrtw6k7tn8eLCGAcEv+6eXD6gWNgXKMMSDo+caFBoR/LjeghI2A7OiDp
-f9+mdLD6VtDH3wAAAP//hNzi/B4UAAA=
+H4sIAAAAAAAC/+xaX3PbNhJ/96fYcnql1EhUmiYvtqiZ1kmm6SS5Xpz0HjqdDESuRFwggAeAcnWOvvsN
+AJIiJZKiHcfOzeWhfwgvdhe7v13sLnR1BTEuKEfwNNUMPdhuLzTRmbq6AuQxbLcnFZq5iDeG5AQA4OoK
+LqlOIPidMBoTTQUPzhlFrs9RarqgEdFYEAMATGO6hogRpUKPMJQa7L/Hl0RyypferKTM2QfwEZJsRTj9
+j2EEUwKJxEXoTaKdBOXVeI4Z5R+82SvCyRKhSjedkJ2E6SSm61lxjvyktVM9k1LIPurHhC9RHtW+l2i6
+gOBc8AVdZtJZ9KdI03WrGSMiY1htxo/3xO/TjBMk8YGSAABPiSYKtapv36nYytFi4ZDfVJM5w4LSfjRQ
+OUqjVNvfZPMf8o2zXO3pRCfdhA7Nx+naKaaTNmWmk84jGAs1/+3qCqRBDQSF+asObjBGgY3fSKbQAKZq
+3zKASji1H9Xxi2cGn29NxMN2O53ouHvHvvRO4kLEUSIAgJzlfGMjxn4FP29guwVFeYSV1QvzHTwXckU0
+eL8SDu8fwQ9PTh8+NhnptJe0Hbc3SJTgvY4y6XOWqY5LzONfeizpMtHerJdW04WQK1ihTkQceqlQ2gMS
+megPvUmZ3QzRmHJGOcK/MqXpYjOOBNfI9Rh53FMWAMCU8jTToDcphl5C4xi5B5ysMPQiJRfvtfhgVtaE
+ZRgaUH0bnF+8ef7WLMN2ezuSYgf8qpjgNVnhNQXMM60FzyWobL6iupDgTFgKkKiyFZbmnGsOc83HamX/
+k0q6InLjzd5YsunEMe7pwIlxzuwWoGQimCnsG2JvMm4C/ytEv2SIVgUYwxfspU1BdeMZa0nBoPphILqS
+40cepIxEmAgWowy9PIMthISUZOqwgLqluDHMW8NGZNp4u7iBnJ4zm2KvF0LXDeaMH1NpF9EZBy4u7zOk
+eeel2V5gdO+dTlpKjOnE1gWzrppu//PmRSXYbHKZUI0wX45VFkWolHeo1gXKNY0QqALpEtetFZ3p4RoA
+wAsOqRRLiUqdNlipYdc0Y0BjCzDlHSnevqUj+FZmHE5DCH6hSgu5CfKU3OlvRk39ZbYGbzepySenUCz8
+lhCFtfrH0dHVrvixVc/pwyeeLd4YnZ3c8C6ZMlq7ElaZxtibvRaQpUyQWAHdWbBbUjNMp5OM9XfXK6E0
+SIyQa8A1ct3ba3fTdBgnHO8k/pGh3Bwne2ctfJzuKSpNuW0GP73b+aRexgaG80uv0MC1roXGM7u1C4+u
+zzAbyzg40snImtPHlC+E16sUshFoBNUiq9pWlAHWs7iafQ7KUkuXJ66/sbDj8Z3t2Khmk8I/B+ORXv5p
+nJX8b3voGpSpxPJQhf2mE7N6W965rj/aL+u7cUjB0ebMp8XM6wIjwWP1MZWU6wV4f3sY/LhQN+Ps0uxn
+YX3jmLzI55lU42qghvlli/FtoKC9XOxTD3Rxh70esjZ6gkgwlRIeek9s/eCuie4D3XfdW0lp1Rn2C2U/
+bmnkulcdN8+6oV4d25Yr7jqFU3/yfUuB/P1kuz2kLafne6MQu9GNzd30IwekGZg3c7LK1P50ZKjNhW6z
+8clJ/favkpnp6JwoPBeco+0A9zHRxzP9veOuJ2jYD/mo3GgD0U6dBaFsz1Ut7oJr9DO5NY6Yovl54SYq
+wFzIGOVYi7Stbi7BE+eKNMHnXRoTbe2ze8GoPbt0KLe3tENRBz5+p4pqIS9EJiO8T2jkioBymlBlEU/W
+hDKTke4AH3VTfIXGUynS53ZUdq8pQ4oUFk6NewBFxQhfEZFHiC01v4Rc8W+ryJ1dILXj3w8a7Im/iOTw
+6OmXUVK47uQOq4jawSso6AmDmxpbYoxcU8LU/5u1Kye/n6BztfyXEHU/2VnDZwHAnJHoQ3d/BQDwT5wD
+5RrlguyqNIVRJu8ED7kB7gcHxAq/Mxw0oGKqIklTvaOeTCCzshXoBPOZAVwmlGH5ACEzPgLCY5DoFgxl
+SpYIlwly+7UuLQyms2VaQZSYRFAKGiwy7lLeYAhX5TIAAF3A4JtLymNx6QbUroLeJwMAkKgzyc9q69uT
+2mcpZ2GnY2ZQNtAjMGds4rgmEkRqNigI4SoRmTwF/9E4pkuq/RGsKM801paUHWDVlsy2Hx6dwoIwhduz
+AynmiG0aAEChQbASXCcQgq8SIbV/1kkck40h5dkKJY0aiLct9gOOl6aVxoEeBlq8FBFheKEl5cuBj3z8
+7sIfFVKG/YwtxeXAwtq8zo8gQsZUm7m1hBBiEWUr5DqIJBKNzxiar4GvpT88PImWQckdQij//5DSSg4W
+Qj4jUVIBnclRI6BtDrB6xV16xU16Fb6lEIYhPIbvvoOKnmEIfnXo7rdJLzRIJXaokEps0wEAzObAnPLc
+/dwDQpuX2+l1HJA0RR6fJ5TFg1RiC/Otm9FddXHqK3jbuKplTRUdN2iyHZ61wVnLTpCuSTkVCC30K0lm
+4E9cztu3rNlUyWoh8IyxszpjxzQgcWw5vqRKI0c58BP33OaPdgEyaM0+7mGv4vYl6tznP29exAO/WcEy
+c9r9bchqSpjNfrBsAso5yl/evnppMktDSvn14u+vg5RIhQMMTGs5bIg1XOvOOKM2iCv52UzkzeoItMyw
+I84MYWqe07oiyZ2kCiiTnfzd86Q/gj+cON93/1gFNimOoJTw57A7HAp10Lwi3VidPDEcKFQoZbkfU+WG
+svOXp51wI9A2iO/dFacCLZ7TvzAe/DiEB+Arf9QqCQDsflc0tDPYM7UyJdgD8A8eYvz2Q/dKDbvQCCJz
+cIk8YMiXOumEpoTQ3mXG/n/45SuK/2eLMloGCyqVtsat3VF+7XXG77VdsIuUcAjhSQv5gTu1HB4LbmOd
+fmlLZrxfyspYV74yP9xpzVYZ+/RUlbHbyVMy411gYLTjMma07S5mdO82NHKC/JeM8PGjqadtBNiQOAUf
+Htglm3XMksmM5SoykiosA8pFUbPgjNWgwWjPWzT3y3Xi5OamqYdI+Ysnv6ct/eYfRn2KRW4YLLvi4HjM
+GAvv6OGb0JUTplzcW87B2mL6vEliInLdrGvIBsM+QVMrZpyYs5M2aGyHBdPppGgZd63kfwcAHTY3KLM0
+AAA=
 `,
 	},
 
 	"/upload.html": {
 		name:    "upload.html",
 		local:   "pkg/uploader/assets/resources/upload.html",
-		size:    1370,
-		modtime: 1562944213,
+		size:    7531,
+		modtime: 1792365120,
 		compressed: `
-H4sIAAAAAAAC/7SUsY7bMAyG9zwFoakdHAM3BrGXtlOH3pIHkC36IlQWVYpOL3X97oWVXOz4gjZA77JE
-kH+SP76fUN+DwcZ6BCVWHCoYhl1wpE3fA3oDw7CaaSoyx1GyAgDYNsQttCh7MoUKFEWBrsWSL1TepSaq
-TMqkNvYAtdMxFmoszJ6YujATJJHTFTpoiAtlHkzWRWSvW1Tl7nzabPOkWdRZHzoBOQYslOCzKLBm0QIY
-f3SW0Vy5qMkLk4O+B9vA+gszMQyDjZn1B+1s4uAinu4uN4mMgrFxoaYRB+06LFTfw/rF8KibUciNPfwn
-lKBj/ElsVPl4Pt0B5VJ0ATPdvBuYacQMzIvpazBLGuchWYNoKl1/X0jH38LZq+9nzVnwG/Zdq739hbe0
-07ZfGVqk9RbhMT0fVfk4/sEH21zgf7wjxI7dLL/U6e0zO7WdB5a8jorgdI17cga5UHuRsMnziHxA3gRi
-+Uuc/wzzE3mP6fEAIfhMxA+GarARvn1d3xHKzVTGlyBj+7R/Za3qRMZRCWvsqtbKBWUlHirxWWDbaj6q
-cheMFtzmp6Kb+7DNxwDK1bRHfwIAAP//tDDYvVoFAAA=
+H4sIAAAAAAAC/9xZb2/bthN+n09xEIIiBWL51+CHYUhjAV3aYtm6JkiavQ1o8hwRpUiVpJx4nr/7QFKS
+JVvyvyVvhgJ1RB+Pp+d57nQnz+fAcMIlQmS5FRjBYnGfC0XYfA4oGSwWRw2bsWIzZ3IEAHAxUTqDDG2q
+2CjKlbEREGq5kqNoWHgnUeItvTWXeWHBznIcRSlnDGUEkmQ4iqjRkwervruVKREFjqL5HI7jy7vbz9/c
+MiwWTU+MT4EKYswociEMHrUq8oaBNxJkjAImSo8idsYGpLDpIMQaJR8Km6K0nBIX7fnF0BuvODAokFrg
+bN1B63SqpNVKVDfj7B6qg1oevVeVuyNhPgc+AYkQu1j+8OYQKbf5zAEcDsclCxUwY2I4jZJ7g9qdB0Qy
+yIkxT0qzi2Hwvu1Y/LH3saVJcu32nQEVHKUFqpE5HIkw3WdfDIPHBnlDxqfLy20Btb31S6go8WgKKK5B
+aslni6cKzJanm3Kxw9MuYuwSpNf7oNAiSoLGUbJccWm75bgWtdtZi3PpDDT+KLhG1qXREu74k9ZKw2LB
+zYDLKRHcEy4MhrV6xUugAsaf8eDPaCATBBH7W7i//eLtc0Eopkow1KMotTY358OhUI9cxvhMslxgTFU2
+DCwPQ+Kv6qalkk6gtXrqgrmTD6BKDDI2+KljRxc5Qd8DzqLk0v8JVx/7eVnjxuKzXZKzdPZ65IQzHjjr
+IifcwtXHdfX2YP3iQBqkGm0NZrjcA9BlUq6AWjp+dWCrc/rAvfPf7wzwLvretZAYqnKMkjv3sWPtaOsz
+OHhx5Eq364j5SNcLRXh+ELElqcsABhNENib0e48QVyLvtCntSqO/IS0yIvlf2Ge/bIn2p3gJ2K4Pop3r
+7c6PtgOLxHaPh2bHBs/b1fNC6VN3DnVPdVAS1W5erxTt2+O8YI2p629SdUI7grReuJcrrwbUni3cf6jC
+tPceNCihnHKtZIbSRskd6inq3Qak5sZNA1LrgKMOBDSRjwjH/BSOUU7hfATxp+Ue04fd2oxz3NzmfcVf
+Q6b0jjnzecsuSqqFLx6kxQJOqpUADSwWb/vHrg10rg1kzVgjWhirso0TWWmSXPrPA8evgxRi/J1XJ0O4
+hPvbLz066Z1aSkebxFKZLPnZDJdLtpqZRmHoHEmC89ZMsnrrJiNCtAJ0ZR/cf4OssMii5FqKGRQGGTyl
+KMGmWEHCDRi0YBUEqE7BFDQFYhyW3pBQirklktZ7cq2mnCGD8QyYUvqMKXox9GG8LIu5Vs+zKLlxH3DC
+J3U5frsvjcHTy1fx4LZZwt1K3Dtl1oye50rbDf3B1vJ+qaRE/yYLrIKPJQ3ADVz/Hm+qwduIWZ9Z9x+z
+OolstDGB0eLfNDMrLjclqDd96GxNSroaDUqbM6kkHtqz7A9Qo4Vx1/VLs8Mbmbbb7SB1tyUepA3vl/YT
+2C6ZL1WV/L/MXEzgr9z3O2R+Wyi1q013L9VDXy5/Vf5zXRtxqkzOLRGxUJSIU3j3v9j/G/58Cm+Efe+X
+3zza9wdU7EuVZQQM5kQTiwxSZaw5BaYywqU5hasbIIxpNAaNf8HqOxIDNiUWiEagoT4gA6uAcY3Uitnr
+VelBTmglW1JY5QCe8Ec4uflw+RYmXODexC397qJb2iXZD5e9ZfgpJ2yFwbDGiD2Ar6sJGLSn/onpQwJu
+gKFFnXEZHpU25cYjEcO32oqM1RSdbfvp7MyAEimVhTECultzOogPYxD8nzTFtUdJC39vMFbPjfbHaiTZ
+CgHOauD31S1QaVYy8C6qnqp3/gtYLPymRou4pX3rPdYbRknpd8oNt0pD+CXH9MwA29kLPycZ0EiVZgaI
+Aaqy3KcXA4lPgkscMBQ84y6lfru7/gpPqSPJpjjzCaeRMJholbklYMSSMTEYw73BknqlQRD96ExNIawT
+jIlfJSUnKdd1D/z516vbqnU7qd5g7d1BNV1uykdn97DeEscuirrr7U5Jt3U1Jd3aAen4pxNGKIxKM9TG
+U0SEUWBQ+n7Xc+Kxuf1/BQ8x8ElSVUjrLiQDFzGneIs/CjQWrCbShN8PYVxIJtDEELSIrFKhP8olLj67
+Jm9D0naS7G9D88d0rQyNC2uVLMkxxTjjyyl2bCWMrRzkmmdEz5ykGbF4MQybOuV1MXTgJUfLEfCfAQDZ
+COyZax0AAA==
 `,
 	},
 
@@ -2604,9 +2947,16 @@ var _escDirs = map[string][]os.FileInfo{
 
 	"pkg/uploader/assets/resources": {
 		_escData["/_layout.html"],
+		_escData["/_preview.html"],
 		_escData["/access.html"],
 		_escData["/assets"],
+		_escData["/audit.html"],
+		_escData["/certificates.html"],
+		_escData["/changes.html"],
 		_escData["/database.html"],
+		_escData["/hl7.html"],
+		_escData["/login.html"],
+		_escData["/openapi.json"],
 		_escData["/orders-consult.html"],
 		_escData["/orders-lab.html"],
 		_escData["/orders-radiology.html"],
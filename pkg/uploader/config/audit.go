@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+)
+
+// auditLog is the file in the configuration folder to which changes of the configuration are appended.
+const auditLog = "door2doc.audit.jsonl"
+
+// Audit actions distinguish changes of the draft configuration from changes that have been applied.
+const (
+	AuditDraft   = "draft"
+	AuditApplied = "applied"
+)
+
+// Actor identifies who changes the configuration: the user, or API token, and the address of the request.
+type Actor struct {
+	User   string
+	Source string
+}
+
+// AuditEntry records the change of a single field of the configuration. Secrets are redacted, as in Diff.
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Source  string    `json:"source"`
+	Action  string    `json:"action"`
+	Version int       `json:"version,omitempty"`
+	Field   string    `json:"field"`
+	Old     string    `json:"old"`
+	New     string    `json:"new"`
+}
+
+// AuditEntries returns an entry for each change, made by user from source.
+func AuditEntries(changes []Change, now time.Time, user, source, action string, version int) []AuditEntry {
+	res := make([]AuditEntry, len(changes))
+	for i, c := range changes {
+		res[i] = AuditEntry{
+			Time:    now,
+			User:    user,
+			Source:  source,
+			Action:  action,
+			Version: version,
+			Field:   c.Field,
+			Old:     c.Old,
+			New:     c.New,
+		}
+	}
+	return res
+}
+
+// AuditLog is an append-only log of changes to the configuration, stored as JSON lines.
+type AuditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewAuditLog returns the audit log stored at path.
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Append adds entries to the end of the log.
+func (l *AuditLog) Append(entries ...AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Entries returns all entries of the log, oldest first. Lines that cannot be read, such as a line that was only
+// partially written, are skipped.
+func (l *AuditLog) Entries() ([]AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var res []AuditEntry
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(s.Bytes(), &e); err == nil {
+			res = append(res, e)
+		}
+	}
+	return res, s.Err()
+}
+
+// AuditLog returns the log to which changes of the configuration are recorded.
+func (c *Configuration) AuditLog() *AuditLog {
+	return c.audit
+}
+
+// record appends an entry for each change, made by by, to the audit log. The caller must hold the write lock, such
+// that the entries are in the order in which the changes were made.
+func (c *Configuration) record(by Actor, action string, version int, changes []Change) {
+	entries := AuditEntries(changes, time.Now(), by.User, by.Source, action, version)
+	if err := c.audit.Append(entries...); err != nil {
+		dlog.Error("Failed to write audit log: %v", err)
+	}
+}
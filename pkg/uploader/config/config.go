@@ -9,19 +9,23 @@ import (
 	"github.com/door2doc/d2d-uploader/pkg/uploader/password"
 	"html/template"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dropfolder"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/fhir"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
 	"github.com/shibukawa/configdir"
 )
 
 var (
-	Server     = "https://integration.door2doc.net/"
 	configDirs = configdir.New("door2doc", "Upload Service")
 )
 
@@ -33,11 +37,17 @@ const (
 	PathConsultUpload    = "/services/v3/upload/orders/consult"
 	DBValidationTimeout  = 5 * time.Second
 
-	config = "door2doc.json"
+	// ClientCertificateExpiryWarning determines how long before expiry a client certificate should be renewed.
+	ClientCertificateExpiryWarning = 30 * 24 * time.Hour
+
+	config   = "door2doc.json"
+	versions = "door2doc.versions.json"
 )
 
 type QueryResult interface {
 	AsTable() template.HTML
+	TablePage(page, size int) template.HTML
+	Len() int
 }
 
 // ValidationResult contains the results of validating the current configuration.
@@ -45,6 +55,12 @@ type ValidationResult struct {
 	DatabaseConnection error
 	QueryTimeout       error
 
+	// VisitorSource indicates that visits cannot be received from the configured source.
+	VisitorSource error
+
+	// DropFolder indicates that CSV files cannot be read from the drop folder.
+	DropFolder error
+
 	VisitorQuery         error
 	VisitorQueryDuration time.Duration
 	VisitorQueryResults  QueryResult
@@ -64,6 +80,9 @@ type ValidationResult struct {
 	D2DConnection  error
 	D2DCredentials error
 
+	// ClientCertificate warns if the client certificate expires soon.
+	ClientCertificate error
+
 	Access error
 }
 
@@ -71,31 +90,95 @@ type ValidationResult struct {
 func (v *ValidationResult) IsValid() bool {
 	return v.DatabaseConnection == nil &&
 		v.QueryTimeout == nil &&
+		v.VisitorSource == nil &&
+		v.DropFolder == nil &&
 		v.VisitorQuery == nil &&
 		v.D2DConnection == nil &&
 		v.D2DCredentials == nil
 }
 
-// Configuration contains the configuration options for the service.
+// Errors returns all validation errors that prevent a configuration from being applied. Order queries that have not
+// been configured are not considered an error.
+func (v *ValidationResult) Errors() []error {
+	var res []error
+	for _, err := range []error{
+		v.DatabaseConnection,
+		v.QueryTimeout,
+		v.VisitorSource,
+		v.DropFolder,
+		v.VisitorQuery,
+		v.RadiologieQuery,
+		v.LabQuery,
+		v.ConsultQuery,
+		v.D2DConnection,
+		v.D2DCredentials,
+	} {
+		if err != nil && err != ErrQueryNotConfigured {
+			res = append(res, err)
+		}
+	}
+	if v.VisitorQuery == ErrQueryNotConfigured {
+		res = append(res, v.VisitorQuery)
+	}
+	return res
+}
+
+// Configuration contains the configuration options for the service. Changes are made to a draft configuration,
+// which only becomes active after it has been validated and applied.
 type Configuration struct {
 	mu sync.RWMutex
 
 	// Set to true if the service should be active
 	active bool
 
+	// the active configuration
 	data DataV2
 
+	// pending changes, nil if there are none
+	draft *DataV2
+
+	// all applied configurations, oldest first
+	versions []Version
+
 	// results of the last call to UpdateValidation
 	validationResult *ValidationResult
+
+	// validation errors that prevented the draft from being applied
+	applyErrors []error
+
+	// client sends all requests to the door2doc server
+	client *rest.Client
+
+	// paused datasets, and datasets waiting to run on request
+	paused map[string]Pause
+	queued map[string]bool
+	runs   chan string
+
+	// users can log in to the web interface, tokens authenticate requests to the management API
+	users  []User
+	tokens []APIToken
+
+	// audit records all changes of the configuration
+	audit *AuditLog
 }
 
 func NewConfiguration() *Configuration {
-	return &Configuration{
+	return NewConfigurationWithClient(rest.NewClient())
+}
+
+// NewConfigurationWithClient returns a configuration that sends all requests to the door2doc server using client.
+func NewConfigurationWithClient(client *rest.Client) *Configuration {
+	c := &Configuration{
+		client: client,
 		active: true,
+		runs:   make(chan string, len(Datasets)),
+		audit:  NewAuditLog(FilePath(auditLog)),
 		data: DataV2{
 			Timeout: 5 * time.Second,
 		},
 	}
+	c.updateLog()
+	return c
 }
 
 // Credentials returns the door2doc credentials stored in the configuration.
@@ -106,25 +189,210 @@ func (c *Configuration) Credentials() (string, string) {
 	return c.data.Username, c.data.Password.PlainText()
 }
 
-func (c *Configuration) SetCredentials(username, pwd string) {
+func (c *Configuration) SetCredentials(by Actor, username, pwd string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.edit(by, func(d *DataV2) {
+		d.Username = username
+		d.Password = password.Password(pwd)
+	})
+}
+
+// AuthMethod returns how requests to the door2doc server are authenticated, either AuthBasic or AuthOAuth2.
+func (c *Configuration) AuthMethod() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.data.AuthMethod == "" {
+		return AuthBasic
+	}
+	return c.data.AuthMethod
+}
+
+// SetClientCredentials sets the authentication method, and the settings used for the OAuth2 client credentials grant.
+func (c *Configuration) SetClientCredentials(by Actor, method string, cc rest.ClientCredentials) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if method == AuthBasic {
+		method = ""
+	}
+	c.edit(by, func(d *DataV2) {
+		d.AuthMethod = method
+		d.TokenURL = cc.TokenURL
+		d.ClientID = cc.ClientID
+		d.ClientSecret = password.Password(cc.ClientSecret)
+		d.TokenScope = cc.Scope
+	})
+}
+
+// Server returns the base URL of the door2doc server.
+func (c *Configuration) Server() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return serverOrDefault(c.data.Server)
+}
+
+func (c *Configuration) SetServer(by Actor, server string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.edit(by, func(d *DataV2) { d.Server = server })
+}
+
+// Endpoint returns the URL of a service path on the door2doc server.
+func (c *Configuration) Endpoint(path string) (string, error) {
+	return Endpoint(c.Server(), path)
+}
+
+// Proxy returns the proxy settings used for all outgoing requests.
+func (c *Configuration) Proxy() rest.Proxy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.ProxySettings()
+}
+
+func (c *Configuration) SetProxy(by Actor, proxy rest.Proxy) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.data.Username = username
-	c.data.Password = password.Password(pwd)
-	dlog.SetUsername(username)
+	c.edit(by, func(d *DataV2) {
+		d.Proxy = proxy.URL
+		d.ProxyUsername = proxy.Username
+		d.ProxyPassword = password.Password(proxy.Password)
+		d.NoProxy = proxy.NoProxy
+		d.ProxyPAC = proxy.PAC
+	})
+}
+
+// TLS returns the settings used for verifying the door2doc server.
+func (c *Configuration) TLS() rest.TLS {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.TLSSettings()
 }
 
-func (c *Configuration) Proxy() string {
+// Options returns the settings used for connecting to the door2doc server.
+func (c *Configuration) Options() rest.Options {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.Options()
+}
+
+// SetServerCA stores a PEM encoded bundle of certificates in the configuration folder, and trusts these in addition
+// to the system roots when connecting to the door2doc server.
+func (c *Configuration) SetServerCA(by Actor, bs []byte) error {
+	if _, err := rest.ParseCertificates(bs); err != nil {
+		return err
+	}
+	name, err := storeFile("ca", ".pem", bs)
+	if err != nil {
+		return err
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return c.data.Proxy
+	c.edit(by, func(d *DataV2) { d.ServerCAFile = name })
+	return nil
 }
 
-func (c *Configuration) SetProxy(proxy string) {
+// ClearServerCA removes the custom certificate bundle, such that only the system roots are trusted.
+func (c *Configuration) ClearServerCA(by Actor) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.data.Proxy = proxy
+	c.edit(by, func(d *DataV2) { d.ServerCAFile = "" })
+}
+
+// SetServerPins sets the public key pins of the door2doc server. An empty list disables pinning.
+func (c *Configuration) SetServerPins(by Actor, pins []string) error {
+	var res []string
+	for _, p := range pins {
+		pin, err := rest.ParsePin(p)
+		if err != nil {
+			return err
+		}
+		res = append(res, pin)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.edit(by, func(d *DataV2) { d.ServerPins = res })
+	return nil
+}
+
+// GenerateClientCSR generates a new private key for client certificate authentication, and a certificate signing
+// request that can be retrieved from ClientCSRFile. The certificate issued for it is set using SetClientCertificate.
+func (c *Configuration) GenerateClientCSR(by Actor, commonName, organization string) error {
+	keyPEM, csrPEM, err := rest.GenerateCSR(commonName, organization)
+	if err != nil {
+		return err
+	}
+	keyName, err := storePrivateFile("client-key", ".pem", keyPEM)
+	if err != nil {
+		return err
+	}
+	csrName, err := storeFile("client-csr", ".pem", csrPEM)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.edit(by, func(d *DataV2) {
+		d.ClientKeyFile = keyName
+		d.ClientCSRFile = csrName
+		d.ClientCertFile = ""
+	})
+	return nil
+}
+
+// SetClientCertificate sets the certificate used for client certificate authentication. The data is either PEM
+// encoded, optionally including the private key, or a PKCS#12 file protected by password. If it does not contain a
+// private key, the certificate must have been issued for the most recently generated request.
+func (c *Configuration) SetClientCertificate(by Actor, data []byte, password string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var keyPEM []byte
+	if name := c.pending().ClientKeyFile; name != "" {
+		var err error
+		if keyPEM, err = readFile(name); err != nil {
+			return err
+		}
+	}
+
+	certPEM, keyPEM, err := rest.ParseClientCertificate(data, password, keyPEM)
+	if err != nil {
+		return err
+	}
+	certName, err := storeFile("client-cert", ".pem", certPEM)
+	if err != nil {
+		return err
+	}
+	keyName, err := storePrivateFile("client-key", ".pem", keyPEM)
+	if err != nil {
+		return err
+	}
+
+	c.edit(by, func(d *DataV2) {
+		d.ClientCertFile = certName
+		d.ClientKeyFile = keyName
+		d.ClientCSRFile = ""
+	})
+	return nil
+}
+
+// ClearClientCertificate disables client certificate authentication.
+func (c *Configuration) ClearClientCertificate(by Actor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.edit(by, func(d *DataV2) {
+		d.ClientCertFile = ""
+		d.ClientKeyFile = ""
+		d.ClientCSRFile = ""
+	})
 }
 
 // Connection returns the connection data stored in the configuration.
@@ -135,11 +403,11 @@ func (c *Configuration) Connection() db.ConnectionData {
 	return c.data.Connection
 }
 
-func (c *Configuration) SetConnection(cd db.ConnectionData) {
+func (c *Configuration) SetConnection(by Actor, cd db.ConnectionData) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.data.Connection = cd
+	c.edit(by, func(d *DataV2) { d.Connection = cd })
 }
 
 // Timeout returns the timeout used for all queries
@@ -149,11 +417,93 @@ func (c *Configuration) Timeout() time.Duration {
 	return c.data.Timeout
 }
 
-func (c *Configuration) SetTimeout(timeout time.Duration) {
+func (c *Configuration) SetTimeout(by Actor, timeout time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.data.Timeout = timeout
+	c.edit(by, func(d *DataV2) { d.Timeout = timeout })
+}
+
+// StreamUploads returns true if visitor records are uploaded as newline-delimited JSON while they are read from the
+// database.
+func (c *Configuration) StreamUploads() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.StreamUploads
+}
+
+func (c *Configuration) SetStreamUploads(by Actor, stream bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.edit(by, func(d *DataV2) { d.StreamUploads = stream })
+}
+
+// FHIRServer returns the base URL of the FHIR server that uploads are also exported to, if any.
+func (c *Configuration) FHIRServer() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.FHIRServer
+}
+
+func (c *Configuration) SetFHIRServer(by Actor, server string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.edit(by, func(d *DataV2) { d.FHIRServer = server })
+}
+
+// VisitorSource returns the source of visitor records, such as SourceDatabase or SourceHL7.
+func (c *Configuration) VisitorSource() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.Source()
+}
+
+func (c *Configuration) SetVisitorSource(by Actor, source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if source == SourceDatabase {
+		source = ""
+	}
+	c.edit(by, func(d *DataV2) { d.VisitorSource = source })
+}
+
+// HL7Address returns the address on which HL7 messages are received, such as :2575.
+func (c *Configuration) HL7Address() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.HL7Address
+}
+
+func (c *Configuration) SetHL7Address(by Actor, address string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.edit(by, func(d *DataV2) { d.HL7Address = address })
+}
+
+// FHIRSource returns the settings of the FHIR server that visits are read from.
+func (c *Configuration) FHIRSource() fhir.SourceSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.FHIRSource
+}
+
+func (c *Configuration) SetFHIRSource(by Actor, s fhir.SourceSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.edit(by, func(d *DataV2) { d.FHIRSource = s })
+}
+
+// DropFolder returns the settings of the folder that CSV files are read from.
+func (c *Configuration) DropFolder() dropfolder.Settings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.DropFolder
+}
+
+func (c *Configuration) SetDropFolder(by Actor, s dropfolder.Settings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.edit(by, func(d *DataV2) { d.DropFolder = s })
 }
 
 // VisitorQuery returns the visitor query stored in the configuration.
@@ -163,11 +513,11 @@ func (c *Configuration) VisitorQuery() string {
 	return c.data.VisitorQuery
 }
 
-func (c *Configuration) SetVisitorQuery(query string) {
+func (c *Configuration) SetVisitorQuery(by Actor, query string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.data.VisitorQuery = query
+	c.edit(by, func(d *DataV2) { d.VisitorQuery = query })
 }
 
 // RadiologieQuery returns the radiologie query stored in the configuration.
@@ -177,11 +527,11 @@ func (c *Configuration) RadiologieQuery() string {
 	return c.data.RadiologieQuery
 }
 
-func (c *Configuration) SetRadiologieQuery(query string) {
+func (c *Configuration) SetRadiologieQuery(by Actor, query string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.data.RadiologieQuery = query
+	c.edit(by, func(d *DataV2) { d.RadiologieQuery = query })
 }
 
 // LabQuery returns the lab query stored in the configuration.
@@ -191,11 +541,11 @@ func (c *Configuration) LabQuery() string {
 	return c.data.LabQuery
 }
 
-func (c *Configuration) SetLabQuery(query string) {
+func (c *Configuration) SetLabQuery(by Actor, query string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.data.LabQuery = query
+	c.edit(by, func(d *DataV2) { d.LabQuery = query })
 }
 
 // ConsultQuery returns the consult query stored in the configuration.
@@ -205,29 +555,11 @@ func (c *Configuration) ConsultQuery() string {
 	return c.data.ConsultQuery
 }
 
-func (c *Configuration) SetConsultQuery(query string) {
+func (c *Configuration) SetConsultQuery(by Actor, query string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.data.ConsultQuery = query
-}
-
-func (c *Configuration) AccessCredentials() (username, password string) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.data.AccessUsername, c.data.AccessPassword.PlainText()
-}
-
-func (c *Configuration) SetAccessCredentials(username, pwd string) {
-	c.mu.Lock()
-	c.mu.Unlock()
-
-	if username == "" || pwd == "" {
-		username = ""
-		pwd = ""
-	}
-	c.data.AccessUsername = username
-	c.data.AccessPassword = password.Password(pwd)
+	c.edit(by, func(d *DataV2) { d.ConsultQuery = query })
 }
 
 func (c *Configuration) Active() bool {
@@ -244,35 +576,130 @@ func (c *Configuration) Interval() time.Duration {
 	return time.Minute
 }
 
-// UpdateBaseValidation validates the base configuration and returns the results of those checks.
+// UpdateBaseValidation validates the base configuration and returns the results of those checks. If there are
+// pending changes, the draft configuration is validated instead of the active one.
 func (c *Configuration) UpdateBaseValidation(ctx context.Context) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.validationResult = c.validateBase(ctx, c.pending())
+	if c.draft == nil {
+		c.active = c.validationResult.IsValid()
+	}
+}
+
+func (c *Configuration) validateBase(ctx context.Context, data *DataV2) *ValidationResult {
 	res := &ValidationResult{}
 
 	// check d2d connection
 	connCtx, timeout := context.WithTimeout(ctx, DBValidationTimeout)
 	defer timeout()
 
-	res.D2DConnection, res.D2DCredentials = c.checkConnection(connCtx)
+	res.D2DConnection, res.D2DCredentials = c.checkConnection(connCtx, data)
+	res.ClientCertificate = checkClientCertificate(data, time.Now())
 
-	if c.data.AccessUsername == "" && c.data.AccessPassword == "" {
+	if len(c.users) == 0 {
 		res.Access = ErrAccessNotConfigured
 	}
 
 	// check timeout
-	if c.data.Timeout <= 0 {
+	if data.Timeout <= 0 {
 		res.QueryTimeout = ErrInvalidTimeout
 	}
 
 	// check db connection
-	res.VisitorQueryDuration, res.VisitorQueryResults, res.DatabaseConnection, res.VisitorQuery = c.checkDatabase(ctx, c.data.VisitorQuery, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
-		return db.ExecuteVisitorQuery(ctx, tx, query, c.data.Timeout)
-	})
+	switch data.Source() {
+	case SourceDatabase:
+		res.VisitorQueryDuration, res.VisitorQueryResults, res.DatabaseConnection, res.VisitorQuery = c.checkDatabase(ctx, data.Connection, data.VisitorQuery, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
+			return db.ExecuteVisitorQuery(ctx, tx, query, data.Timeout, 0)
+		})
+	case SourceHL7:
+		res.VisitorSource = checkHL7Address(data.HL7Address)
+		res.DatabaseConnection = c.checkOptionalDatabase(ctx, data.Connection)
+	case SourceFHIR:
+		res.VisitorSource = c.checkFHIRSource(connCtx, data)
+		res.DatabaseConnection = c.checkOptionalDatabase(ctx, data.Connection)
+	case SourceCSV:
+		res.DatabaseConnection = c.checkOptionalDatabase(ctx, data.Connection)
+	default:
+		res.VisitorSource = ErrUnknownVisitorSource
+	}
+
+	// check drop folder, which may also provide orders
+	if data.Source() == SourceCSV || data.DropFolder.Folder != "" {
+		res.DropFolder = checkDropFolder(data.DropFolder)
+	}
 
-	c.validationResult = res
-	c.active = c.validationResult.IsValid()
+	return res
+}
+
+// checkDropFolder checks whether CSV files can be read from the drop folder and moved aside.
+func checkDropFolder(s dropfolder.Settings) error {
+	if s.Folder == "" {
+		return ErrDropFolderNotConfigured
+	}
+	if err := s.Check(); err != nil {
+		return &DropFolderError{Cause: err}
+	}
+	return nil
+}
+
+// checkOptionalDatabase checks the database connection if one has been configured. It is used when visits do not come
+// from the database, such that the database is only needed for order queries.
+func (c *Configuration) checkOptionalDatabase(ctx context.Context, connection db.ConnectionData) error {
+	if !connection.IsValid() {
+		return nil
+	}
+	_, _, err, _ := c.checkDatabase(ctx, connection, "", nil)
+	return err
+}
+
+// checkFHIRSource checks whether the FHIR server can be searched for encounters, using the configured credentials.
+func (c *Configuration) checkFHIRSource(ctx context.Context, data *DataV2) error {
+	s := data.FHIRSource
+	if s.URL == "" {
+		return ErrFHIRSourceNotConfigured
+	}
+	if u, err := url.Parse(s.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidFHIRSource
+	}
+	switch s.Auth {
+	case fhir.AuthBasic:
+		if s.Username == "" || s.Password == "" {
+			return ErrFHIRCredentialsMissing
+		}
+	case fhir.AuthBearer:
+		if s.Token == "" {
+			return ErrFHIRCredentialsMissing
+		}
+	}
+
+	opts := rest.Options{Proxy: data.ProxySettings(), Auth: s.Authenticator()}
+	err := fhir.CheckSearch(ctx, c.client, opts, s.URL)
+	var outcomeErr *fhir.OutcomeError
+	if err != nil && !errors.As(err, &outcomeErr) {
+		dlog.Error("Failed to search FHIR server %s: %v", s.URL, err)
+		return &FHIRSourceError{Cause: err.Error()}
+	}
+	return err
+}
+
+// checkHL7Address returns ErrInvalidHL7Address if address is not a valid TCP listen address.
+func checkHL7Address(address string) error {
+	if !validListenAddress(address) {
+		return ErrInvalidHL7Address
+	}
+	return nil
+}
+
+// validListenAddress returns true if address is a valid TCP listen address, such as :2575 or localhost:2575.
+func validListenAddress(address string) bool {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	p, err := strconv.Atoi(port)
+	return err == nil && p > 0 && p <= 65535
 }
 
 // UpdateRadiologieValidation validates the order configuration and returns the results of those checks.
@@ -283,11 +710,13 @@ func (c *Configuration) UpdateRadiologieValidation(ctx context.Context) {
 	if c.validationResult == nil {
 		c.validationResult = new(ValidationResult)
 	}
-	res := c.validationResult
+	c.validateRadiologie(ctx, c.pending(), c.validationResult)
+}
 
+func (c *Configuration) validateRadiologie(ctx context.Context, data *DataV2, res *ValidationResult) {
 	// check db connection
-	res.RadiologieQueryDuration, res.RadiologieQueryResults, res.DatabaseConnection, res.RadiologieQuery = c.checkDatabase(ctx, c.data.RadiologieQuery, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
-		return db.ExecuteRadiologieQuery(ctx, tx, query, c.data.Timeout)
+	res.RadiologieQueryDuration, res.RadiologieQueryResults, res.DatabaseConnection, res.RadiologieQuery = c.checkDatabase(ctx, data.Connection, data.RadiologieQuery, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
+		return db.ExecuteRadiologieQuery(ctx, tx, query, data.Timeout, 0)
 	})
 }
 
@@ -299,11 +728,13 @@ func (c *Configuration) UpdateLabValidation(ctx context.Context) {
 	if c.validationResult == nil {
 		c.validationResult = new(ValidationResult)
 	}
-	res := c.validationResult
+	c.validateLab(ctx, c.pending(), c.validationResult)
+}
 
+func (c *Configuration) validateLab(ctx context.Context, data *DataV2, res *ValidationResult) {
 	// check db connection
-	res.LabQueryDuration, res.LabQueryResults, res.DatabaseConnection, res.LabQuery = c.checkDatabase(ctx, c.data.LabQuery, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
-		return db.ExecuteLabQuery(ctx, tx, query, c.data.Timeout)
+	res.LabQueryDuration, res.LabQueryResults, res.DatabaseConnection, res.LabQuery = c.checkDatabase(ctx, data.Connection, data.LabQuery, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
+		return db.ExecuteLabQuery(ctx, tx, query, data.Timeout, 0)
 	})
 }
 
@@ -315,11 +746,13 @@ func (c *Configuration) UpdateConsultValidation(ctx context.Context) {
 	if c.validationResult == nil {
 		c.validationResult = new(ValidationResult)
 	}
-	res := c.validationResult
+	c.validateConsult(ctx, c.pending(), c.validationResult)
+}
 
+func (c *Configuration) validateConsult(ctx context.Context, data *DataV2, res *ValidationResult) {
 	// check db connection
-	res.ConsultQueryDuration, res.ConsultQueryResults, res.DatabaseConnection, res.ConsultQuery = c.checkDatabase(ctx, c.data.ConsultQuery, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
-		return db.ExecuteConsultQuery(ctx, tx, query, c.data.Timeout)
+	res.ConsultQueryDuration, res.ConsultQueryResults, res.DatabaseConnection, res.ConsultQuery = c.checkDatabase(ctx, data.Connection, data.ConsultQuery, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
+		return db.ExecuteConsultQuery(ctx, tx, query, data.Timeout, 0)
 	})
 }
 
@@ -346,7 +779,70 @@ func (c *Configuration) Reload() error {
 	if err := json.Unmarshal(bs, &c); err != nil {
 		return err
 	}
-	return nil
+
+	bs, err = folders[0].ReadFile(versions)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.versions = nil
+	if err == nil {
+		if err := json.Unmarshal(bs, &c.versions); err != nil {
+			return fmt.Errorf("while reading configuration versions: %w", err)
+		}
+	}
+	if len(c.versions) == 0 {
+		c.addVersion("Loaded from " + config)
+	}
+	c.paused = nil
+	if err := readState(pauses, &c.paused); err != nil {
+		return err
+	}
+	c.tokens = nil
+	if err := readState(tokens, &c.tokens); err != nil {
+		return err
+	}
+	for i := range c.tokens {
+		// tokens were administrators before they had roles
+		if c.tokens[i].Role == "" {
+			c.tokens[i].Role = RoleAdmin
+		}
+	}
+	return c.migrateUsers()
+}
+
+// migrateUsers moves the users that older versions stored in the configuration to the users file, and removes them
+// from the configuration and its versions, such that their password hashes are no longer kept there. The caller must
+// hold the write lock.
+func (c *Configuration) migrateUsers() error {
+	// UnmarshalJSON has taken the users from the configuration file
+	migrated := c.users
+	found := len(migrated) > 0
+	for i := range c.versions {
+		old, err := c.versions[i].Data.takeUsers()
+		if err != nil {
+			return err
+		}
+		found = found || len(old) > 0
+	}
+
+	c.users = nil
+	if err := readState(users, &c.users); err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	if len(c.users) == 0 && len(migrated) > 0 {
+		if err := writeState(users, migrated); err != nil {
+			return err
+		}
+		c.users = migrated
+	}
+	return c.save()
 }
 
 // Save stores the latest configuration values to a well-known location.
@@ -354,7 +850,15 @@ func (c *Configuration) Save() error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	bs, err := json.MarshalIndent(c, "", "  ")
+	return c.save()
+}
+
+func (c *Configuration) save() error {
+	bs, err := json.MarshalIndent(c.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	vs, err := json.MarshalIndent(c.versions, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -366,6 +870,9 @@ func (c *Configuration) Save() error {
 	if err := folders[0].WriteFile(config, bs); err != nil {
 		return fmt.Errorf("while writing configuration file: %w", err)
 	}
+	if err := folders[0].WriteFile(versions, vs); err != nil {
+		return fmt.Errorf("while writing configuration versions: %w", err)
+	}
 	dlog.Info("Updated %s/%s", folders[0].Path, config)
 	return nil
 }
@@ -419,27 +926,80 @@ func (c *Configuration) UnmarshalJSON(v []byte) error {
 	if c.data.Timeout == 0 {
 		c.data.Timeout = 5 * time.Second
 	}
+	c.updateLog()
+
+	// users are kept separately, see migrateUsers
+	var err error
+	c.users, err = c.data.takeUsers()
+	return err
+}
+
+// updateLog scopes all subsequent logging to the active configuration. The caller must hold the write lock.
+func (c *Configuration) updateLog() {
 	dlog.SetUsername(c.data.Username)
+	dlog.SetServer(serverOrDefault(c.data.Server))
+
+	// error reports are not authenticated
+	client, opts := c.client, c.data.Options()
+	opts.Auth = nil
+	dlog.SetDo(func(req *http.Request) (*http.Response, error) {
+		return client.Do(context.Background(), opts, req)
+	})
+}
 
+// checkClientCertificate returns a ClientCertificateExpiryError if the client certificate expires within
+// ClientCertificateExpiryWarning.
+func checkClientCertificate(data *DataV2, now time.Time) error {
+	if data.ClientCertFile == "" {
+		return nil
+	}
+	certs, err := rest.ReadCertificates(FilePath(data.ClientCertFile))
+	if err != nil {
+		return &rest.TLSConfigError{Cause: err}
+	}
+	if notAfter := certs[0].NotAfter; now.Add(ClientCertificateExpiryWarning).After(notAfter) {
+		return &ClientCertificateExpiryError{NotAfter: notAfter, Expired: now.After(notAfter)}
+	}
 	return nil
 }
 
-func (c *Configuration) checkConnection(ctx context.Context) (connErr error, credErr error) {
-	if c.data.Username == "" || c.data.Password == "" {
-		credErr = ErrD2DCredentialsNotConfigured
+func (c *Configuration) checkConnection(ctx context.Context, data *DataV2) (connErr error, credErr error) {
+	switch data.AuthMethod {
+	case AuthOAuth2:
+		if data.TokenURL == "" || data.ClientID == "" || data.ClientSecret == "" {
+			credErr = ErrD2DCredentialsNotConfigured
+		}
+	default:
+		if data.Username == "" || data.Password == "" {
+			credErr = ErrD2DCredentialsNotConfigured
+		}
 	}
 
-	req, err := http.NewRequest(http.MethodGet, Server, nil)
+	endpoint, err := Endpoint(data.Server, PathPing)
 	if err != nil {
-		dlog.Error("Failed to initialize connection to %s: %v", Server, err)
 		return err, credErr
 	}
-	req.URL.Path = PathPing
-	req.SetBasicAuth(c.data.Username, c.data.Password.PlainText())
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		dlog.Error("Failed to initialize connection to %s: %v", endpoint, err)
+		return err, credErr
+	}
 
-	res, err := rest.Do(ctx, c.data.Proxy, req)
+	opts := data.Options()
+	if credErr != nil {
+		// only check whether the server can be reached
+		opts.Auth = nil
+	}
+	res, err := c.client.Do(ctx, opts, req)
 	if err != nil {
-		dlog.Error("Failed to connect to %s: %v", Server, err)
+		dlog.Error("Failed to connect to %s: %v", endpoint, err)
+		var tokenErr *rest.TokenError
+		if errors.As(err, &tokenErr) {
+			return nil, tokenErr
+		}
+		if rest.IsTLSError(err) {
+			return err, credErr
+		}
 		return ErrD2DConnectionFailed, credErr
 	}
 	_, err = io.Copy(io.Discard, res.Body)
@@ -468,26 +1028,23 @@ func (c *Configuration) checkConnection(ctx context.Context) (connErr error, cre
 
 type checker func(context.Context, *sql.Tx, string) (QueryResult, error)
 
-func (c *Configuration) checkDatabase(ctx context.Context, query string, f checker) (queryDuration time.Duration, queryResult QueryResult, connErr, queryErr error) {
+func (c *Configuration) checkDatabase(ctx context.Context, connection db.ConnectionData, query string, f checker) (queryDuration time.Duration, queryResult QueryResult, connErr, queryErr error) {
 	if query == "" {
 		queryErr = ErrQueryNotConfigured
 	}
 
-	if !c.data.Connection.IsValid() {
+	if !connection.IsValid() {
 		connErr = ErrDatabaseNotConfigured
 		return
 	}
-
-	conn, err := sql.Open(c.data.Connection.Driver, c.data.Connection.DSN())
-	if err != nil {
-		dlog.Error("Failed to connect to database: %v", err)
-		connErr = &DatabaseInvalidError{Cause: err.Error()}
+	if err := connection.Validate(); err != nil {
+		connErr = err
 		return
 	}
 
-	err = conn.PingContext(ctx)
+	conn, _, err := db.Open(ctx, connection)
 	if err != nil {
-		dlog.Error("Failed to ping database %s: %v", c.data.Connection.Host, err)
+		dlog.Error("Failed to connect to database: %v", err)
 		connErr = &DatabaseInvalidError{Cause: err.Error()}
 		return
 	}
@@ -534,10 +1091,12 @@ func (c *Configuration) checkDatabase(ctx context.Context, query string, f check
 	return
 }
 
-func (c *Configuration) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// Client returns the client used for requests to the door2doc server.
+func (c *Configuration) Client() *rest.Client {
+	return c.client
+}
 
-	req.SetBasicAuth(c.data.Username, c.data.Password.PlainText())
-	return rest.Do(ctx, c.data.Proxy, req)
+// Do sends a request to the door2doc server, using the active settings.
+func (c *Configuration) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return c.client.Do(ctx, c.Options(), req)
 }
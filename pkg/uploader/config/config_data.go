@@ -2,7 +2,10 @@ package config
 
 import (
 	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dropfolder"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/fhir"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/password"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
 	"time"
 )
 
@@ -47,17 +50,142 @@ func (v DataV1) ToV2() (DataV2, error) {
 	}, nil
 }
 
+const (
+	// AuthBasic authenticates to the door2doc server using the username and password.
+	AuthBasic = "basic"
+	// AuthOAuth2 authenticates to the door2doc server using access tokens obtained with the OAuth2 client credentials
+	// grant.
+	AuthOAuth2 = "oauth2"
+)
+
+const (
+	// SourceDatabase reads visits using the visitor query.
+	SourceDatabase = "database"
+	// SourceHL7 receives visits as HL7 v2 ADT messages over MLLP.
+	SourceHL7 = "hl7"
+	// SourceFHIR polls a FHIR server for encounters.
+	SourceFHIR = "fhir"
+	// SourceCSV reads visits from CSV files in the drop folder.
+	SourceCSV = "csv"
+)
+
 type DataV2 struct {
 	Version         int
-	Username        string            `json:"username"`
-	Password        password.Password `json:"password"`
-	Proxy           string            `json:"proxy"`
-	Connection      db.ConnectionData `json:"database"`
-	Timeout         time.Duration     `json:"timeout"`
-	VisitorQuery    string            `json:"query"`
-	RadiologieQuery string            `json:"radiologie"`
-	LabQuery        string            `json:"lab"`
-	ConsultQuery    string            `json:"consult"`
-	AccessUsername  string            `json:"access_username"`
-	AccessPassword  password.Password `json:"access_password"`
+	Username        string              `json:"username"`
+	Password        password.Password   `json:"password"`
+	AuthMethod      string              `json:"auth_method,omitempty"`
+	TokenURL        string              `json:"token_url,omitempty"`
+	ClientID        string              `json:"client_id,omitempty"`
+	ClientSecret    password.Password   `json:"client_secret,omitempty"`
+	TokenScope      string              `json:"token_scope,omitempty"`
+	Server          string              `json:"server,omitempty"`
+	Proxy           string              `json:"proxy"`
+	ProxyUsername   string              `json:"proxy_username,omitempty"`
+	ProxyPassword   password.Password   `json:"proxy_password,omitempty"`
+	NoProxy         string              `json:"no_proxy,omitempty"`
+	ProxyPAC        string              `json:"proxy_pac,omitempty"`
+	ServerCAFile    string              `json:"server_ca_file,omitempty"`
+	ServerPins      []string            `json:"server_pins,omitempty"`
+	ClientCertFile  string              `json:"client_cert_file,omitempty"`
+	ClientKeyFile   string              `json:"client_key_file,omitempty"`
+	ClientCSRFile   string              `json:"client_csr_file,omitempty"`
+	Connection      db.ConnectionData   `json:"database"`
+	Timeout         time.Duration       `json:"timeout"`
+	VisitorSource   string              `json:"visitor_source,omitempty"`
+	HL7Address      string              `json:"hl7_address,omitempty"`
+	FHIRSource      fhir.SourceSettings `json:"fhir_source"`
+	DropFolder      dropfolder.Settings `json:"drop_folder"`
+	StreamUploads   bool                `json:"stream_uploads,omitempty"`
+	FHIRServer      string              `json:"fhir_server,omitempty"`
+	VisitorQuery    string              `json:"query"`
+	RadiologieQuery string              `json:"radiologie"`
+	LabQuery        string              `json:"lab"`
+	ConsultQuery    string              `json:"consult"`
+	WebServer       WebServer           `json:"web_server"`
+
+	// Users, and the single user of even older versions in AccessUsername, AccessHash and AccessPassword, are the web
+	// interface users that older versions stored in the configuration. They are moved to the users file when the
+	// configuration is loaded.
+	Users          []User            `json:"users,omitempty"`
+	AccessUsername string            `json:"access_username,omitempty"`
+	AccessHash     password.Hash     `json:"access_password_hash,omitempty"`
+	AccessPassword password.Password `json:"access_password,omitempty"`
+}
+
+// takeUsers removes the web interface users that older versions stored in the configuration, and returns them. The
+// single user of the oldest versions is returned as an administrator.
+func (d *DataV2) takeUsers() ([]User, error) {
+	res := d.Users
+	if len(res) == 0 && d.AccessUsername != "" && (d.AccessHash != "" || d.AccessPassword != "") {
+		hash := d.AccessHash
+		if d.AccessPassword != "" {
+			var err error
+			if hash, err = password.NewHash(d.AccessPassword.PlainText()); err != nil {
+				return nil, err
+			}
+		}
+		res = []User{{Name: d.AccessUsername, Hash: hash, Role: RoleAdmin}}
+	}
+	d.Users = nil
+	d.AccessUsername, d.AccessHash, d.AccessPassword = "", "", ""
+	return res, nil
+}
+
+// Source returns the source of visitor records. It defaults to SourceDatabase.
+func (d DataV2) Source() string {
+	if d.VisitorSource == "" {
+		return SourceDatabase
+	}
+	return d.VisitorSource
+}
+
+// ProxySettings returns the proxy settings used for connecting to the door2doc server.
+func (d DataV2) ProxySettings() rest.Proxy {
+	return rest.Proxy{
+		URL:      d.Proxy,
+		Username: d.ProxyUsername,
+		Password: d.ProxyPassword.PlainText(),
+		NoProxy:  d.NoProxy,
+		PAC:      d.ProxyPAC,
+	}
+}
+
+// TLSSettings returns the settings used for verifying the door2doc server.
+func (d DataV2) TLSSettings() rest.TLS {
+	res := rest.TLS{
+		CAFile: FilePath(d.ServerCAFile),
+		Pins:   d.ServerPins,
+	}
+	if d.ClientCertFile != "" {
+		res.ClientCert = FilePath(d.ClientCertFile)
+		res.ClientKey = FilePath(d.ClientKeyFile)
+	}
+	return res
+}
+
+// Authenticator returns the authenticator for requests to the door2doc server.
+func (d DataV2) Authenticator() rest.Authenticator {
+	if d.AuthMethod == AuthOAuth2 {
+		return d.ClientCredentials()
+	}
+	return rest.BasicAuth{Username: d.Username, Password: d.Password.PlainText()}
+}
+
+// ClientCredentials returns the settings for the OAuth2 client credentials grant.
+func (d DataV2) ClientCredentials() rest.ClientCredentials {
+	return rest.ClientCredentials{
+		TokenURL:     d.TokenURL,
+		ClientID:     d.ClientID,
+		ClientSecret: d.ClientSecret.PlainText(),
+		Scope:        d.TokenScope,
+	}
+}
+
+// Options returns the settings used for connecting to the door2doc server.
+func (d DataV2) Options() rest.Options {
+	return rest.Options{
+		Proxy: d.ProxySettings(),
+		TLS:   d.TLSSettings(),
+		Auth:  d.Authenticator(),
+	}
 }
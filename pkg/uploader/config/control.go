@@ -0,0 +1,141 @@
+package config
+
+import "time"
+
+// Datasets are uploaded to separate endpoints, and can be paused and run separately.
+const (
+	DatasetVisits     = "bezoeken"
+	DatasetRadiologie = "radiologie"
+	DatasetLab        = "lab"
+	DatasetConsult    = "consult"
+)
+
+// Datasets lists all datasets in the order in which they are uploaded.
+var Datasets = []string{DatasetVisits, DatasetRadiologie, DatasetLab, DatasetConsult}
+
+var datasetPaths = map[string]string{
+	DatasetVisits:     PathVisitorUpload,
+	DatasetRadiologie: PathRadiologieUpload,
+	DatasetLab:        PathLabUpload,
+	DatasetConsult:    PathConsultUpload,
+}
+
+// DatasetPath returns the upload path of a dataset, or an empty string if the dataset is unknown.
+func DatasetPath(dataset string) string {
+	return datasetPaths[dataset]
+}
+
+// PathDataset returns the dataset that is uploaded to path, or an empty string if there is none.
+func PathDataset(path string) string {
+	for dataset, p := range datasetPaths {
+		if p == path {
+			return dataset
+		}
+	}
+	return ""
+}
+
+// pauses is the file in which paused datasets are stored. They are not part of the configuration, such that applying
+// or rolling back changes does not resume uploads.
+const pauses = "door2doc.pauses.json"
+
+// Pause records why an operator has paused the uploads of a dataset.
+type Pause struct {
+	Since  time.Time `json:"since"`
+	By     string    `json:"by"`
+	Reason string    `json:"reason"`
+}
+
+// Paused returns whether the uploads of a dataset have been paused, and why.
+func (c *Configuration) Paused(dataset string) (Pause, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	p, ok := c.paused[dataset]
+	return p, ok
+}
+
+// Pause stops the uploads of a dataset until it is resumed, also after the service restarts.
+func (c *Configuration) Pause(dataset, by, reason string) error {
+	if DatasetPath(dataset) == "" {
+		return ErrUnknownDataset
+	}
+	if reason == "" {
+		return ErrPauseReasonMissing
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	paused := make(map[string]Pause, len(c.paused)+1)
+	for k, v := range c.paused {
+		paused[k] = v
+	}
+	paused[dataset] = Pause{Since: time.Now(), By: by, Reason: reason}
+	if err := writeState(pauses, paused); err != nil {
+		return err
+	}
+	c.paused = paused
+	return nil
+}
+
+// Resume restarts the uploads of a paused dataset.
+func (c *Configuration) Resume(dataset string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.paused[dataset]; !ok {
+		return nil
+	}
+	paused := make(map[string]Pause, len(c.paused))
+	for k, v := range c.paused {
+		if k != dataset {
+			paused[k] = v
+		}
+	}
+	if err := writeState(pauses, paused); err != nil {
+		return err
+	}
+	c.paused = paused
+	return nil
+}
+
+// RunNow requests an upload of a dataset, without waiting for the next interval. Requests for a dataset that is
+// already waiting to run are ignored.
+func (c *Configuration) RunNow(dataset string) error {
+	if DatasetPath(dataset) == "" {
+		return ErrUnknownDataset
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, paused := c.paused[dataset]
+	switch {
+	case !c.active:
+		return ErrNotActive
+	case paused:
+		return ErrDatasetPaused
+	case c.queued[dataset]:
+		return nil
+	}
+	if c.queued == nil {
+		c.queued = make(map[string]bool)
+	}
+	c.queued[dataset] = true
+	c.runs <- dataset
+	return nil
+}
+
+// RunRequests returns the datasets for which an upload has been requested using RunNow.
+func (c *Configuration) RunRequests() <-chan string {
+	return c.runs
+}
+
+// Started marks a requested upload of a dataset as started, such that it can be requested again.
+func (c *Configuration) Started(dataset string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.queued, dataset)
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/password"
+)
+
+// Change describes a single changed configuration field. Secrets are redacted.
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+var (
+	passwordType = reflect.TypeOf(password.Password(""))
+	hashType     = reflect.TypeOf(password.Hash(""))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// Diff returns all fields that differ between two configurations.
+func Diff(old, new DataV2) []Change {
+	var res []Change
+	diff(&res, "", reflect.ValueOf(old), reflect.ValueOf(new))
+	return res
+}
+
+func diff(res *[]Change, prefix string, old, new reflect.Value) {
+	t := old.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Name == "Version" {
+			continue
+		}
+
+		name := prefix + f.Name
+		o, n := old.Field(i), new.Field(i)
+		switch {
+		case f.Type.Kind() == reflect.Struct && f.Type != timeType:
+			diff(res, name+".", o, n)
+		case reflect.DeepEqual(o.Interface(), n.Interface()):
+			// unchanged
+		case f.Type == passwordType || f.Type == hashType:
+			*res = append(*res, Change{Field: name, Old: redact(o.String()), New: redact(n.String())})
+		default:
+			*res = append(*res, Change{Field: name, Old: fmt.Sprint(o.Interface()), New: fmt.Sprint(n.Interface())})
+		}
+	}
+}
+
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "[redacted]"
+}
+
+// Masked returns the configuration in the format of the configuration file, with the secrets that have been set
+// replaced by mask, such that it can be shown to users that may not see secrets. Unlike the configuration file, it
+// cannot be read back.
+func (d DataV2) Masked(mask string) map[string]interface{} {
+	return masked(reflect.ValueOf(d), mask)
+}
+
+func masked(v reflect.Value, mask string) map[string]interface{} {
+	res := make(map[string]interface{})
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fv := v.Field(i)
+		switch {
+		case opts == "omitempty" && f.Type.Kind() != reflect.Struct && fv.IsZero():
+			// omitted, as in the configuration file
+		case f.Type == passwordType || f.Type == hashType:
+			res[name] = ""
+			if fv.String() != "" {
+				res[name] = mask
+			}
+		case f.Type.Kind() == reflect.Struct && f.Type != timeType:
+			res[name] = masked(fv, mask)
+		case f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.Struct:
+			items := make([]map[string]interface{}, fv.Len())
+			for j := range items {
+				items[j] = masked(fv.Index(j), mask)
+			}
+			res[name] = items
+		default:
+			res[name] = fv.Interface()
+		}
+	}
+	return res
+}
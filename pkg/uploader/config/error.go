@@ -3,16 +3,39 @@ package config
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 var (
 	ErrDatabaseNotConfigured       = errors.New("database connection not configured")
 	ErrQueryNotConfigured          = errors.New("query not configured")
 	ErrD2DConnectionFailed         = errors.New("connection failed")
+	ErrInvalidServer               = errors.New("invalid server URL")
 	ErrD2DCredentialsNotConfigured = errors.New("credentials not configured")
 	ErrD2DCredentialsInvalid       = errors.New("credentials invalid")
 	ErrAccessNotConfigured         = errors.New("access credentials have not been configured")
 	ErrInvalidTimeout              = errors.New("invalid query timeout")
+	ErrDraftInvalid                = errors.New("draft configuration is invalid")
+	ErrUnknownVersion              = errors.New("unknown configuration version")
+	ErrUnknownVisitorSource        = errors.New("unknown visitor source")
+	ErrInvalidHL7Address           = errors.New("invalid HL7 listen address")
+	ErrFHIRSourceNotConfigured     = errors.New("FHIR server not configured")
+	ErrInvalidFHIRSource           = errors.New("invalid FHIR server URL")
+	ErrFHIRCredentialsMissing      = errors.New("FHIR server credentials not configured")
+	ErrDropFolderNotConfigured     = errors.New("drop folder not configured")
+	ErrUnknownDataset              = errors.New("unknown dataset")
+	ErrNotActive                   = errors.New("configuration is not active")
+	ErrDatasetPaused               = errors.New("dataset is paused")
+	ErrPauseReasonMissing          = errors.New("reason for pausing is missing")
+	ErrTokenNameMissing            = errors.New("API token name is missing")
+	ErrTokenExists                 = errors.New("API token already exists")
+	ErrWebKeyMissing               = errors.New("web server certificate does not contain a private key")
+	ErrInvalidWebAddress           = errors.New("invalid web server listen address")
+	ErrUserNameMissing             = errors.New("user name is missing")
+	ErrUserPasswordMissing         = errors.New("password of new user is missing")
+	ErrUnknownRole                 = errors.New("unknown role")
+	ErrUnknownUser                 = errors.New("unknown user")
+	ErrLastAdmin                   = errors.New("at least one administrator is required")
 )
 
 // D2DCredentialsStatusError indicates a general error while connecting to the door2doc cloud.
@@ -41,3 +64,47 @@ type QueryError struct {
 func (e *QueryError) Error() string {
 	return fmt.Sprintf("general query error: %s", e.Cause)
 }
+
+// ClientCertificateExpiryError indicates that the client certificate has expired, or expires soon.
+type ClientCertificateExpiryError struct {
+	NotAfter time.Time
+	Expired  bool
+}
+
+func (e *ClientCertificateExpiryError) Error() string {
+	if e.Expired {
+		return fmt.Sprintf("client certificate expired on %s", e.NotAfter.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("client certificate expires on %s", e.NotAfter.Format(time.RFC3339))
+}
+
+// FHIRSourceError indicates that the FHIR server could not be reached.
+type FHIRSourceError struct {
+	Cause string
+}
+
+func (e *FHIRSourceError) Error() string {
+	return fmt.Sprintf("FHIR server connection failed: %s", e.Cause)
+}
+
+// AllowlistError indicates that an entry of the web server allowlist is neither an IP address nor a network.
+type AllowlistError struct {
+	Entry string
+}
+
+func (e *AllowlistError) Error() string {
+	return fmt.Sprintf("invalid allowlist entry: %s", e.Entry)
+}
+
+// DropFolderError indicates that files cannot be read from the drop folder.
+type DropFolderError struct {
+	Cause error
+}
+
+func (e *DropFolderError) Error() string {
+	return fmt.Sprintf("drop folder unavailable: %v", e.Cause)
+}
+
+func (e *DropFolderError) Unwrap() error {
+	return e.Cause
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shibukawa/configdir"
+)
+
+// storeFile stores data in the configuration folder, under a name derived from its contents. Files are never
+// overwritten, such that previous configuration versions keep referring to the right contents. It returns the name
+// of the file.
+func storeFile(prefix, ext string, data []byte) (string, error) {
+	return writeFile(prefix, ext, data, 0644)
+}
+
+// storePrivateFile stores data like storeFile, but only allows the owner of the file to read it. It is used for
+// private keys.
+func storePrivateFile(prefix, ext string, data []byte) (string, error) {
+	return writeFile(prefix, ext, data, 0600)
+}
+
+func writeFile(prefix, ext string, data []byte, perm os.FileMode) (string, error) {
+	folders := configDirs.QueryFolders(configdir.System)
+	if len(folders) == 0 {
+		return "", errors.New("failed to find configuration folder")
+	}
+
+	sum := sha256.Sum256(data)
+	name := prefix + "-" + hex.EncodeToString(sum[:8]) + ext
+	if folders[0].Exists(name) {
+		return name, nil
+	}
+	if err := writeNamedFile(name, data, perm); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// writeNamedFile stores data in the configuration folder under a fixed name, replacing any previous contents.
+func writeNamedFile(name string, data []byte, perm os.FileMode) error {
+	folders := configDirs.QueryFolders(configdir.System)
+	if len(folders) == 0 {
+		return errors.New("failed to find configuration folder")
+	}
+	if err := folders[0].MkdirAll(); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(folders[0].Path, name), data, perm)
+}
+
+// FilePath returns the full path of a file stored in the configuration folder. Absolute paths are returned unchanged.
+func FilePath(name string) string {
+	if name == "" || filepath.IsAbs(name) {
+		return name
+	}
+	folders := configDirs.QueryFolders(configdir.System)
+	if len(folders) == 0 {
+		return name
+	}
+	return filepath.Join(folders[0].Path, name)
+}
+
+// readFile reads a file stored in the configuration folder.
+func readFile(name string) ([]byte, error) {
+	return os.ReadFile(FilePath(name))
+}
+
+// readState reads operational state, such as paused datasets, from a JSON file in the configuration folder. It leaves
+// v unchanged if the file does not exist.
+func readState(name string, v interface{}) error {
+	folders := configDirs.QueryFolders(configdir.System)
+	if len(folders) == 0 {
+		return nil
+	}
+	bs, err := folders[0].ReadFile(name)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(bs, v); err != nil {
+		return fmt.Errorf("while reading %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeState stores operational state as a JSON file in the configuration folder, replacing any previous contents.
+func writeState(name string, v interface{}) error {
+	bs, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	folders := configDirs.QueryFolders(configdir.System)
+	if len(folders) == 0 {
+		return errors.New("failed to find configuration folder")
+	}
+	if err := folders[0].WriteFile(name, bs); err != nil {
+		return fmt.Errorf("while writing %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
+)
+
+// PreviewRows is the maximum number of rows read when test-running a draft query.
+const PreviewRows = 250
+
+// QueryPreview contains the results of test-running a draft query. Running a preview never changes the active
+// configuration.
+type QueryPreview struct {
+	Query    string
+	Time     time.Time
+	Duration time.Duration
+
+	DatabaseConnection error
+	QueryError         error
+
+	// Results contains the records as read from the database.
+	Results QueryResult
+	// Records contains the JSON representation of each record, as it would be uploaded.
+	Records []json.RawMessage
+	// Truncated is true if the query returned PreviewRows rows, after which reading stopped.
+	Truncated bool
+}
+
+// IsValid returns true if the draft query executed successfully.
+func (p *QueryPreview) IsValid() bool {
+	return p.DatabaseConnection == nil && p.QueryError == nil
+}
+
+// Len returns the number of rows returned by the draft query.
+func (p *QueryPreview) Len() int {
+	return len(p.Records)
+}
+
+// JSONPage returns the given zero-based page of converted records as indented JSON.
+func (p *QueryPreview) JSONPage(page, size int) string {
+	from, to := db.PageBounds(len(p.Records), page, size)
+	bs, err := json.MarshalIndent(p.Records[from:to], "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return string(bs)
+}
+
+type converter func(QueryResult) (interface{}, error)
+
+// PreviewVisitorQuery runs a draft visitor query and converts the results, without activating the query.
+func (c *Configuration) PreviewVisitorQuery(ctx context.Context, query string, loc *time.Location) *QueryPreview {
+	return c.preview(ctx, query, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
+		return db.ExecuteVisitorQuery(ctx, tx, query, c.pending().Timeout, PreviewRows)
+	}, func(res QueryResult) (interface{}, error) {
+		return rest.VisitorRecordsFromDB(res.(db.VisitorRecords), loc)
+	})
+}
+
+// PreviewRadiologieQuery runs a draft radiologie query and converts the results, without activating the query.
+func (c *Configuration) PreviewRadiologieQuery(ctx context.Context, query string, loc *time.Location) *QueryPreview {
+	return c.preview(ctx, query, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
+		return db.ExecuteRadiologieQuery(ctx, tx, query, c.pending().Timeout, PreviewRows)
+	}, func(res QueryResult) (interface{}, error) {
+		return rest.RadiologieRecordsFromDB(res.(db.RadiologieOrders), loc)
+	})
+}
+
+// PreviewLabQuery runs a draft lab query and converts the results, without activating the query.
+func (c *Configuration) PreviewLabQuery(ctx context.Context, query string, loc *time.Location) *QueryPreview {
+	return c.preview(ctx, query, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
+		return db.ExecuteLabQuery(ctx, tx, query, c.pending().Timeout, PreviewRows)
+	}, func(res QueryResult) (interface{}, error) {
+		return rest.LabRecordsFromDB(res.(db.LabOrders), loc)
+	})
+}
+
+// PreviewConsultQuery runs a draft consult query and converts the results, without activating the query.
+func (c *Configuration) PreviewConsultQuery(ctx context.Context, query string, loc *time.Location) *QueryPreview {
+	return c.preview(ctx, query, func(ctx context.Context, tx *sql.Tx, query string) (QueryResult, error) {
+		return db.ExecuteConsultQuery(ctx, tx, query, c.pending().Timeout, PreviewRows)
+	}, func(res QueryResult) (interface{}, error) {
+		return rest.ConsultRecordsFromDB(res.(db.ConsultOrders), loc)
+	})
+}
+
+func (c *Configuration) preview(ctx context.Context, query string, f checker, conv converter) *QueryPreview {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	p := &QueryPreview{
+		Query: query,
+		Time:  time.Now(),
+	}
+	p.Duration, p.Results, p.DatabaseConnection, p.QueryError = c.checkDatabase(ctx, c.pending().Connection, p.Query, f)
+	if !p.IsValid() || p.Results == nil {
+		return p
+	}
+	p.Truncated = p.Results.Len() >= PreviewRows
+
+	converted, err := conv(p.Results)
+	if err != nil {
+		p.QueryError = &QueryError{Cause: err.Error()}
+		return p
+	}
+
+	// marshal each record separately, so that the results can be paginated
+	bs, err := json.Marshal(converted)
+	if err != nil {
+		p.QueryError = &QueryError{Cause: err.Error()}
+		return p
+	}
+	if err := json.Unmarshal(bs, &p.Records); err != nil {
+		p.QueryError = &QueryError{Cause: err.Error()}
+		return p
+	}
+	return p
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	ServerProduction = "https://integration.door2doc.net/"
+
+	// EnvironmentCustom is used for all other servers, such as the acceptance server provided by door2doc.
+	EnvironmentCustom = "custom"
+)
+
+// Environment is a door2doc server that can be selected on the upload page.
+type Environment struct {
+	Name   string
+	Label  string
+	Server string
+}
+
+// Environments lists the door2doc environments, the first one being the default.
+var Environments = []Environment{
+	{Name: "production", Label: "Production", Server: ServerProduction},
+}
+
+// EnvironmentOf returns the name of the environment of a server, or EnvironmentCustom.
+func EnvironmentOf(server string) string {
+	server = serverOrDefault(server)
+	for _, e := range Environments {
+		if strings.TrimSuffix(e.Server, "/") == strings.TrimSuffix(server, "/") {
+			return e.Name
+		}
+	}
+	return EnvironmentCustom
+}
+
+// ServerOf returns the server of an environment, or custom if the environment is not known.
+func ServerOf(environment, custom string) string {
+	for _, e := range Environments {
+		if e.Name == environment {
+			return e.Server
+		}
+	}
+	return strings.TrimSpace(custom)
+}
+
+func serverOrDefault(server string) string {
+	if server == "" {
+		return ServerProduction
+	}
+	return server
+}
+
+// Endpoint returns the URL of a service path on the server, which may contain a path prefix itself.
+func Endpoint(server, path string) (string, error) {
+	u, err := url.Parse(serverOrDefault(server))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", ErrInvalidServer
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String(), nil
+}
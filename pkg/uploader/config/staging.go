@@ -0,0 +1,319 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+)
+
+// Version is a configuration that has been applied at some point in time.
+type Version struct {
+	Number  int       `json:"number"`
+	Applied time.Time `json:"applied"`
+	Comment string    `json:"comment,omitempty"`
+	Data    DataV2    `json:"data"`
+}
+
+// pending returns the configuration that is being edited: the draft if there is one, or the active configuration
+// otherwise. The caller must hold the lock.
+func (c *Configuration) pending() *DataV2 {
+	if c.draft != nil {
+		return c.draft
+	}
+	return &c.data
+}
+
+// edit changes the draft configuration using set, creating the draft from the active configuration if required, and
+// records the changes made by by in the audit log. The caller must hold the write lock.
+func (c *Configuration) edit(by Actor, set func(d *DataV2)) {
+	before := *c.pending()
+	if c.draft == nil {
+		d := c.data
+		c.draft = &d
+	}
+	c.applyErrors = nil
+	set(c.draft)
+	c.record(by, AuditDraft, 0, Diff(before, *c.draft))
+}
+
+// recordDraft records the changes of the draft configuration since before, except the changes that have been applied
+// as well, such that a change is recorded only once. The caller must hold the write lock.
+func (c *Configuration) recordDraft(by Actor, before DataV2, applied []Change) {
+	var changes []Change
+draft:
+	for _, ch := range Diff(before, *c.pending()) {
+		for _, a := range applied {
+			if a == ch {
+				continue draft
+			}
+		}
+		changes = append(changes, ch)
+	}
+	c.record(by, AuditDraft, 0, changes)
+}
+
+// addVersion records the active configuration as a new version. The caller must hold the write lock.
+func (c *Configuration) addVersion(comment string) {
+	number := 1
+	if len(c.versions) > 0 {
+		number = c.versions[len(c.versions)-1].Number + 1
+	}
+	c.versions = append(c.versions, Version{
+		Number:  number,
+		Applied: time.Now(),
+		Comment: comment,
+		Data:    c.data,
+	})
+}
+
+// Load replaces the active configuration without validation, discarding any pending changes.
+func (c *Configuration) Load(data DataV2) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data = data
+	c.draft = nil
+	if c.data.Timeout == 0 {
+		c.data.Timeout = 5 * time.Second
+	}
+	c.updateLog()
+}
+
+// Applied returns the active configuration, and the number of the most recently applied version.
+func (c *Configuration) Applied() (DataV2, int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	number := 0
+	if len(c.versions) > 0 {
+		number = c.versions[len(c.versions)-1].Number
+	}
+	return c.data, number
+}
+
+// Draft returns the configuration that is being edited. This is the active configuration if there are no pending
+// changes.
+func (c *Configuration) Draft() DataV2 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return *c.pending()
+}
+
+// Changes returns the differences between the active and the draft configuration.
+func (c *Configuration) Changes() []Change {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.draft == nil {
+		return nil
+	}
+	return Diff(c.data, *c.draft)
+}
+
+// HasChanges returns true if the draft configuration differs from the active configuration.
+func (c *Configuration) HasChanges() bool {
+	return len(c.Changes()) > 0
+}
+
+// Discard drops all pending changes, which are recorded in the audit log as reverted by by.
+func (c *Configuration) Discard(by Actor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	before := *c.pending()
+	c.draft = nil
+	c.applyErrors = nil
+	c.record(by, AuditDraft, 0, Diff(before, c.data))
+}
+
+// SetDraft replaces the draft configuration, such as when a complete configuration is uploaded. Like other changes,
+// it only takes effect once applied. Web interface users are not part of the configuration, so users in data are
+// ignored.
+func (c *Configuration) SetDraft(by Actor, data DataV2) error {
+	if _, err := data.takeUsers(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data.Version = 2
+	if data.Timeout == 0 {
+		data.Timeout = 5 * time.Second
+	}
+	before := *c.pending()
+	c.draft = &data
+	c.applyErrors = nil
+	c.record(by, AuditDraft, 0, Diff(before, data))
+	return nil
+}
+
+// Apply validates the draft configuration, and makes it the active configuration if it is valid. The new
+// configuration is stored as a new version and saved to disk.
+func (c *Configuration) Apply(ctx context.Context, by Actor, comment string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.apply(ctx, by, comment)
+}
+
+// ApplySetting changes a single setting and applies only that change, such as when a tested query is activated. Other
+// pending changes stay in the draft. If the change cannot be applied, it is kept in the draft as well.
+func (c *Configuration) ApplySetting(ctx context.Context, by Actor, comment string, set func(*DataV2)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	before, active := *c.pending(), c.data
+	pending := c.draft
+	d := c.data
+	set(&d)
+	c.draft = &d
+	err := c.apply(ctx, by, comment)
+
+	if pending != nil {
+		set(pending)
+		c.draft = pending
+		if len(Diff(c.data, *pending)) == 0 {
+			c.draft = nil
+		}
+	}
+	c.recordDraft(by, before, Diff(active, c.data))
+	return err
+}
+
+// apply applies the draft configuration, and records the changes made by by in the audit log. The caller must hold the
+// write lock.
+func (c *Configuration) apply(ctx context.Context, by Actor, comment string) error {
+	if c.draft == nil {
+		return nil
+	}
+	changes := Diff(c.data, *c.draft)
+	if len(changes) == 0 {
+		c.draft = nil
+		return nil
+	}
+
+	// validate everything that is configured, without replacing the results shown for the pending configuration
+	// until the draft has been applied
+	res := c.validateBase(ctx, c.draft)
+	c.validateRadiologie(ctx, c.draft, res)
+	c.validateLab(ctx, c.draft, res)
+	c.validateConsult(ctx, c.draft, res)
+
+	c.applyErrors = blockingErrors(res, changes)
+	if len(c.applyErrors) > 0 {
+		return ErrDraftInvalid
+	}
+
+	c.data = *c.draft
+	c.draft = nil
+	c.validationResult = res
+	c.active = res.IsValid()
+	c.addVersion(comment)
+	c.updateLog()
+	version := c.versions[len(c.versions)-1].Number
+	dlog.Info("Applied configuration version %d", version)
+	c.record(by, AuditApplied, version, changes)
+
+	return c.save()
+}
+
+// validationCheck relates a check of the validation to the settings it depends on.
+type validationCheck struct {
+	err    func(*ValidationResult) error
+	fields []string
+
+	// optional checks are those of order queries, which do not have to be configured
+	optional bool
+}
+
+// validationChecks lists the settings that each check of the validation depends on. Changes can be applied as long as
+// the checks of the changed settings succeed, such that a partial configuration can be applied, for example before
+// the door2doc server can be reached.
+var validationChecks = func() []validationCheck {
+	// queries cannot be tested without a database connection
+	queries := []string{"VisitorQuery", "RadiologieQuery", "LabQuery", "ConsultQuery"}
+	proxy := []string{"Proxy", "ProxyUsername", "ProxyPassword", "NoProxy", "ProxyPAC"}
+	d2d := append([]string{"Server", "ServerCAFile", "ServerPins", "ClientCertFile", "ClientKeyFile"}, proxy...)
+	credentials := append([]string{"Username", "Password", "AuthMethod", "TokenURL", "ClientID", "ClientSecret", "TokenScope"}, d2d...)
+
+	return []validationCheck{
+		{err: func(v *ValidationResult) error { return v.DatabaseConnection }, fields: append([]string{"Connection", "VisitorSource"}, queries...)},
+		{err: func(v *ValidationResult) error { return v.QueryTimeout }, fields: []string{"Timeout"}},
+		{err: func(v *ValidationResult) error { return v.VisitorSource }, fields: append([]string{"VisitorSource", "HL7Address", "FHIRSource"}, proxy...)},
+		{err: func(v *ValidationResult) error { return v.DropFolder }, fields: []string{"DropFolder", "VisitorSource"}},
+		{err: func(v *ValidationResult) error { return v.VisitorQuery }, fields: []string{"VisitorQuery", "VisitorSource", "Timeout"}},
+		{err: func(v *ValidationResult) error { return v.RadiologieQuery }, fields: []string{"RadiologieQuery", "Timeout"}, optional: true},
+		{err: func(v *ValidationResult) error { return v.LabQuery }, fields: []string{"LabQuery", "Timeout"}, optional: true},
+		{err: func(v *ValidationResult) error { return v.ConsultQuery }, fields: []string{"ConsultQuery", "Timeout"}, optional: true},
+		{err: func(v *ValidationResult) error { return v.D2DConnection }, fields: d2d},
+		{err: func(v *ValidationResult) error { return v.D2DCredentials }, fields: credentials},
+	}
+}()
+
+// blockingErrors returns the validation errors of the checks that depend on the changed settings.
+func blockingErrors(res *ValidationResult, changes []Change) []error {
+	var errs []error
+	for _, check := range validationChecks {
+		err := check.err(res)
+		if err == nil || (check.optional && err == ErrQueryNotConfigured) || !changesAny(changes, check.fields) {
+			continue
+		}
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// changesAny returns true if any of the fields, or a field nested in them, has changed.
+func changesAny(changes []Change, fields []string) bool {
+	for _, c := range changes {
+		for _, f := range fields {
+			if c.Field == f || strings.HasPrefix(c.Field, f+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ApplyErrors returns the validation errors that prevented the pending changes from being applied, if any.
+func (c *Configuration) ApplyErrors() []error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.applyErrors
+}
+
+// Versions returns all applied configurations, most recent first.
+func (c *Configuration) Versions() []Version {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	res := make([]Version, len(c.versions))
+	for i, v := range c.versions {
+		res[len(res)-1-i] = v
+	}
+	return res
+}
+
+// Rollback replaces any pending changes by a previously applied configuration, and applies it.
+func (c *Configuration) Rollback(ctx context.Context, by Actor, number int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, v := range c.versions {
+		if v.Number == number {
+			before, active := *c.pending(), c.data
+			d := v.Data
+			c.draft = &d
+			err := c.apply(ctx, by, fmt.Sprintf("Rolled back to version %d", number))
+			c.recordDraft(by, before, Diff(active, c.data))
+			return err
+		}
+	}
+	return ErrUnknownVersion
+}
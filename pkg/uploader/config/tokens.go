@@ -0,0 +1,132 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// tokens is the file in which API tokens are stored. Like paused datasets, they are not part of the configuration,
+// such that they take effect immediately.
+const tokens = "door2doc.tokens.json"
+
+// tokenPrefix makes API tokens recognizable, for example by secret scanners.
+const tokenPrefix = "d2d_"
+
+// APIToken authenticates requests to the management API. Only a hash of the token is stored. Like users, tokens
+// have a role that determines which requests they can make.
+type APIToken struct {
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"`
+	Role      string    `json:"role"`
+	Created   time.Time `json:"created"`
+	CreatedBy string    `json:"created_by"`
+}
+
+// Can returns true if the role of the token includes role.
+func (t APIToken) Can(role string) bool {
+	return User{Role: t.Role}.Can(role)
+}
+
+// APITokens returns the tokens that can be used for the management API.
+func (c *Configuration) APITokens() []APIToken {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]APIToken(nil), c.tokens...)
+}
+
+// CreateAPIToken creates a new token with the given role for the management API, and returns it. The token cannot be
+// retrieved later.
+func (c *Configuration) CreateAPIToken(by Actor, name, role string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrTokenNameMissing
+	}
+	if _, ok := roleRanks[role]; !ok {
+		return "", ErrUnknownRole
+	}
+
+	bs := make([]byte, 32)
+	if _, err := rand.Read(bs); err != nil {
+		return "", err
+	}
+	token := tokenPrefix + base64.RawURLEncoding.EncodeToString(bs)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, t := range c.tokens {
+		if t.Name == name {
+			return "", ErrTokenExists
+		}
+	}
+	res := append(append([]APIToken(nil), c.tokens...), APIToken{
+		Name:      name,
+		Hash:      hashToken(token),
+		Role:      role,
+		Created:   time.Now(),
+		CreatedBy: by.User,
+	})
+	if err := writeState(tokens, res); err != nil {
+		return "", err
+	}
+	c.record(by, AuditApplied, 0, []Change{{Field: "APITokens." + name + ".Role", New: role}})
+	c.tokens = res
+	return token, nil
+}
+
+// RevokeAPIToken removes a token, such that it can no longer be used.
+func (c *Configuration) RevokeAPIToken(by Actor, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		res     []APIToken
+		revoked []Change
+	)
+	for _, t := range c.tokens {
+		if t.Name != name {
+			res = append(res, t)
+		} else {
+			revoked = append(revoked, Change{Field: "APITokens." + name + ".Role", Old: t.Role})
+		}
+	}
+	if len(revoked) == 0 {
+		return nil
+	}
+	if err := writeState(tokens, res); err != nil {
+		return err
+	}
+	c.record(by, AuditApplied, 0, revoked)
+	c.tokens = res
+	return nil
+}
+
+// CheckAPIToken returns the API token matching token, if any.
+func (c *Configuration) CheckAPIToken(token string) (APIToken, bool) {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return APIToken{}, false
+	}
+	hash := []byte(hashToken(token))
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, t := range c.tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Hash), hash) == 1 {
+			return t, true
+		}
+	}
+	return APIToken{}, false
+}
+
+// hashToken returns the hash under which a token is stored. Tokens are random, so a salt is not required.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/password"
+)
+
+// Roles determine what users of the web interface can do. Each role includes the permissions of the previous one.
+const (
+	// RoleViewer can view the status and configuration, without secrets.
+	RoleViewer = "viewer"
+	// RoleOperator can also change queries, pause and run uploads, and apply changes.
+	RoleOperator = "operator"
+	// RoleAdmin can also change connections, credentials, certificates and users, and view secrets.
+	RoleAdmin = "admin"
+)
+
+// Roles lists all roles, from least to most permissions.
+var Roles = []string{RoleViewer, RoleOperator, RoleAdmin}
+
+var roleRanks = map[string]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// users is the file in which the users of the web interface are stored. Like API tokens, they are not part of the
+// configuration, such that changes take effect immediately, and rolling back the configuration does not restore
+// removed users or old passwords.
+const users = "door2doc.users.json"
+
+// User can log in to the web interface. Only a hash of the password is stored.
+type User struct {
+	Name string        `json:"name"`
+	Hash password.Hash `json:"password_hash"`
+	Role string        `json:"role"`
+}
+
+// Can returns true if the role of the user includes role.
+func (u User) Can(role string) bool {
+	required, ok := roleRanks[role]
+	return ok && roleRanks[u.Role] >= required
+}
+
+// dummyHash is checked for unknown users, such that the response time does not reveal which users exist.
+var dummyHash, _ = password.NewHash("door2doc")
+
+// AccessConfigured returns true if users have to log in to the web interface.
+func (c *Configuration) AccessConfigured() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.users) > 0
+}
+
+// Users returns the users of the web interface.
+func (c *Configuration) Users() []User {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]User(nil), c.users...)
+}
+
+// User returns the user with the given name.
+func (c *Configuration) User(name string) (User, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, u := range c.users {
+		if u.Name == name {
+			return u, true
+		}
+	}
+	return User{}, false
+}
+
+// CheckAccess returns the user with the given name, if pwd is its password.
+func (c *Configuration) CheckAccess(name, pwd string) (User, bool) {
+	var (
+		res   User
+		found bool
+	)
+	c.mu.RLock()
+	for _, u := range c.users {
+		if subtle.ConstantTimeCompare([]byte(u.Name), []byte(name)) == 1 {
+			res, found = u, true
+		}
+	}
+	c.mu.RUnlock()
+
+	if !found {
+		dummyHash.Check(pwd)
+		return User{}, false
+	}
+	return res, res.Hash.Check(pwd)
+}
+
+// SetUser adds a user, or changes the password and role of an existing user. If pwd is empty, the password of an
+// existing user is kept.
+func (c *Configuration) SetUser(by Actor, name, pwd, role string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrUserNameMissing
+	}
+	if _, ok := roleRanks[role]; !ok {
+		return ErrUnknownRole
+	}
+	var hash password.Hash
+	if pwd != "" {
+		var err error
+		if hash, err = password.NewHash(pwd); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res, err := setUser(c.users, name, hash, role)
+	if err != nil {
+		return err
+	}
+	if err := writeState(users, res); err != nil {
+		return err
+	}
+	c.record(by, AuditApplied, 0, userChanges(c.users, res))
+	c.users = res
+	return nil
+}
+
+// RemoveUser removes a user. Removing the last user leaves the web interface unsecured.
+func (c *Configuration) RemoveUser(by Actor, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res, err := removeUser(c.users, name)
+	if err != nil {
+		return err
+	}
+	if err := writeState(users, res); err != nil {
+		return err
+	}
+	c.record(by, AuditApplied, 0, userChanges(c.users, res))
+	c.users = res
+	return nil
+}
+
+// setUser returns a copy of users in which the user with the given name has been added or changed. If hash is empty,
+// the password of an existing user is kept.
+func setUser(users []User, name string, hash password.Hash, role string) ([]User, error) {
+	res := append([]User(nil), users...)
+	i := 0
+	for i < len(res) && res[i].Name != name {
+		i++
+	}
+	if i == len(res) {
+		if hash == "" {
+			return nil, ErrUserPasswordMissing
+		}
+		res = append(res, User{Name: name})
+	}
+	res[i].Role = role
+	if hash != "" {
+		res[i].Hash = hash
+	}
+	if !hasAdmin(res) {
+		return nil, ErrLastAdmin
+	}
+	return res, nil
+}
+
+// removeUser returns a copy of users without the user with the given name.
+func removeUser(users []User, name string) ([]User, error) {
+	var res []User
+	for _, u := range users {
+		if u.Name != name {
+			res = append(res, u)
+		}
+	}
+	if len(res) == len(users) {
+		return nil, ErrUnknownUser
+	}
+	if len(res) > 0 && !hasAdmin(res) {
+		return nil, ErrLastAdmin
+	}
+	return res, nil
+}
+
+func hasAdmin(users []User) bool {
+	for _, u := range users {
+		if u.Role == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// userChanges returns the differences between two lists of users for the audit log. Like in Diff, password hashes are
+// redacted.
+func userChanges(old, new []User) []Change {
+	find := func(users []User, name string) User {
+		for _, u := range users {
+			if u.Name == name {
+				return u
+			}
+		}
+		return User{}
+	}
+
+	var res []Change
+	for i, u := range append(append([]User(nil), old...), new...) {
+		if i >= len(old) && find(old, u.Name).Name != "" {
+			continue
+		}
+		o, n := find(old, u.Name), find(new, u.Name)
+		if o.Role != n.Role {
+			res = append(res, Change{Field: "Users." + u.Name + ".Role", Old: o.Role, New: n.Role})
+		}
+		if o.Hash != n.Hash {
+			res = append(res, Change{Field: "Users." + u.Name + ".Hash", Old: redact(string(o.Hash)), New: redact(string(n.Hash))})
+		}
+	}
+	return res
+}
@@ -0,0 +1,309 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
+)
+
+const (
+	// selfSignedCert and selfSignedKey are the files in the configuration folder that contain the certificate that is
+	// generated for the configuration interface, if no certificate has been uploaded.
+	selfSignedCert = "web-selfsigned-cert.pem"
+	selfSignedKey  = "web-selfsigned-key.pem"
+
+	// selfSignedValidity determines how long a generated certificate is valid.
+	selfSignedValidity = 10 * 365 * 24 * time.Hour
+
+	// DefaultWebAddress is the address of the configuration interface if none has been set, which only accepts
+	// connections from this machine. This includes installations that predate the setting, which used to listen on
+	// all interfaces.
+	DefaultWebAddress = "localhost:17226"
+)
+
+// selfSignedMu prevents concurrent generation of the self-signed certificate.
+var selfSignedMu sync.Mutex
+
+// WebServer determines how the configuration interface is served. Changes take effect when the service is restarted.
+type WebServer struct {
+	// Address is the address on which the configuration interface is served, such as :17226 to allow access from
+	// other machines. If not set, DefaultWebAddress is used.
+	Address string `json:"address,omitempty"`
+
+	// Allowlist contains the addresses and networks, such as 10.0.0.12 or 10.1.0.0/16, of other machines that may use
+	// the configuration interface. If empty, all machines may use it. This machine is always allowed.
+	Allowlist []string `json:"allowlist,omitempty"`
+
+	// TLS serves the configuration interface over HTTPS. This is the default on addresses that other machines can
+	// reach, unless PlainHTTP is set.
+	TLS bool `json:"tls,omitempty"`
+
+	// PlainHTTP serves the configuration interface over plain HTTP on addresses that other machines can reach.
+	PlainHTTP bool `json:"plain_http,omitempty"`
+
+	// RedirectHTTP redirects requests using plain HTTP to HTTPS. If not set, these requests are rejected, unless HTTPS
+	// is used by default, in which case they are always redirected.
+	RedirectHTTP bool `json:"redirect_http,omitempty"`
+
+	// CertFile and KeyFile contain an uploaded certificate and its private key. If not set, a self-signed certificate
+	// is used.
+	CertFile string `json:"cert_file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty"`
+}
+
+// ListenAddress returns the address on which the configuration interface is served.
+func (w WebServer) ListenAddress() string {
+	if w.Address == "" {
+		return DefaultWebAddress
+	}
+	return w.Address
+}
+
+// UsesTLS returns true if the configuration interface is served over HTTPS on addr, such that passwords and session
+// cookies are not sent in plain text to other machines.
+func (w WebServer) UsesTLS(addr string) bool {
+	return w.TLS || (!w.PlainHTTP && !loopbackAddress(addr))
+}
+
+// RedirectsHTTP returns true if plain HTTP requests on addr are redirected to HTTPS.
+func (w WebServer) RedirectsHTTP(addr string) bool {
+	return w.UsesTLS(addr) && (w.RedirectHTTP || !w.TLS)
+}
+
+// loopbackAddress returns true if addr only accepts connections from this machine.
+func loopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Allowed returns true if a client with the given IP address may use the configuration interface.
+func (w WebServer) Allowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() || len(w.Allowlist) == 0 {
+		return true
+	}
+	for _, entry := range w.Allowlist {
+		if n, err := parseAllowlistEntry(entry); err == nil && n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAllowlistEntry parses an IP address or a network in CIDR notation.
+func parseAllowlistEntry(entry string) (*net.IPNet, error) {
+	if strings.Contains(entry, "/") {
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, &AllowlistError{Entry: entry}
+		}
+		return n, nil
+	}
+
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, &AllowlistError{Entry: entry}
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	bits := 8 * len(ip)
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// Certificate returns the certificate for serving the configuration interface. A self-signed certificate is generated
+// if no certificate has been uploaded, and none has been generated before.
+func (w WebServer) Certificate() (tls.Certificate, error) {
+	if w.CertFile != "" {
+		return tls.LoadX509KeyPair(FilePath(w.CertFile), FilePath(w.KeyFile))
+	}
+
+	selfSignedMu.Lock()
+	defer selfSignedMu.Unlock()
+
+	cert, err := tls.LoadX509KeyPair(FilePath(selfSignedCert), FilePath(selfSignedKey))
+	if err == nil || !os.IsNotExist(err) {
+		return cert, err
+	}
+
+	certPEM, keyPEM, err := generateSelfSigned(time.Now())
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := writeNamedFile(selfSignedKey, keyPEM, 0600); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := writeNamedFile(selfSignedCert, certPEM, 0644); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// SelfSigned returns true if the certificate was generated, rather than uploaded.
+func (w WebServer) SelfSigned() bool {
+	return w.CertFile == ""
+}
+
+// generateSelfSigned generates a certificate for the host names and loopback addresses of this machine.
+func generateSelfSigned(now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "localhost"
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: hostname, Organization: []string{"Door2doc Upload Service"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{hostname, "localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if short, _, ok := strings.Cut(hostname, "."); ok {
+		tmpl.DNSNames = append(tmpl.DNSNames, short)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a certificate, as shown by browsers.
+func Fingerprint(c *x509.Certificate) string {
+	sum := sha256.Sum256(c.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// WebServer returns the settings for serving the configuration interface.
+func (c *Configuration) WebServer() WebServer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.WebServer
+}
+
+// SetWebAddress sets the address on which the configuration interface is served, and the machines that may use it.
+// Empty allowlist entries are ignored.
+func (c *Configuration) SetWebAddress(by Actor, address string, allowlist []string) error {
+	if address != "" && !validListenAddress(address) {
+		return ErrInvalidWebAddress
+	}
+	var entries []string
+	for _, entry := range allowlist {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if _, err := parseAllowlistEntry(entry); err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.edit(by, func(d *DataV2) {
+		d.WebServer.Address = address
+		d.WebServer.Allowlist = entries
+	})
+	return nil
+}
+
+// SetWebTLS determines whether the configuration interface is served over HTTPS, and whether plain HTTP requests are
+// redirected. Disabling HTTPS on an address that other machines can reach is recorded, such that it is not enabled by
+// default.
+func (c *Configuration) SetWebTLS(by Actor, enabled, redirect bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.edit(by, func(d *DataV2) {
+		d.WebServer.TLS = enabled
+		d.WebServer.PlainHTTP = !enabled && !loopbackAddress(d.WebServer.ListenAddress())
+		d.WebServer.RedirectHTTP = enabled && redirect
+	})
+}
+
+// SetWebCertificate sets the certificate for serving the configuration interface. The data is either PEM encoded,
+// including the private key, or a PKCS#12 file protected by password.
+func (c *Configuration) SetWebCertificate(by Actor, data []byte, password string) error {
+	certPEM, keyPEM, err := rest.ParseClientCertificate(data, password, nil)
+	if err == rest.ErrNoPrivateKey {
+		return ErrWebKeyMissing
+	} else if err != nil {
+		return err
+	}
+	certName, err := storeFile("web-cert", ".pem", certPEM)
+	if err != nil {
+		return err
+	}
+	keyName, err := storePrivateFile("web-key", ".pem", keyPEM)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.edit(by, func(d *DataV2) {
+		d.WebServer.CertFile = certName
+		d.WebServer.KeyFile = keyName
+	})
+	return nil
+}
+
+// ClearWebCertificate reverts to the self-signed certificate for serving the configuration interface.
+func (c *Configuration) ClearWebCertificate(by Actor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.edit(by, func(d *DataV2) {
+		d.WebServer.CertFile = ""
+		d.WebServer.KeyFile = ""
+	})
+}
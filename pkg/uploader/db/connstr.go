@@ -0,0 +1,236 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// keyValue is a single setting in a connection string.
+type keyValue struct {
+	Key   string
+	Value string
+}
+
+var errUnterminatedValue = errors.New("unterminated quoted value")
+
+// normalizeKey lower-cases a connection string key and collapses all whitespace, such that "User  ID" and "user id"
+// are considered equal.
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.Join(strings.Fields(key), " "))
+}
+
+// splitConnectionString splits an ADO.NET or ODBC style connection string into its settings. Values may be quoted
+// using double or single quotes, in which case the quote character is escaped by doubling it, or using braces, in
+// which case the closing brace is escaped by doubling it. Keys may contain an equals sign by doubling it.
+func splitConnectionString(s string) ([]keyValue, error) {
+	var (
+		res []keyValue
+		rs  = []rune(s)
+		i   = 0
+	)
+
+	for i < len(rs) {
+		// skip leading separators
+		for i < len(rs) && (rs[i] == ';' || unicode.IsSpace(rs[i])) {
+			i++
+		}
+		if i == len(rs) {
+			break
+		}
+
+		// read key
+		var key strings.Builder
+		for i < len(rs) {
+			if rs[i] == '=' {
+				if i+1 < len(rs) && rs[i+1] == '=' {
+					key.WriteRune('=')
+					i += 2
+					continue
+				}
+				break
+			}
+			if rs[i] == ';' {
+				break
+			}
+			key.WriteRune(rs[i])
+			i++
+		}
+		k := normalizeKey(key.String())
+		if i == len(rs) || rs[i] == ';' {
+			// key without value
+			if k != "" {
+				res = append(res, keyValue{Key: k})
+			}
+			continue
+		}
+		if k == "" {
+			return nil, fmt.Errorf("missing key at position %d", i)
+		}
+		i++ // skip '='
+
+		// skip whitespace before the value
+		for i < len(rs) && unicode.IsSpace(rs[i]) && rs[i] != ';' {
+			i++
+		}
+
+		// read value
+		var value strings.Builder
+		if i < len(rs) && (rs[i] == '"' || rs[i] == '\'' || rs[i] == '{') {
+			open, close := rs[i], rs[i]
+			if open == '{' {
+				close = '}'
+			}
+			i++
+			terminated := false
+			for i < len(rs) {
+				if rs[i] == close {
+					if i+1 < len(rs) && rs[i+1] == close {
+						value.WriteRune(close)
+						i += 2
+						continue
+					}
+					terminated = true
+					i++
+					break
+				}
+				value.WriteRune(rs[i])
+				i++
+			}
+			if !terminated {
+				return nil, fmt.Errorf("%s: %w", k, errUnterminatedValue)
+			}
+			for i < len(rs) && rs[i] != ';' {
+				if !unicode.IsSpace(rs[i]) {
+					return nil, fmt.Errorf("%s: unexpected character %q after quoted value", k, rs[i])
+				}
+				i++
+			}
+		} else {
+			for i < len(rs) && rs[i] != ';' {
+				value.WriteRune(rs[i])
+				i++
+			}
+		}
+
+		res = append(res, keyValue{Key: k, Value: strings.TrimSpace(value.String())})
+	}
+
+	return res, nil
+}
+
+// needsBraces returns true if the value cannot be represented as a bare value in a connection string.
+func needsBraces(s string) bool {
+	return strings.ContainsAny(s, ";{}'\"") || strings.TrimSpace(s) != s
+}
+
+// joinConnectionString formats settings as a connection string that can be parsed by the SQL Server driver. The
+// driver only supports escaping in its ODBC syntax, so that syntax is used if any value requires escaping.
+func joinConnectionString(kvs []keyValue) string {
+	odbc := false
+	for _, kv := range kvs {
+		if needsBraces(kv.Value) {
+			odbc = true
+		}
+	}
+
+	parts := make([]string, len(kvs))
+	for i, kv := range kvs {
+		value := kv.Value
+		if odbc && needsBraces(value) {
+			value = "{" + strings.ReplaceAll(value, "}", "}}") + "}"
+		}
+		parts[i] = kv.Key + "=" + value
+	}
+
+	if odbc {
+		return "odbc:" + strings.Join(parts, ";")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// splitPostgresConnectionString splits a libpq style key/value connection string, such as
+// "host=localhost dbname=db". Values may be single quoted, and use a backslash to escape quotes and backslashes.
+func splitPostgresConnectionString(s string) ([]keyValue, error) {
+	var (
+		res []keyValue
+		rs  = []rune(s)
+		i   = 0
+	)
+
+	skipSpace := func() {
+		for i < len(rs) && unicode.IsSpace(rs[i]) {
+			i++
+		}
+	}
+
+	for {
+		skipSpace()
+		if i == len(rs) {
+			break
+		}
+
+		var key strings.Builder
+		for i < len(rs) && rs[i] != '=' && !unicode.IsSpace(rs[i]) {
+			key.WriteRune(rs[i])
+			i++
+		}
+		skipSpace()
+		if i == len(rs) || rs[i] != '=' {
+			return nil, fmt.Errorf("missing value for %s", key.String())
+		}
+		i++
+		skipSpace()
+
+		var value strings.Builder
+		quoted := i < len(rs) && rs[i] == '\''
+		if quoted {
+			i++
+		}
+		terminated := !quoted
+		for i < len(rs) {
+			c := rs[i]
+			if c == '\\' && i+1 < len(rs) {
+				value.WriteRune(rs[i+1])
+				i += 2
+				continue
+			}
+			if quoted && c == '\'' {
+				terminated = true
+				i++
+				break
+			}
+			if !quoted && unicode.IsSpace(c) {
+				break
+			}
+			value.WriteRune(c)
+			i++
+		}
+		if !terminated {
+			return nil, fmt.Errorf("%s: %w", key.String(), errUnterminatedValue)
+		}
+
+		res = append(res, keyValue{Key: strings.ToLower(key.String()), Value: value.String()})
+	}
+
+	return res, nil
+}
+
+// isPostgresConnectionString returns true if s looks like a libpq style key/value connection string.
+func isPostgresConnectionString(s string) bool {
+	if strings.Contains(s, ";") {
+		return false
+	}
+	kvs, err := splitPostgresConnectionString(s)
+	if err != nil {
+		return false
+	}
+	for _, kv := range kvs {
+		switch kv.Key {
+		case "dbname", "host", "hostaddr", "sslmode":
+			return true
+		}
+	}
+	return false
+}
@@ -3,21 +3,52 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
 )
 
-// ExecuteVisitorQuery tries to execute the visitor query and marshal the result into records.
-func ExecuteVisitorQuery(ctx context.Context, tx *sql.Tx, query string, timeout time.Duration) (VisitorRecords, error) {
-	dbCtx, cancel := context.WithTimeout(ctx, timeout)
+// errLimit stops reading the result set once the requested number of rows has been read.
+var errLimit = errors.New("row limit reached")
+
+// ExecuteVisitorQuery tries to execute the visitor query and marshal the result into records. If limit is positive, at
+// most limit rows are read.
+func ExecuteVisitorQuery(ctx context.Context, tx *sql.Tx, query string, timeout time.Duration, limit int) (VisitorRecords, error) {
+	var res []VisitorRecord
+	_, err := StreamVisitorQuery(ctx, tx, query, timeout, func(rec *VisitorRecord) error {
+		res = append(res, *rec)
+		if len(res) == limit {
+			return errLimit
+		}
+		return nil
+	})
+	if err != nil && err != errLimit {
+		return nil, err
+	}
+	return res, nil
+}
+
+// StreamVisitorQuery executes the visitor query, and calls f for each record as it is read from the database. It
+// returns the number of records read. The record passed to f is reused for the next row. The timeout only applies to
+// executing the query, as f may take long, such as when records are uploaded while they are read.
+func StreamVisitorQuery(ctx context.Context, tx *sql.Tx, query string, timeout time.Duration, f func(*VisitorRecord) error) (int, error) {
+	// the rows are closed when their context is done, so the timeout cancels the context only while executing
+	dbCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	timer := time.AfterFunc(timeout, cancel)
 
 	// execute query
 	rows, err := tx.QueryContext(dbCtx, query)
+	if !timer.Stop() {
+		if err == nil {
+			_ = rows.Close()
+		}
+		return 0, context.DeadlineExceeded
+	}
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
@@ -28,31 +59,34 @@ func ExecuteVisitorQuery(ctx context.Context, tx *sql.Tx, query string, timeout
 	// determine column names
 	names, err := rows.Columns()
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	col2index, err := checkColumnNames(names, VisitorColumns)
+	col2index, err := MatchColumns(names, VisitorColumns)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	// map result set to records
-	var res []VisitorRecord
-
+	var (
+		rec VisitorRecord
+		n   int
+	)
 	for rows.Next() {
-		var rec VisitorRecord
-		err := mapVisitorRow(rows, &rec, names, col2index)
-		if err != nil {
-			return nil, err
+		rec = VisitorRecord{}
+		if err := mapVisitorRow(rows, &rec, names, col2index); err != nil {
+			return n, err
 		}
-
-		res = append(res, rec)
+		if err := f(&rec); err != nil {
+			return n, err
+		}
+		n++
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return n, err
 	}
 
-	return res, nil
+	return n, nil
 }
 
 func mapVisitorRow(rows *sql.Rows, rec *VisitorRecord, allColumns []string, col2index map[string]int) error {
@@ -169,8 +203,9 @@ func mapVisitorRow(rows *sql.Rows, rec *VisitorRecord, allColumns []string, col2
 	return nil
 }
 
-// ExecuteRadiologieQuery tries to execute the visitor query and marshal the result into records.
-func ExecuteRadiologieQuery(ctx context.Context, tx *sql.Tx, query string, timeout time.Duration) (RadiologieOrders, error) {
+// ExecuteRadiologieQuery tries to execute the visitor query and marshal the result into records. If limit is positive, at
+// most limit rows are read.
+func ExecuteRadiologieQuery(ctx context.Context, tx *sql.Tx, query string, timeout time.Duration, limit int) (RadiologieOrders, error) {
 	dbCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -191,7 +226,7 @@ func ExecuteRadiologieQuery(ctx context.Context, tx *sql.Tx, query string, timeo
 		return nil, err
 	}
 
-	col2index, err := checkColumnNames(names, RadiologieColumns)
+	col2index, err := MatchColumns(names, RadiologieColumns)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +234,7 @@ func ExecuteRadiologieQuery(ctx context.Context, tx *sql.Tx, query string, timeo
 	// map result set to records
 	var res []RadiologieOrder
 
-	for rows.Next() {
+	for (limit <= 0 || len(res) < limit) && rows.Next() {
 		var rec RadiologieOrder
 		err := mapRadiologieRow(rows, &rec, names, col2index)
 		if err != nil {
@@ -253,8 +288,9 @@ func mapRadiologieRow(rows *sql.Rows, rec *RadiologieOrder, allColumns []string,
 	return nil
 }
 
-// ExecuteLabQuery tries to execute the visitor query and marshal the result into records.
-func ExecuteLabQuery(ctx context.Context, tx *sql.Tx, query string, timeout time.Duration) (LabOrders, error) {
+// ExecuteLabQuery tries to execute the visitor query and marshal the result into records. If limit is positive, at
+// most limit rows are read.
+func ExecuteLabQuery(ctx context.Context, tx *sql.Tx, query string, timeout time.Duration, limit int) (LabOrders, error) {
 	dbCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -275,7 +311,7 @@ func ExecuteLabQuery(ctx context.Context, tx *sql.Tx, query string, timeout time
 		return nil, err
 	}
 
-	col2index, err := checkColumnNames(names, LabColumns)
+	col2index, err := MatchColumns(names, LabColumns)
 	if err != nil {
 		return nil, err
 	}
@@ -283,7 +319,7 @@ func ExecuteLabQuery(ctx context.Context, tx *sql.Tx, query string, timeout time
 	// map result set to records
 	var res []LabOrder
 
-	for rows.Next() {
+	for (limit <= 0 || len(res) < limit) && rows.Next() {
 		var rec LabOrder
 		err := mapLabRow(rows, &rec, names, col2index)
 		if err != nil {
@@ -334,8 +370,9 @@ func mapLabRow(rows *sql.Rows, rec *LabOrder, allColumns []string, col2index map
 	return nil
 }
 
-// ExecuteConsultQuery tries to execute the visitor query and marshal the result into records.
-func ExecuteConsultQuery(ctx context.Context, tx *sql.Tx, query string, timeout time.Duration) (ConsultOrders, error) {
+// ExecuteConsultQuery tries to execute the visitor query and marshal the result into records. If limit is positive, at
+// most limit rows are read.
+func ExecuteConsultQuery(ctx context.Context, tx *sql.Tx, query string, timeout time.Duration, limit int) (ConsultOrders, error) {
 	dbCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -356,7 +393,7 @@ func ExecuteConsultQuery(ctx context.Context, tx *sql.Tx, query string, timeout
 		return nil, err
 	}
 
-	col2index, err := checkColumnNames(names, ConsultColumns)
+	col2index, err := MatchColumns(names, ConsultColumns)
 	if err != nil {
 		return nil, err
 	}
@@ -364,7 +401,7 @@ func ExecuteConsultQuery(ctx context.Context, tx *sql.Tx, query string, timeout
 	// map result set to records
 	var res []ConsultOrder
 
-	for rows.Next() {
+	for (limit <= 0 || len(res) < limit) && rows.Next() {
 		var rec ConsultOrder
 		err := mapConsultRow(rows, &rec, names, col2index)
 		if err != nil {
@@ -431,7 +468,9 @@ func asTime(s string) string {
 	return s
 }
 
-func checkColumnNames(got []string, want []Column) (map[string]int, error) {
+// MatchColumns returns the position of each wanted column in got, by name. Names are compared case-insensitively. It
+// returns a SelectionError if any columns are missing.
+func MatchColumns(got []string, want []Column) (map[string]int, error) {
 	got2pos := make(map[string]int)
 	for i, s := range got {
 		got2pos[strings.ToLower(s)] = i
@@ -4,12 +4,26 @@ import (
 	"errors"
 	"fmt"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/password"
+	"net"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
 )
 
+// DriverSQLite is the name of the SQLite driver. SQLite databases are files; their path is stored as Database.
+const DriverSQLite = "sqlite3"
+
+var (
+	// EncryptModes lists the valid SQL Server encrypt options. The empty string uses the driver default.
+	EncryptModes = []string{"", "disable", "false", "true"}
+	// SSLModes lists the valid PostgreSQL sslmode options. The empty string uses the driver default.
+	SSLModes = []string{"", "disable", "require", "verify-ca", "verify-full"}
+)
+
 type ConnectionData struct {
 	Driver   string
 	Host     string
@@ -19,15 +33,80 @@ type ConnectionData struct {
 	Username string
 	Password password.Password
 	Params   string
+
+	// FailoverHosts are tried in order when the primary host is not reachable, such as the failover partner of a
+	// mirrored SQL Server database, or the replicas of an availability group.
+	FailoverHosts []string
+
+	// SQL Server encryption options
+	Encrypt                string
+	TrustServerCertificate bool
+	HostNameInCertificate  string
+
+	// PostgreSQL encryption options
+	SSLMode string
+
+	// CAFile is the path to a PEM file with the certificate(s) used to verify the database server.
+	CAFile string
 }
 
 func (c ConnectionData) IsValid() bool {
+	if c.Driver == DriverSQLite {
+		return c.Database != ""
+	}
 	return c.Driver != "" && c.Host != ""
 }
 
+// Validate checks the encryption options of the connection.
+func (c ConnectionData) Validate() error {
+	if !contains(EncryptModes, c.Encrypt) {
+		return &ConnectionOptionError{Option: "encrypt", Value: c.Encrypt}
+	}
+	if !contains(SSLModes, c.SSLMode) {
+		return &ConnectionOptionError{Option: "sslmode", Value: c.SSLMode}
+	}
+	for _, h := range c.FailoverHosts {
+		if _, err := c.withHost(h); err != nil {
+			return &ConnectionOptionError{Option: "failover host", Value: h, Cause: err}
+		}
+	}
+	if c.Driver == DriverSQLite {
+		if _, err := os.Stat(c.Database); err != nil {
+			return &ConnectionOptionError{Option: "database file", Value: c.Database, Cause: err}
+		}
+	}
+	if c.CAFile != "" {
+		if _, err := os.Stat(c.CAFile); err != nil {
+			return &ConnectionOptionError{Option: "CA file", Value: c.CAFile, Cause: err}
+		}
+	}
+	return nil
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// FromDSN parses a connection string. Supported are PostgreSQL URLs and key/value connection strings, SQL Server
+// URLs, ADO.NET and ODBC connection strings, and SQLite file names and URIs.
 func FromDSN(s string) (ConnectionData, error) {
-	if strings.HasPrefix(s, "postgres://") {
+	s = strings.TrimSpace(s)
+	switch {
+	case isSQLiteDSN(s):
+		return fromSQLiteDSN(s)
+	case strings.HasPrefix(s, "postgres://"), strings.HasPrefix(s, "postgresql://"):
 		return fromPostgresDSN(s)
+	case strings.HasPrefix(s, "sqlserver://"):
+		return fromSqlServerURL(s)
+	case strings.HasPrefix(s, "odbc:"):
+		return fromSqlServerDSN(strings.TrimPrefix(s, "odbc:"))
+	case isPostgresConnectionString(s):
+		return fromPostgresKeyValueDSN(s)
 	}
 
 	return fromSqlServerDSN(s)
@@ -48,68 +127,306 @@ func fromPostgresDSN(s string) (ConnectionData, error) {
 
 	pwd, _ := u.User.Password()
 	c.Password = password.Password(pwd)
-	c.Params = u.RawQuery
+
+	// move structured options out of the free-form parameters
+	var params []string
+	for _, p := range strings.Split(u.RawQuery, "&") {
+		key, val, _ := strings.Cut(p, "=")
+		val, err := url.QueryUnescape(val)
+		if err != nil {
+			return c, err
+		}
+		switch key {
+		case "":
+		case "sslmode":
+			c.SSLMode = val
+		case "sslrootcert":
+			c.CAFile = val
+		default:
+			params = append(params, p)
+		}
+	}
+	c.Params = strings.Join(params, "&")
 	return c, nil
 }
 
-func fromSqlServerDSN(s string) (ConnectionData, error) {
+// isSQLiteDSN returns true if s is a SQLite URI, or the name of a SQLite database file.
+func isSQLiteDSN(s string) bool {
+	if strings.HasPrefix(s, "file:") {
+		return true
+	}
+	if strings.ContainsAny(s, ";=") {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(s)) {
+	case ".db", ".db3", ".sqlite", ".sqlite3":
+		return true
+	}
+	return false
+}
+
+func fromSQLiteDSN(s string) (ConnectionData, error) {
+	var c ConnectionData
+	c.Driver = DriverSQLite
+
+	if !strings.HasPrefix(s, "file:") {
+		c.Database = s
+		return c, nil
+	}
+
+	path, query, _ := strings.Cut(strings.TrimPrefix(s, "file:"), "?")
+	path, err := url.PathUnescape(path)
+	if err != nil {
+		return c, err
+	}
+	if strings.HasPrefix(path, "///") {
+		path = strings.TrimPrefix(path, "//")
+	}
+	c.Database = path
+
+	// the database is always opened read-only
+	var params []string
+	for _, p := range strings.Split(query, "&") {
+		if key, _, _ := strings.Cut(p, "="); key != "" && key != "mode" {
+			params = append(params, p)
+		}
+	}
+	c.Params = strings.Join(params, "&")
+	return c, nil
+}
+
+func fromPostgresKeyValueDSN(s string) (ConnectionData, error) {
+	var c ConnectionData
+	c.Driver = "postgres"
+
+	kvs, err := splitPostgresConnectionString(s)
+	if err != nil {
+		return c, err
+	}
+
+	var hosts, ports []string
+	params := url.Values{}
+	for _, kv := range kvs {
+		switch kv.Key {
+		case "host", "hostaddr":
+			hosts = strings.Split(kv.Value, ",")
+		case "port":
+			ports = strings.Split(kv.Value, ",")
+		case "dbname":
+			c.Database = kv.Value
+		case "user":
+			c.Username = kv.Value
+		case "password":
+			c.Password = password.Password(kv.Value)
+		case "sslmode":
+			c.SSLMode = kv.Value
+		case "sslrootcert":
+			c.CAFile = kv.Value
+		default:
+			params.Add(kv.Key, kv.Value)
+		}
+	}
+	c.Params = params.Encode()
+
+	// libpq accepts a list of hosts with an optional list of ports, which are tried in order
+	for i, h := range hosts {
+		port := ""
+		switch {
+		case len(ports) == 1:
+			port = ports[0]
+		case i < len(ports):
+			port = ports[i]
+		}
+		if i == 0 {
+			c.Host, c.Port = h, port
+			continue
+		}
+		if port != "" {
+			h = net.JoinHostPort(h, port)
+		}
+		c.FailoverHosts = append(c.FailoverHosts, h)
+	}
+	return c, nil
+}
+
+func fromSqlServerURL(s string) (ConnectionData, error) {
 	var c ConnectionData
 	c.Driver = "sqlserver"
-	parts := strings.Split(s, ";")
-	for _, p := range parts {
-		keyVal := strings.Split(strings.TrimSpace(p), "=")
-		if len(keyVal) != 2 {
-			return c, errors.New("invalid connection string")
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return c, err
+	}
+	c.Host = u.Hostname()
+	c.Port = u.Port()
+	c.Instance = strings.TrimPrefix(u.Path, "/")
+	c.Username = u.User.Username()
+	pwd, _ := u.User.Password()
+	c.Password = password.Password(pwd)
+
+	var kvs []keyValue
+	for key, values := range u.Query() {
+		for _, v := range values {
+			kvs = append(kvs, keyValue{Key: normalizeKey(key), Value: v})
 		}
-		key, val := keyVal[0], keyVal[1]
-		switch strings.ToLower(key) {
-		case "server":
-			serverInstance := strings.Split(val, "\\")
-			switch len(serverInstance) {
-			case 1:
-				c.Host = serverInstance[0]
-			case 2:
-				c.Host = serverInstance[0]
-				c.Instance = serverInstance[1]
-			default:
-				return c, errors.New("invalid server")
+	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+	if err := c.setSqlServerOptions(kvs); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+func fromSqlServerDSN(s string) (ConnectionData, error) {
+	var c ConnectionData
+	c.Driver = "sqlserver"
+
+	kvs, err := splitConnectionString(s)
+	if err != nil {
+		return c, fmt.Errorf("invalid connection string: %w", err)
+	}
+	if len(kvs) == 0 {
+		return c, errors.New("invalid connection string")
+	}
+	if err := c.setSqlServerOptions(kvs); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// setSqlServerOptions interprets all SQL Server connection string settings, including their common synonyms.
+// Unknown settings are kept as parameters.
+func (c *ConnectionData) setSqlServerOptions(kvs []keyValue) error {
+	var (
+		params       []keyValue
+		failoverPort string
+	)
+	for _, kv := range kvs {
+		key, val := kv.Key, kv.Value
+		switch key {
+		case "server", "data source", "address", "addr", "network address":
+			if err := c.setSqlServer(val); err != nil {
+				return err
 			}
-		case "database":
+		case "port":
+			c.Port = val
+		case "database", "initial catalog":
 			c.Database = val
-		case "user id":
+		case "user id", "uid", "user":
 			c.Username = val
-		case "password":
+		case "password", "pwd":
 			c.Password = password.Password(val)
-		case "integrated security":
-			c.Username = ""
-			c.Password = ""
-		default:
-			if len(c.Params) != 0 {
-				c.Params += ";"
+		case "integrated security", "trusted_connection":
+			switch strings.ToLower(val) {
+			case "true", "yes", "sspi":
+				c.Username = ""
+				c.Password = ""
 			}
-			c.Params += key + "=" + val
+		case "encrypt":
+			c.Encrypt = strings.ToLower(val)
+		case "trustservercertificate", "trust server certificate":
+			c.TrustServerCertificate = strings.EqualFold(val, "true") || strings.EqualFold(val, "yes")
+		case "hostnameincertificate", "host name in certificate":
+			c.HostNameInCertificate = val
+		case "certificate":
+			c.CAFile = val
+		case "failover partner", "failoverpartner":
+			if val != "" {
+				c.FailoverHosts = append(c.FailoverHosts, val)
+			}
+		case "failoverport", "failover port":
+			failoverPort = val
+		case "driver", "provider":
+			// ODBC and OLE DB specific, not used by the Go driver
+		default:
+			params = append(params, kv)
 		}
-		_ = val
 	}
+	if len(params) > 0 {
+		c.Params = joinConnectionString(params)
+	}
+	if failoverPort != "" && len(c.FailoverHosts) == 1 && !strings.Contains(c.FailoverHosts[0], ",") {
+		c.FailoverHosts[0] += "," + failoverPort
+	}
+	return nil
+}
 
-	return c, nil
+// setSqlServer interprets a server specification such as "tcp:host\instance,1433".
+func (c *ConnectionData) setSqlServer(val string) error {
+	if protocol, rest, ok := strings.Cut(val, ":"); ok && !strings.Contains(protocol, "\\") {
+		if !strings.EqualFold(protocol, "tcp") {
+			return fmt.Errorf("unsupported protocol: %s", protocol)
+		}
+		val = rest
+	}
+
+	if host, port, ok := strings.Cut(val, ","); ok {
+		val = host
+		c.Port = strings.TrimSpace(port)
+	}
+
+	serverInstance := strings.Split(strings.TrimSpace(val), "\\")
+	switch len(serverInstance) {
+	case 1:
+		c.Host = serverInstance[0]
+	case 2:
+		c.Host = serverInstance[0]
+		c.Instance = serverInstance[1]
+	default:
+		return errors.New("invalid server")
+	}
+	return nil
 }
 
 func (c ConnectionData) toDSN() (string, error) {
-	if c.Driver == "postgres" {
+	switch c.Driver {
+	case "postgres":
 		return c.toPostgresDSN()
+	case DriverSQLite:
+		return c.toSQLiteDSN()
 	}
 
 	return c.toSqlServerDSN()
 }
 
+// toSQLiteDSN returns a URI that opens the database file read-only.
+func (c ConnectionData) toSQLiteDSN() (string, error) {
+	path := strings.ReplaceAll(c.Database, "\\", "/")
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?mode=ro"
+	if c.Params != "" {
+		dsn += "&" + c.Params
+	}
+	return dsn, nil
+}
+
 func (c ConnectionData) toPostgresDSN() (string, error) {
+	var params []string
+	if c.SSLMode != "" {
+		params = append(params, "sslmode="+url.QueryEscape(c.SSLMode))
+	}
+	if c.CAFile != "" {
+		params = append(params, "sslrootcert="+url.QueryEscape(c.CAFile))
+	}
+	if c.Params != "" {
+		params = append(params, c.Params)
+	}
+
+	var user *url.Userinfo
+	switch {
+	case c.Password != "":
+		user = url.UserPassword(c.Username, c.Password.PlainText())
+	case c.Username != "":
+		user = url.User(c.Username)
+	}
+
 	u := &url.URL{
 		Scheme:   "postgres",
-		User:     url.UserPassword(c.Username, c.Password.PlainText()),
+		User:     user,
 		Host:     c.Host,
 		Path:     "/" + c.Database,
-		RawQuery: c.Params,
+		RawQuery: strings.Join(params, "&"),
 	}
 	if c.Port != "" {
 		u.Host = u.Host + ":" + c.Port
@@ -118,30 +435,48 @@ func (c ConnectionData) toPostgresDSN() (string, error) {
 }
 
 func (c ConnectionData) toSqlServerDSN() (string, error) {
-	var parts []string
+	var parts []keyValue
 	server := c.Host
 	if c.Instance != "" {
 		server += "\\" + c.Instance
 	}
-	parts = append(parts, fmt.Sprintf("server=%s", server))
+	parts = append(parts, keyValue{"server", server})
+	if c.Port != "" {
+		parts = append(parts, keyValue{"port", c.Port})
+	}
 	if c.Database != "" {
-		parts = append(parts, fmt.Sprintf("database=%s", c.Database))
+		parts = append(parts, keyValue{"database", c.Database})
 	}
 	if c.Username != "" {
-		parts = append(parts, fmt.Sprintf("user id=%s", c.Username))
+		parts = append(parts, keyValue{"user id", c.Username})
 	}
 	if c.Password != "" {
-		parts = append(parts, fmt.Sprintf("password=%s", c.Password.PlainText()))
+		parts = append(parts, keyValue{"password", c.Password.PlainText()})
 	}
 	if c.Username == "" && c.Password == "" {
-		parts = append(parts, "integrated security=SSPI")
+		parts = append(parts, keyValue{"integrated security", "SSPI"})
+	}
+	if c.Encrypt != "" {
+		parts = append(parts, keyValue{"encrypt", c.Encrypt})
+	}
+	if c.TrustServerCertificate {
+		parts = append(parts, keyValue{"TrustServerCertificate", "true"})
+	}
+	if c.HostNameInCertificate != "" {
+		parts = append(parts, keyValue{"hostNameInCertificate", c.HostNameInCertificate})
+	}
+	if c.CAFile != "" {
+		parts = append(parts, keyValue{"certificate", c.CAFile})
 	}
 	if c.Params != "" {
-		parts = append(parts, c.Params)
+		params, err := splitConnectionString(strings.TrimPrefix(c.Params, "odbc:"))
+		if err != nil {
+			return "", fmt.Errorf("invalid parameters: %w", err)
+		}
+		parts = append(parts, params...)
 	}
 
-	dsn := strings.Join(parts, "; ")
-	return dsn, nil
+	return joinConnectionString(parts), nil
 }
 
 func (c ConnectionData) DSN() string {
@@ -17,3 +17,21 @@ type SelectionError struct {
 func (s *SelectionError) Error() string {
 	return fmt.Sprintf("missing columns: %v, got: %v", s.Missing, s.Got)
 }
+
+// ConnectionOptionError indicates that an option of the database connection has an invalid value.
+type ConnectionOptionError struct {
+	Option string
+	Value  string
+	Cause  error
+}
+
+func (e *ConnectionOptionError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("invalid %s %q: %v", e.Option, e.Value, e.Cause)
+	}
+	return fmt.Sprintf("invalid %s %q", e.Option, e.Value)
+}
+
+func (e *ConnectionOptionError) Unwrap() error {
+	return e.Cause
+}
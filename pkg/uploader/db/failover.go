@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+)
+
+// Hosts returns the connection for the primary host, followed by the connections for all failover hosts, in the order
+// in which they should be tried. Invalid failover hosts are skipped; they are reported by Validate.
+func (c ConnectionData) Hosts() []ConnectionData {
+	primary := c
+	primary.FailoverHosts = nil
+
+	res := []ConnectionData{primary}
+	for _, h := range c.FailoverHosts {
+		f, err := primary.withHost(h)
+		if err != nil {
+			continue
+		}
+		res = append(res, f)
+	}
+	return res
+}
+
+// withHost returns a copy of the connection that connects to another host. The host is specified as "host:port" for
+// PostgreSQL, and as "host\instance,port" for SQL Server. The port and instance of the original connection are used
+// when not specified.
+func (c ConnectionData) withHost(spec string) (ConnectionData, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return c, errors.New("empty host")
+	}
+
+	res := c
+	if c.Driver == "postgres" {
+		if !strings.Contains(spec, ":") {
+			res.Host = spec
+			return res, nil
+		}
+		host, port, err := net.SplitHostPort(spec)
+		if err != nil {
+			return c, err
+		}
+		res.Host, res.Port = host, port
+		return res, nil
+	}
+
+	if err := res.setSqlServer(spec); err != nil {
+		return c, err
+	}
+	return res, nil
+}
+
+// Open connects to the first reachable host of the connection, trying the failover hosts in order. It returns the
+// database and the connection data of the host that was used.
+func Open(ctx context.Context, c ConnectionData) (*sql.DB, ConnectionData, error) {
+	var lastErr error
+	for i, h := range c.Hosts() {
+		conn, err := sql.Open(h.Driver, h.DSN())
+		if err != nil {
+			return nil, h, err
+		}
+		if err := conn.PingContext(ctx); err != nil {
+			dlog.Error("Failed to ping database %s: %v", h.Host, err)
+			dlog.Close(conn)
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		if i > 0 {
+			dlog.Info("Connected to failover database host %s", h.Host)
+		}
+		return conn, h, nil
+	}
+
+	if len(c.FailoverHosts) > 0 {
+		return nil, c, fmt.Errorf("no database host reachable: %w", lastErr)
+	}
+	return nil, c, lastErr
+}
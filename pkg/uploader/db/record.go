@@ -139,27 +139,49 @@ type VisitorRecord struct {
 	Vervallen         bool
 }
 
-func maxCount(length int) int {
-	if length > 10 {
-		return 10
+// TableSize is the number of records shown by AsTable.
+const TableSize = 10
+
+// PageBounds returns the slice bounds of the given zero-based page of length items, clamped to the items.
+func PageBounds(length, page, size int) (int, int) {
+	from := page * size
+	if page < 0 || from > length {
+		from = length
+	}
+	to := from + size
+	if to > length {
+		to = length
 	}
-	return length
+	return from, to
 }
 
 type VisitorRecords []VisitorRecord
 
+// AsTable renders the first TableSize records as an HTML table.
 func (v VisitorRecords) AsTable() template.HTML {
+	return v.TablePage(0, TableSize)
+}
+
+// TablePage renders the given zero-based page of records as an HTML table.
+func (v VisitorRecords) TablePage(page, size int) template.HTML {
+	from, to := PageBounds(len(v), page, size)
+
 	var buf bytes.Buffer
 	if err := visitorTableTmpl.Execute(&buf, struct {
 		Columns      []Column
 		QueryResults []VisitorRecord
-	}{VisitorColumns, v[:maxCount(len(v))]}); err != nil {
+	}{VisitorColumns, v[from:to]}); err != nil {
 		panic(err)
 	}
 
 	return template.HTML(buf.String())
 }
 
+// Len returns the number of records.
+func (v VisitorRecords) Len() int {
+	return len(v)
+}
+
 var visitorTableTmpl = template.Must(template.New("table").Parse(`
 <table class="table">
 	<thead>
@@ -217,18 +239,31 @@ type RadiologieOrder struct {
 
 type RadiologieOrders []RadiologieOrder
 
+// AsTable renders the first TableSize records as an HTML table.
 func (r RadiologieOrders) AsTable() template.HTML {
+	return r.TablePage(0, TableSize)
+}
+
+// TablePage renders the given zero-based page of records as an HTML table.
+func (r RadiologieOrders) TablePage(page, size int) template.HTML {
+	from, to := PageBounds(len(r), page, size)
+
 	var buf bytes.Buffer
 	if err := radiologieTableTmpl.Execute(&buf, struct {
 		Columns      []Column
 		QueryResults []RadiologieOrder
-	}{RadiologieColumns, r[:maxCount(len(r))]}); err != nil {
+	}{RadiologieColumns, r[from:to]}); err != nil {
 		panic(err)
 	}
 
 	return template.HTML(buf.String())
 }
 
+// Len returns the number of records.
+func (r RadiologieOrders) Len() int {
+	return len(r)
+}
+
 var radiologieTableTmpl = template.Must(template.New("table").Parse(`
 <table class="table">
 	<thead>
@@ -263,18 +298,31 @@ type LabOrder struct {
 
 type LabOrders []LabOrder
 
+// AsTable renders the first TableSize records as an HTML table.
 func (r LabOrders) AsTable() template.HTML {
+	return r.TablePage(0, TableSize)
+}
+
+// TablePage renders the given zero-based page of records as an HTML table.
+func (r LabOrders) TablePage(page, size int) template.HTML {
+	from, to := PageBounds(len(r), page, size)
+
 	var buf bytes.Buffer
 	if err := labTableTmpl.Execute(&buf, struct {
 		Columns      []Column
 		QueryResults []LabOrder
-	}{LabColumns, r[:maxCount(len(r))]}); err != nil {
+	}{LabColumns, r[from:to]}); err != nil {
 		panic(err)
 	}
 
 	return template.HTML(buf.String())
 }
 
+// Len returns the number of records.
+func (r LabOrders) Len() int {
+	return len(r)
+}
+
 var labTableTmpl = template.Must(template.New("table").Parse(`
 <table class="table">
 	<thead>
@@ -309,18 +357,31 @@ type ConsultOrder struct {
 
 type ConsultOrders []ConsultOrder
 
+// AsTable renders the first TableSize records as an HTML table.
 func (r ConsultOrders) AsTable() template.HTML {
+	return r.TablePage(0, TableSize)
+}
+
+// TablePage renders the given zero-based page of records as an HTML table.
+func (r ConsultOrders) TablePage(page, size int) template.HTML {
+	from, to := PageBounds(len(r), page, size)
+
 	var buf bytes.Buffer
 	if err := consultTableTmpl.Execute(&buf, struct {
 		Columns      []Column
 		QueryResults []ConsultOrder
-	}{ConsultColumns, r[:maxCount(len(r))]}); err != nil {
+	}{ConsultColumns, r[from:to]}); err != nil {
 		panic(err)
 	}
 
 	return template.HTML(buf.String())
 }
 
+// Len returns the number of records.
+func (r ConsultOrders) Len() int {
+	return len(r)
+}
+
 var consultTableTmpl = template.Must(template.New("table").Parse(`
 <table class="table">
 	<thead>
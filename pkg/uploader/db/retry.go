@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+)
+
+// RetryPolicy determines how often and how fast transient database errors are retried.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts, including the first one.
+	Attempts int
+	// Backoff is the delay before the first retry. It doubles on every subsequent retry.
+	Backoff time.Duration
+	// MaxBackoff limits the delay between retries.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used by the uploader for all queries.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   4,
+	Backoff:    500 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+}
+
+// sqlServerError is implemented by errors returned by the SQL Server driver.
+type sqlServerError interface {
+	SQLErrorNumber() int32
+}
+
+// postgresError is implemented by errors returned by the PostgreSQL driver.
+type postgresError interface {
+	SQLState() string
+}
+
+// transientSqlServerErrors lists the SQL Server error numbers that are expected to go away when retried.
+var transientSqlServerErrors = map[int32]bool{
+	1205:  true, // deadlock victim
+	983:   true, // availability replica not in primary or secondary role
+	976:   true, // availability database not accessible
+	40197: true, // service error, failover in progress
+	40501: true, // service is busy
+	40613: true, // database unavailable
+}
+
+// transientPostgresErrors lists the PostgreSQL SQLSTATE codes that are expected to go away when retried.
+var transientPostgresErrors = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"57P01": true, // admin_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// IsTransient returns true if err is a database error that may succeed when retried, such as a deadlock, a
+// serialization failure or a broken network connection. A host name that does not exist is not retried.
+func IsTransient(err error) bool {
+	var mssqlErr sqlServerError
+	if errors.As(err, &mssqlErr) {
+		return transientSqlServerErrors[mssqlErr.SQLErrorNumber()]
+	}
+	var pqErr postgresError
+	if errors.As(err, &pqErr) {
+		return transientPostgresErrors[pqErr.SQLState()]
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+	return IsConnectionError(err)
+}
+
+// IsConnectionError returns true if err indicates that the connection to the database server was lost or could not be
+// established. Such errors may be resolved by connecting to a failover host.
+func IsConnectionError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Retry calls f until it succeeds, returns an error that is not transient, or the maximum number of attempts has been
+// reached. The delay between attempts increases exponentially.
+func Retry(ctx context.Context, policy RetryPolicy, f func(ctx context.Context) error) error {
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		err := f(ctx)
+		if err == nil || attempt >= policy.Attempts || !IsTransient(err) {
+			return err
+		}
+
+		dlog.Info("Transient database error, retrying in %v (attempt %d of %d): %v", backoff, attempt+1, policy.Attempts, err)
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
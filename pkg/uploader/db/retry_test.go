@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type testSqlServerError int32
+
+func (e testSqlServerError) Error() string         { return fmt.Sprintf("mssql: error %d", int32(e)) }
+func (e testSqlServerError) SQLErrorNumber() int32 { return int32(e) }
+
+type testPostgresError string
+
+func (e testPostgresError) Error() string    { return "pq: " + string(e) }
+func (e testPostgresError) SQLState() string { return string(e) }
+
+func TestIsTransient(t *testing.T) {
+	for name, test := range map[string]struct {
+		Given error
+		Want  bool
+	}{
+		"deadlock victim":       {Given: testSqlServerError(1205), Want: true},
+		"invalid object name":   {Given: testSqlServerError(208)},
+		"serialization failure": {Given: testPostgresError("40001"), Want: true},
+		"deadlock detected":     {Given: testPostgresError("40P01"), Want: true},
+		"syntax error":          {Given: testPostgresError("42601")},
+		"bad connection":        {Given: driver.ErrBadConn, Want: true},
+		"connection reset":      {Given: fmt.Errorf("read: %w", syscall.ECONNRESET), Want: true},
+		"unknown host":          {Given: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "db.invalid", IsNotFound: true}}},
+		"dns timeout":           {Given: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "i/o timeout", Name: "db.example", IsTimeout: true}}, Want: true},
+		"deadline exceeded":     {Given: context.DeadlineExceeded},
+		"other":                 {Given: errors.New("other")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := IsTransient(test.Given); got != test.Want {
+				t.Errorf("IsTransient() == %t, got %t", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+
+	for name, test := range map[string]struct {
+		Errors       []error
+		WantAttempts int
+		WantErr      bool
+	}{
+		"success":           {WantAttempts: 1},
+		"transient":         {Errors: []error{testSqlServerError(1205)}, WantAttempts: 2},
+		"permanent":         {Errors: []error{testSqlServerError(208)}, WantAttempts: 1, WantErr: true},
+		"too many failures": {Errors: []error{driver.ErrBadConn, driver.ErrBadConn, driver.ErrBadConn}, WantAttempts: 3, WantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			err := Retry(context.Background(), policy, func(ctx context.Context) error {
+				attempts++
+				if attempts <= len(test.Errors) {
+					return test.Errors[attempts-1]
+				}
+				return nil
+			})
+			if (err != nil) != test.WantErr {
+				t.Errorf("Retry() == error %t, got %v", test.WantErr, err)
+			}
+			if attempts != test.WantAttempts {
+				t.Errorf("Retry() == %d attempts, got %d", test.WantAttempts, attempts)
+			}
+		})
+	}
+}
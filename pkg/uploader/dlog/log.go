@@ -7,13 +7,22 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/kardianos/service"
 )
 
+const pathFeedback = "/services/v3/upload/feedback"
+
 var (
-	svc      service.Logger
+	svc service.Logger
+
+	// mu guards the settings used for error reports, which change whenever a configuration is applied
+	mu       sync.RWMutex
 	username string
+	server   string
+	do       = http.DefaultClient.Do
 )
 
 // SetService uses the service log for all subsequent logging.
@@ -27,9 +36,26 @@ func SetService(s service.Service) {
 
 // SetUsername sets the username scope for all subsequent logging
 func SetUsername(s string) {
+	mu.Lock()
+	defer mu.Unlock()
 	username = s
 }
 
+// SetServer sets the door2doc server that errors are reported to.
+func SetServer(s string) {
+	mu.Lock()
+	defer mu.Unlock()
+	server = s
+}
+
+// SetDo sets the function used to send error reports, such that they use the same proxy settings as all other
+// outgoing requests.
+func SetDo(f func(req *http.Request) (*http.Response, error)) {
+	mu.Lock()
+	defer mu.Unlock()
+	do = f
+}
+
 func Info(pattern string, args ...interface{}) {
 	if svc == nil {
 		log.Printf(pattern, args...)
@@ -46,8 +72,11 @@ func Info(pattern string, args ...interface{}) {
 func Error(pattern string, args ...interface{}) {
 	msg := fmt.Sprintf(pattern, args...)
 
-	if svc != nil {
-		go submitError(username, msg)
+	mu.RLock()
+	server, user, do := server, username, do
+	mu.RUnlock()
+	if svc != nil && server != "" {
+		go submitError(do, server, user, msg)
 	}
 
 	if svc == nil {
@@ -62,8 +91,8 @@ func Error(pattern string, args ...interface{}) {
 	}
 }
 
-func submitError(user string, msg string) {
-	req, err := http.NewRequest(http.MethodPost, "https://integration.door2doc.net/services/v3/upload/feedback", nil)
+func submitError(do func(req *http.Request) (*http.Response, error), server, user string, msg string) {
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(server, "/")+pathFeedback, nil)
 	if err != nil {
 		return
 	}
@@ -72,7 +101,7 @@ func submitError(user string, msg string) {
 	q.Add("message", msg)
 	req.URL.RawQuery = q.Encode()
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := do(req)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,325 @@
+package dropfolder
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+)
+
+const (
+	// DefaultDelimiter separates fields, as in the exports read by d2d-import.
+	DefaultDelimiter = ";"
+	// DefaultTimeLayout is the layout of dates and times, as in the exports read by d2d-import.
+	DefaultTimeLayout = "2006-01-02 15:04:05"
+)
+
+// ErrInvalidDelimiter indicates that the delimiter is not a single character.
+var ErrInvalidDelimiter = errors.New("delimiter must be a single character, or tab")
+
+// Dialect determines how CSV files are read.
+type Dialect struct {
+	Delimiter  rune
+	TimeLayout string
+}
+
+// LineError indicates that a line of a CSV file could not be read.
+type LineError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (e *LineError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %s: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// table reads the rows of a CSV file by column name.
+type table struct {
+	r       *csv.Reader
+	dialect Dialect
+	loc     *time.Location
+	columns map[string]int
+
+	line   int
+	fields []string
+	err    error
+}
+
+// newTable reads the header of a CSV file, and checks that it contains the wanted columns. Column names are compared
+// case-insensitively, and their order does not matter.
+func newTable(r io.Reader, d Dialect, loc *time.Location, want []db.Column) (*table, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = d.Delimiter
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(header))
+	for i, h := range header {
+		names[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	}
+
+	columns, err := db.MatchColumns(names, want)
+	if err != nil {
+		return nil, err
+	}
+	return &table{r: cr, dialect: d, loc: loc, columns: columns, line: 1}, nil
+}
+
+// next reads the next row. It returns false at the end of the file, or if the previous row contained an error.
+func (t *table) next() (bool, error) {
+	if t.err != nil {
+		return false, t.err
+	}
+	fields, err := t.r.Read()
+	if err == io.EOF {
+		return false, nil
+	}
+	t.line++
+	if err != nil {
+		return false, &LineError{Line: t.line, Err: err}
+	}
+	t.fields = fields
+	return true, nil
+}
+
+func (t *table) string(c db.Column) string {
+	return strings.TrimSpace(t.fields[t.columns[c.Name]])
+}
+
+// fail records the first error in the current row.
+func (t *table) fail(c db.Column, err error) {
+	if t.err == nil {
+		t.err = &LineError{Line: t.line, Column: c.Name, Err: err}
+	}
+}
+
+func (t *table) int(c db.Column) int {
+	n, err := strconv.Atoi(t.string(c))
+	if err != nil {
+		t.fail(c, fmt.Errorf("invalid number %q", t.string(c)))
+	}
+	return n
+}
+
+func (t *table) bool(c db.Column) bool {
+	s := t.string(c)
+	if s == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		t.fail(c, fmt.Errorf("invalid boolean %q", s))
+	}
+	return b
+}
+
+// time parses a date and time using the layout of the dialect, or a date without time.
+func (t *table) time(c db.Column) *time.Time {
+	s := t.string(c)
+	if s == "" {
+		return nil
+	}
+	for _, layout := range []string{t.dialect.TimeLayout, "2006-01-02"} {
+		if v, err := time.ParseInLocation(layout, s, t.loc); err == nil {
+			return &v
+		}
+	}
+	t.fail(c, fmt.Errorf("invalid time %q, expected layout %s", s, t.dialect.TimeLayout))
+	return nil
+}
+
+// date returns a date as 2006-01-02.
+func (t *table) date(c db.Column) string {
+	if v := t.time(c); v != nil {
+		return v.Format("2006-01-02")
+	}
+	return ""
+}
+
+// clock returns the time of day as 15:04. Values that also contain a date are accepted.
+func (t *table) clock(c db.Column) string {
+	s := t.string(c)
+	if s == "" {
+		return ""
+	}
+	if i := strings.LastIndexAny(s, " T"); i >= 0 {
+		s = s[i+1:]
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if v, err := time.Parse(layout, s); err == nil {
+			return v.Format("15:04")
+		}
+	}
+	t.fail(c, fmt.Errorf("invalid time of day %q", t.string(c)))
+	return ""
+}
+
+// ReadVisits reads visitor records from a CSV file with the columns of the visitor query.
+func ReadVisits(r io.Reader, d Dialect, loc *time.Location) (db.VisitorRecords, error) {
+	t, err := newTable(r, d, loc, db.VisitorColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	var res db.VisitorRecords
+	for {
+		ok, err := t.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return res, nil
+		}
+
+		rec := db.VisitorRecord{
+			Bezoeknummer:      t.int(db.ColBezoeknummer),
+			MutatieID:         t.int(db.ColMutatieID),
+			Locatie:           t.string(db.ColLocatie),
+			Afdeling:          t.string(db.ColAfdeling),
+			BinnenkomstDatum:  t.date(db.ColBinnenkomstDatum),
+			BinnenkomstTijd:   t.clock(db.ColBinnenkomstTijd),
+			TriageTijd:        t.clock(db.ColTriageTijd),
+			NaarKamerTijd:     t.clock(db.ColNaarKamerTijd),
+			BijArtsTijd:       t.clock(db.ColBijArtsTijd),
+			ArtsKlaarTijd:     t.clock(db.ColArtsKlaarTijd),
+			GereedOpnameTijd:  t.clock(db.ColGereedOpnameTijd),
+			VertrekTijd:       t.clock(db.ColVertrekTijd),
+			EindTijd:          t.clock(db.ColEindTijd),
+			MutatieEindTijd:   t.clock(db.ColMutatieEindTijd),
+			Mutatiestatus:     t.string(db.ColMutatieStatus),
+			Kamer:             t.string(db.ColKamer),
+			Bed:               t.string(db.ColBed),
+			Ingangsklacht:     t.string(db.ColIngangsklacht),
+			Specialisme:       t.string(db.ColSpecialisme),
+			Urgentie:          t.string(db.ColUrgentie),
+			Vervoerder:        t.string(db.ColVervoerder),
+			OpnameAfdeling:    t.string(db.ColOpnameAfdeling),
+			OpnameSpecialisme: t.string(db.ColOpnameSpecialisme),
+			Herkomst:          t.string(db.ColHerkomst),
+			Ontslagbestemming: t.string(db.ColOntslagbestemming),
+			Vervallen:         t.bool(db.ColVervallen),
+		}
+		if v := t.time(db.ColAangemeld); v != nil {
+			rec.Aangemeld = *v
+		}
+		if v := t.time(db.ColGeboortedatum); v != nil {
+			rec.Geboortedatum = *v
+		}
+		res = append(res, rec)
+	}
+}
+
+// ReadRadiologie reads radiology orders from a CSV file with the columns of the radiology query.
+func ReadRadiologie(r io.Reader, d Dialect, loc *time.Location) (db.RadiologieOrders, error) {
+	t, err := newTable(r, d, loc, db.RadiologieColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	var res db.RadiologieOrders
+	for {
+		ok, err := t.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return res, nil
+		}
+		res = append(res, db.RadiologieOrder{
+			Bezoeknummer: t.int(db.ColBezoeknummer),
+			Ordernummer:  t.int(db.ColOrderNummer),
+			Status:       t.string(db.ColOrderStatus),
+			Start:        t.time(db.ColOrderStart),
+			Eind:         t.time(db.ColOrderEind),
+			Module:       t.string(db.ColOrderModule),
+		})
+	}
+}
+
+// ReadLab reads lab orders from a CSV file with the columns of the lab query.
+func ReadLab(r io.Reader, d Dialect, loc *time.Location) (db.LabOrders, error) {
+	t, err := newTable(r, d, loc, db.LabColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	var res db.LabOrders
+	for {
+		ok, err := t.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return res, nil
+		}
+		res = append(res, db.LabOrder{
+			Bezoeknummer: t.int(db.ColBezoeknummer),
+			Ordernummer:  t.int(db.ColOrderNummer),
+			Status:       t.string(db.ColOrderStatus),
+			Start:        t.time(db.ColOrderStart),
+			Eind:         t.time(db.ColOrderEind),
+		})
+	}
+}
+
+// ReadConsult reads consult orders from a CSV file with the columns of the consult query.
+func ReadConsult(r io.Reader, d Dialect, loc *time.Location) (db.ConsultOrders, error) {
+	t, err := newTable(r, d, loc, db.ConsultColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	var res db.ConsultOrders
+	for {
+		ok, err := t.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return res, nil
+		}
+		res = append(res, db.ConsultOrder{
+			Bezoeknummer: t.int(db.ColBezoeknummer),
+			Ordernummer:  t.int(db.ColOrderNummer),
+			Status:       t.string(db.ColOrderStatus),
+			Start:        t.time(db.ColOrderStart),
+			Eind:         t.time(db.ColOrderEind),
+			Specialisme:  t.string(db.ColOrderSpecialisme),
+		})
+	}
+}
+
+// parseDelimiter returns the delimiter for a setting, which is a single character or "tab".
+func parseDelimiter(s string) (rune, error) {
+	switch s {
+	case "":
+		s = DefaultDelimiter
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, ErrInvalidDelimiter
+	}
+	return r, nil
+}
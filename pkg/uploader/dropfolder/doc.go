@@ -0,0 +1,3 @@
+// Package dropfolder reads visits and orders from CSV files that are exported to a folder, and moves them aside once
+// they have been processed.
+package dropfolder
@@ -0,0 +1,165 @@
+package dropfolder
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+)
+
+const visitsHeader = "sehid;sehmutid;locatie;afdeling;aangemaakt;binnenkomstdatum;binnenkomsttijd;triagetijd;naarkamertijd;eerstecontacttijd;artsklaartijd;gereedopnametijd;vertrektijd;eindtijd;mutatieeindtijd;mutatiestatus;kamer;bed;ingangsklacht;specialisme;triage;vervoerder;geboortedatum;opnameafdeling;opnamespecialisme;herkomst;ontslagbestemming;vervallen\n"
+
+func TestReadVisits(t *testing.T) {
+	d, err := Settings{}.Dialect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	csv := visitsHeader + "42;7;ZKH;seh;2020-03-01 10:14:00;2020-03-01 00:00:00;10:15;10:20:00;;;;;12:00;;;A;K3;2;buikpijn;CHI;U2;;1975-06-15 00:00:00;;;5;VPL;False\n"
+
+	recs, err := ReadVisits(strings.NewReader(csv), d, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := db.VisitorRecord{
+		Bezoeknummer:      42,
+		MutatieID:         7,
+		Locatie:           "ZKH",
+		Afdeling:          "seh",
+		Aangemeld:         time.Date(2020, 3, 1, 10, 14, 0, 0, time.UTC),
+		BinnenkomstDatum:  "2020-03-01",
+		BinnenkomstTijd:   "10:15",
+		TriageTijd:        "10:20",
+		VertrekTijd:       "12:00",
+		Mutatiestatus:     "A",
+		Kamer:             "K3",
+		Bed:               "2",
+		Ingangsklacht:     "buikpijn",
+		Specialisme:       "CHI",
+		Urgentie:          "U2",
+		Geboortedatum:     time.Date(1975, 6, 15, 0, 0, 0, 0, time.UTC),
+		Herkomst:          "5",
+		Ontslagbestemming: "VPL",
+	}
+	if len(recs) != 1 || recs[0] != want {
+		t.Errorf("ReadVisits() ==\n%+v\ngot\n%+v", want, recs)
+	}
+}
+
+func TestReadOrders(t *testing.T) {
+	d, err := Settings{Delimiter: ",", TimeLayout: "02-01-2006 15:04"}.Dialect()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		CSV     string
+		Line    int
+		Missing bool
+	}{
+		"valid":          {CSV: "Status,SEHID,ORDERNR,StartDatumTijd,EindDatumTijd\nklaar,1,2,01-03-2020 10:00,\n"},
+		"column order":   {CSV: "ordernr,sehid,status,startdatumtijd,einddatumtijd\n2,1,klaar,,01-03-2020 11:00\n"},
+		"missing column": {CSV: "SEHID,ORDERNR,Status\n1,2,klaar\n", Missing: true},
+		"invalid number": {CSV: "SEHID,ORDERNR,Status,StartDatumTijd,EindDatumTijd\n1,2,klaar,,\nx,3,klaar,,\n", Line: 3},
+		"invalid time":   {CSV: "SEHID,ORDERNR,Status,StartDatumTijd,EindDatumTijd\n1,2,klaar,2020-03-01 10:00:00,\n", Line: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			recs, err := ReadLab(strings.NewReader(test.CSV), d, time.UTC)
+
+			var selectionErr *db.SelectionError
+			if errors.As(err, &selectionErr) != test.Missing {
+				t.Fatalf("Missing columns == %v, got %v", test.Missing, err)
+			}
+			var lineErr *LineError
+			if errors.As(err, &lineErr) != (test.Line > 0) || (lineErr != nil && lineErr.Line != test.Line) {
+				t.Fatalf("Error on line %d, got %v", test.Line, err)
+			}
+			if err == nil && (len(recs) != 1 || recs[0].Bezoeknummer != 1 || recs[0].Ordernummer != 2) {
+				t.Errorf("ReadLab() == order 2 of visit 1, got %+v", recs)
+			}
+		})
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	for s, want := range map[string]rune{"": ';', ",": ',', "tab": '\t', "|": '|'} {
+		if got, err := parseDelimiter(s); err != nil || got != want {
+			t.Errorf("parseDelimiter(%q) == %q, got %q, %v", s, want, got, err)
+		}
+	}
+	for _, s := range []string{";;", `"`, "\n"} {
+		if _, err := parseDelimiter(s); err != ErrInvalidDelimiter {
+			t.Errorf("parseDelimiter(%q) == %v, got %v", s, ErrInvalidDelimiter, err)
+		}
+	}
+}
+
+func TestFolder(t *testing.T) {
+	s := Settings{Folder: t.TempDir()}
+	if err := s.Check(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	old := now.Add(-time.Minute)
+
+	files := map[string]string{
+		"bezoeken-1.csv": visitsHeader + "1;1;ZKH;seh;;;;;;;;;;;;;;;;;;;;;;;;\n",
+		"bezoeken-2.CSV": visitsHeader,
+		"export.csv":     visitsHeader,
+		"lab-1.csv":      "SEHID;ORDERNR\n",
+		"readme.txt":     "",
+		"radiologie.csv": "",
+	}
+	for name, content := range files {
+		file := filepath.Join(s.Folder, name)
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if name != "radiologie.csv" {
+			if err := os.Chtimes(file, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	pending, err := Pending(s.Folder, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(pending, " ") != "bezoeken-1.csv bezoeken-2.CSV export.csv lab-1.csv" {
+		t.Fatalf("Pending() == [bezoeken-1.csv bezoeken-2.CSV export.csv lab-1.csv], got %v", pending)
+	}
+
+	for _, name := range pending {
+		b, err := Read(s, name, time.UTC)
+		if err != nil {
+			if err := MarkFailed(s.Folder, name, err, now); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if name == "bezoeken-1.csv" && b.Len() != 1 {
+			t.Errorf("Read(%s) == 1 record, got %d", name, b.Len())
+		}
+		if err := MarkProcessed(s.Folder, name, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, file := range []string{
+		"processed/bezoeken-1.csv",
+		"processed/bezoeken-2.CSV",
+		"failed/export.csv",
+		"failed/export.csv.error.txt",
+		"failed/lab-1.csv",
+		"failed/lab-1.csv.error.txt",
+		"radiologie.csv",
+	} {
+		if _, err := os.Stat(filepath.Join(s.Folder, file)); err != nil {
+			t.Errorf("Expected %s: %v", file, err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package dropfolder
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+)
+
+const (
+	// ProcessedFolder receives files that have been uploaded.
+	ProcessedFolder = "processed"
+	// FailedFolder receives files that could not be read, together with an error report.
+	FailedFolder = "failed"
+
+	// MinAge is the time a file must be left unmodified before it is read, such that files are not read while they
+	// are being written.
+	MinAge = 10 * time.Second
+)
+
+// Datasets are recognized by the start of the file name, such as bezoeken-20200301.csv.
+const (
+	DatasetVisits     = "bezoeken"
+	DatasetRadiologie = "radiologie"
+	DatasetLab        = "lab"
+	DatasetConsult    = "consult"
+)
+
+var datasets = []string{DatasetVisits, DatasetRadiologie, DatasetLab, DatasetConsult}
+
+// ErrUnknownDataset indicates that the file name does not start with the name of a dataset.
+var ErrUnknownDataset = fmt.Errorf("file name does not start with %s", strings.Join(datasets, ", "))
+
+// Settings contain the location of the drop folder, and the dialect of the files in it.
+type Settings struct {
+	Folder     string `json:"folder,omitempty"`
+	Delimiter  string `json:"delimiter,omitempty"`
+	TimeLayout string `json:"time_layout,omitempty"`
+}
+
+// Dialect returns the dialect of the files, using the defaults for settings that are not set.
+func (s Settings) Dialect() (Dialect, error) {
+	d := Dialect{TimeLayout: s.TimeLayout}
+	if d.TimeLayout == "" {
+		d.TimeLayout = DefaultTimeLayout
+	}
+	var err error
+	d.Delimiter, err = parseDelimiter(s.Delimiter)
+	return d, err
+}
+
+// Check returns an error if the folder does not exist, or if the files in it cannot be moved aside.
+func (s Settings) Check() error {
+	if _, err := s.Dialect(); err != nil {
+		return err
+	}
+	info, err := os.Stat(s.Folder)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a folder", s.Folder)
+	}
+	for _, sub := range []string{ProcessedFolder, FailedFolder} {
+		if err := os.MkdirAll(filepath.Join(s.Folder, sub), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Batch contains the records read from a single file. Only the records of its dataset are set.
+type Batch struct {
+	Dataset    string
+	Visits     db.VisitorRecords
+	Radiologie db.RadiologieOrders
+	Lab        db.LabOrders
+	Consult    db.ConsultOrders
+}
+
+// Len returns the number of records in the batch.
+func (b *Batch) Len() int {
+	return len(b.Visits) + len(b.Radiologie) + len(b.Lab) + len(b.Consult)
+}
+
+// Dataset returns the dataset of a file, based on its name.
+func Dataset(name string) (string, error) {
+	name = strings.ToLower(filepath.Base(name))
+	for _, d := range datasets {
+		if strings.HasPrefix(name, d) {
+			return d, nil
+		}
+	}
+	return "", ErrUnknownDataset
+}
+
+// Pending returns the names of the CSV files in folder that are ready to be read, in alphabetical order.
+func Pending(folder string, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".csv") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		if now.Sub(info.ModTime()) < MinAge {
+			continue
+		}
+		res = append(res, e.Name())
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// Read reads a file from the drop folder.
+func Read(s Settings, name string, loc *time.Location) (*Batch, error) {
+	dataset, err := Dataset(name)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.Dialect()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(s.Folder, name))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	b := &Batch{Dataset: dataset}
+	switch dataset {
+	case DatasetVisits:
+		b.Visits, err = ReadVisits(f, d, loc)
+	case DatasetRadiologie:
+		b.Radiologie, err = ReadRadiologie(f, d, loc)
+	case DatasetLab:
+		b.Lab, err = ReadLab(f, d, loc)
+	case DatasetConsult:
+		b.Consult, err = ReadConsult(f, d, loc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarkProcessed moves a file to the processed folder.
+func MarkProcessed(folder, name string, now time.Time) error {
+	_, err := move(folder, name, ProcessedFolder, now)
+	return err
+}
+
+// MarkFailed moves a file to the failed folder, and writes a report with the cause next to it.
+func MarkFailed(folder, name string, cause error, now time.Time) error {
+	target, err := move(folder, name, FailedFolder, now)
+	if err != nil {
+		return err
+	}
+	report := fmt.Sprintf("File:  %s\r\nTime:  %s\r\nError: %v\r\n", name, now.Format(time.RFC3339), cause)
+	return os.WriteFile(target+".error.txt", []byte(report), 0644)
+}
+
+// move moves a file into a subfolder. If a file with the same name was moved before, a timestamp is added to the name.
+// It returns the new path.
+func move(folder, name, sub string, now time.Time) (string, error) {
+	dir := filepath.Join(folder, sub)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(dir, name)
+	if _, err := os.Stat(target); err == nil {
+		ext := filepath.Ext(name)
+		target = filepath.Join(dir, strings.TrimSuffix(name, ext)+"-"+now.Format("20060102-150405")+ext)
+	}
+	if err := os.Rename(filepath.Join(folder, name), target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
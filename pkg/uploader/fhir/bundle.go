@@ -0,0 +1,154 @@
+package fhir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
+)
+
+// ContentType is the media type of FHIR resources in JSON.
+const ContentType = "application/fhir+json"
+
+// MaxBundleSize is the maximum number of entries in a single transaction bundle.
+const MaxBundleSize = 500
+
+// Bundle is a FHIR R4 Bundle.
+type Bundle struct {
+	ResourceType string        `json:"resourceType"`
+	Type         string        `json:"type"`
+	Link         []BundleLink  `json:"link,omitempty"`
+	Entry        []BundleEntry `json:"entry,omitempty"`
+}
+
+type BundleLink struct {
+	Relation string `json:"relation"`
+	URL      string `json:"url"`
+}
+
+type BundleEntry struct {
+	FullURL  string          `json:"fullUrl,omitempty"`
+	Resource json.RawMessage `json:"resource,omitempty"`
+	Request  *BundleRequest  `json:"request,omitempty"`
+	Response *BundleResponse `json:"response,omitempty"`
+}
+
+type BundleRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type BundleResponse struct {
+	Status   string          `json:"status"`
+	Location string          `json:"location,omitempty"`
+	Outcome  json.RawMessage `json:"outcome,omitempty"`
+}
+
+// Transaction returns transaction bundles that create or update the resources, with at most MaxBundleSize entries
+// each. Resources are updated by their logical id, so sending the same resources again has no effect.
+func Transaction(resources []Resource) ([]*Bundle, error) {
+	var res []*Bundle
+	for len(resources) > 0 {
+		n := len(resources)
+		if n > MaxBundleSize {
+			n = MaxBundleSize
+		}
+
+		b := &Bundle{ResourceType: "Bundle", Type: "transaction"}
+		for _, r := range resources[:n] {
+			bs, err := json.Marshal(r)
+			if err != nil {
+				return nil, err
+			}
+			url := r.ResourceType() + "/" + r.ResourceID()
+			b.Entry = append(b.Entry, BundleEntry{
+				Resource: bs,
+				Request:  &BundleRequest{Method: http.MethodPut, URL: url},
+			})
+		}
+		res = append(res, b)
+		resources = resources[n:]
+	}
+	return res, nil
+}
+
+// OutcomeError indicates that the FHIR server rejected a bundle.
+type OutcomeError struct {
+	StatusCode int
+	Issues     []string
+}
+
+func (e *OutcomeError) Error() string {
+	if len(e.Issues) == 0 {
+		return fmt.Sprintf("FHIR server returned HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("FHIR server returned HTTP %d: %s", e.StatusCode, strings.Join(e.Issues, "; "))
+}
+
+// operationOutcome is the part of a FHIR OperationOutcome needed to report errors.
+type operationOutcome struct {
+	Issue []struct {
+		Severity    string `json:"severity"`
+		Code        string `json:"code"`
+		Diagnostics string `json:"diagnostics"`
+		Details     struct {
+			Text string `json:"text"`
+		} `json:"details"`
+	} `json:"issue"`
+}
+
+// outcomeError returns the error for an unsuccessful response, including the issues of the operation outcome in the
+// body, if any.
+func outcomeError(statusCode int, body []byte) *OutcomeError {
+	res := &OutcomeError{StatusCode: statusCode}
+	var outcome operationOutcome
+	if json.Unmarshal(body, &outcome) == nil {
+		for _, issue := range outcome.Issue {
+			msg := issue.Diagnostics
+			if msg == "" {
+				msg = issue.Details.Text
+			}
+			res.Issues = append(res.Issues, fmt.Sprintf("%s %s: %s", issue.Severity, issue.Code, msg))
+		}
+	}
+	return res
+}
+
+// Send posts a transaction bundle to the base URL of a FHIR server, and returns the transaction response.
+func Send(ctx context.Context, client *rest.Client, opts rest.Options, base string, b *Bundle) (*Bundle, error) {
+	bs, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(base, "/"), bytes.NewReader(bs))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set("Accept", ContentType)
+
+	res, err := client.Do(ctx, opts, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 16<<20))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, outcomeError(res.StatusCode, body)
+	}
+
+	var resp Bundle
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid transaction response: %w", err)
+	}
+	return &resp, nil
+}
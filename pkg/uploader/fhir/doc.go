@@ -0,0 +1,3 @@
+// Package fhir converts visits and orders to FHIR R4 resources, and sends them to a FHIR server as transaction
+// bundles. It also reads encounters from a FHIR server, as a source of visits.
+package fhir
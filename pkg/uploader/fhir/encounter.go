@@ -0,0 +1,211 @@
+package fhir
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
+)
+
+// Encounter is a FHIR R4 Encounter, limited to the elements produced for emergency department visits.
+type Encounter struct {
+	ID              string                    `json:"id"`
+	Meta            *Meta                     `json:"meta,omitempty"`
+	Identifier      []Identifier              `json:"identifier"`
+	Status          string                    `json:"status"`
+	StatusHistory   []EncounterStatus         `json:"statusHistory,omitempty"`
+	Class           Coding                    `json:"class"`
+	ServiceType     *CodeableConcept          `json:"serviceType,omitempty"`
+	Priority        *CodeableConcept          `json:"priority,omitempty"`
+	Period          *Period                   `json:"period,omitempty"`
+	ReasonCode      []CodeableConcept         `json:"reasonCode,omitempty"`
+	Hospitalization *EncounterHospitalization `json:"hospitalization,omitempty"`
+	Location        []EncounterLocation       `json:"location,omitempty"`
+	ServiceProvider *Reference                `json:"serviceProvider,omitempty"`
+}
+
+type EncounterStatus struct {
+	Status string `json:"status"`
+	Period Period `json:"period"`
+}
+
+type EncounterHospitalization struct {
+	AdmitSource          *CodeableConcept `json:"admitSource,omitempty"`
+	Destination          *Reference       `json:"destination,omitempty"`
+	DischargeDisposition *CodeableConcept `json:"dischargeDisposition,omitempty"`
+}
+
+type EncounterLocation struct {
+	Location     Reference        `json:"location"`
+	Status       string           `json:"status,omitempty"`
+	PhysicalType *CodeableConcept `json:"physicalType,omitempty"`
+	Period       *Period          `json:"period,omitempty"`
+}
+
+func (e *Encounter) ResourceType() string {
+	return "Encounter"
+}
+
+func (e *Encounter) ResourceID() string {
+	return e.ID
+}
+
+// MarshalJSON adds the resource type.
+func (e *Encounter) MarshalJSON() ([]byte, error) {
+	type encounter Encounter
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		*encounter
+	}{e.ResourceType(), (*encounter)(e)})
+}
+
+// EncounterID returns the logical id of the encounter for a visit.
+func EncounterID(bezoeknummer int) string {
+	return "bezoek-" + strconv.Itoa(bezoeknummer)
+}
+
+// EncounterFromVisit converts a visit to an Encounter. The status history follows the process steps of the emergency
+// department: arrived until triage, triaged until the patient is seen by a physician, and in progress until departure.
+func EncounterFromVisit(v *rest.VisitorRecord) *Encounter {
+	e := &Encounter{
+		ID:         EncounterID(v.Bezoeknummer),
+		Identifier: []Identifier{{System: SystemVisit, Value: strconv.Itoa(v.Bezoeknummer)}},
+		Status:     encounterStatus(v),
+		Class:      Coding{System: systemActCode, Code: "EMER", Display: "emergency"},
+		Priority:   priority(v.Urgentie),
+		Period:     period(v.Binnenkomst, end(v)),
+		ReasonCode: localCodes("ingangsklacht", v.Ingangsklacht),
+	}
+	if v.Specialisme != "" {
+		e.ServiceType = localCode("specialisme", v.Specialisme)
+	}
+
+	steps := []struct {
+		status string
+		start  *time.Time
+	}{
+		{"arrived", v.Binnenkomst},
+		{"triaged", v.Triage},
+		{"in-progress", v.BijArts},
+		{"finished", end(v)},
+	}
+	for i, step := range steps[:len(steps)-1] {
+		if step.start == nil {
+			continue
+		}
+		var stepEnd *time.Time
+		for _, next := range steps[i+1:] {
+			if next.start != nil {
+				stepEnd = next.start
+				break
+			}
+		}
+		e.StatusHistory = append(e.StatusHistory, EncounterStatus{Status: step.status, Period: Period{Start: step.start, End: stepEnd}})
+	}
+
+	if v.Herkomst != "" || v.Ontslagbestemming != "" || v.OpnameAfdeling != "" {
+		e.Hospitalization = &EncounterHospitalization{
+			AdmitSource:          localCode("herkomst", v.Herkomst),
+			DischargeDisposition: localCode("ontslagbestemming", v.Ontslagbestemming),
+		}
+		if v.OpnameAfdeling != "" {
+			e.Hospitalization.Destination = &Reference{
+				Identifier: &Identifier{System: SystemCode + "afdeling", Value: v.OpnameAfdeling},
+				Display:    v.OpnameAfdeling,
+			}
+		}
+	}
+
+	if v.Locatie != "" {
+		e.ServiceProvider = &Reference{
+			Identifier: &Identifier{System: SystemCode + "locatie", Value: v.Locatie},
+			Display:    v.Locatie,
+		}
+	}
+	var location []string
+	for _, l := range []string{v.Afdeling, v.Kamer, v.Bed} {
+		if l != "" {
+			location = append(location, l)
+		}
+	}
+	if len(location) > 0 {
+		status := "active"
+		if e.Status == "finished" {
+			status = "completed"
+		}
+		e.Location = []EncounterLocation{{
+			Location: Reference{
+				Identifier: &Identifier{System: SystemCode + "locatie", Value: strings.Join(append([]string{v.Locatie}, location...), "/")},
+				Display:    strings.Join(location, " "),
+			},
+			Status: status,
+			Period: period(v.NaarKamer, v.Vertrek),
+		}}
+	}
+	return e
+}
+
+// end returns the time the visit ended, if it has.
+func end(v *rest.VisitorRecord) *time.Time {
+	if v.Vertrek != nil {
+		return v.Vertrek
+	}
+	return v.Einde
+}
+
+func encounterStatus(v *rest.VisitorRecord) string {
+	switch {
+	case v.Vervallen:
+		return "entered-in-error"
+	case end(v) != nil:
+		return "finished"
+	case v.BijArts != nil:
+		return "in-progress"
+	case v.Triage != nil:
+		return "triaged"
+	default:
+		return "arrived"
+	}
+}
+
+// priority maps the urgency of the Manchester Triage System, 1 (immediate) to 5 (non-urgent), to the HL7 act
+// priority. The original code is always included.
+func priority(urgentie string) *CodeableConcept {
+	c := localCode("urgentie", urgentie)
+	if c == nil {
+		return nil
+	}
+
+	var code, display string
+	switch strings.TrimLeft(strings.ToUpper(urgentie), "UM") {
+	case "1", "2":
+		code, display = "EM", "emergency"
+	case "3":
+		code, display = "UR", "urgent"
+	case "4", "5":
+		code, display = "R", "routine"
+	}
+	if code != "" {
+		c.Coding = append(c.Coding, Coding{System: systemActPriority, Code: code, Display: display})
+	}
+	return c
+}
+
+// EncountersFromVisits converts visits to encounters. Multiple mutations of the same visit result in one encounter,
+// with the state of the last mutation.
+func EncountersFromVisits(vs []rest.VisitorRecord) []Resource {
+	index := make(map[int]int)
+	var res []Resource
+	for i := range vs {
+		e := EncounterFromVisit(&vs[i])
+		if j, ok := index[vs[i].Bezoeknummer]; ok {
+			res[j] = e
+			continue
+		}
+		index[vs[i].Bezoeknummer] = len(res)
+		res = append(res, e)
+	}
+	return res
+}
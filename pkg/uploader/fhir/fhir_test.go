@@ -0,0 +1,247 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
+)
+
+func at(hour, min int) *time.Time {
+	t := time.Date(2020, 3, 1, hour, min, 0, 0, time.UTC)
+	return &t
+}
+
+func TestEncounterFromVisit(t *testing.T) {
+	for name, test := range map[string]struct {
+		Visit    rest.VisitorRecord
+		Status   string
+		History  []string
+		Priority string
+	}{
+		"arrived":   {Visit: rest.VisitorRecord{Binnenkomst: at(10, 0)}, Status: "arrived", History: []string{"arrived"}},
+		"triaged":   {Visit: rest.VisitorRecord{Binnenkomst: at(10, 0), Triage: at(10, 5), Urgentie: "U2"}, Status: "triaged", History: []string{"arrived", "triaged"}, Priority: "EM"},
+		"seen":      {Visit: rest.VisitorRecord{Binnenkomst: at(10, 0), BijArts: at(10, 30), Urgentie: "3"}, Status: "in-progress", History: []string{"arrived", "in-progress"}, Priority: "UR"},
+		"finished":  {Visit: rest.VisitorRecord{Binnenkomst: at(10, 0), Triage: at(10, 5), BijArts: at(10, 30), Vertrek: at(12, 0), Urgentie: "5"}, Status: "finished", History: []string{"arrived", "triaged", "in-progress"}, Priority: "R"},
+		"cancelled": {Visit: rest.VisitorRecord{Binnenkomst: at(10, 0), Vervallen: true}, Status: "entered-in-error", History: []string{"arrived"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			e := EncounterFromVisit(&test.Visit)
+			if e.Status != test.Status {
+				t.Errorf("Status == %s, got %s", test.Status, e.Status)
+			}
+			var history []string
+			for _, s := range e.StatusHistory {
+				history = append(history, s.Status)
+			}
+			if len(history) != len(test.History) {
+				t.Fatalf("StatusHistory == %v, got %v", test.History, history)
+			}
+			for i := range history {
+				if history[i] != test.History[i] {
+					t.Errorf("StatusHistory == %v, got %v", test.History, history)
+				}
+			}
+
+			var priority string
+			if e.Priority != nil && len(e.Priority.Coding) > 1 {
+				priority = e.Priority.Coding[1].Code
+			}
+			if priority != test.Priority {
+				t.Errorf("Priority == %q, got %q", test.Priority, priority)
+			}
+		})
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	visits := make([]rest.VisitorRecord, MaxBundleSize+1)
+	for i := range visits {
+		visits[i] = rest.VisitorRecord{Bezoeknummer: i, MutatieID: 1}
+	}
+	visits = append(visits, rest.VisitorRecord{Bezoeknummer: 0, MutatieID: 2, Vertrek: at(12, 0)})
+
+	bundles, err := Transaction(EncountersFromVisits(visits))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundles) != 2 || len(bundles[0].Entry) != MaxBundleSize || len(bundles[1].Entry) != 1 {
+		t.Fatalf("Transaction() == bundles of %d and 1, got %d bundles", MaxBundleSize, len(bundles))
+	}
+
+	entry := bundles[0].Entry[0]
+	if entry.Request.Method != http.MethodPut || entry.Request.URL != "Encounter/bezoek-0" {
+		t.Errorf("Request == PUT Encounter/bezoek-0, got %s %s", entry.Request.Method, entry.Request.URL)
+	}
+	var resource struct {
+		ResourceType string
+		ID           string
+		Status       string
+	}
+	if err := json.Unmarshal(entry.Resource, &resource); err != nil {
+		t.Fatal(err)
+	}
+	if resource.ResourceType != "Encounter" || resource.ID != "bezoek-0" || resource.Status != "finished" {
+		t.Errorf("Resource == finished Encounter bezoek-0, got %+v", resource)
+	}
+}
+
+func TestSend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b Bundle
+		if err := json.NewDecoder(r.Body).Decode(&b); err != nil || r.Header.Get("Content-Type") != ContentType {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if len(b.Entry) == 0 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"resourceType":"OperationOutcome","issue":[{"severity":"error","code":"invalid","diagnostics":"empty bundle"}]}`))
+			return
+		}
+		res := Bundle{ResourceType: "Bundle", Type: "transaction-response"}
+		for range b.Entry {
+			res.Entry = append(res.Entry, BundleEntry{Response: &BundleResponse{Status: "201 Created"}})
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer srv.Close()
+
+	client := rest.NewClient()
+	bundles, err := Transaction([]Resource{ServiceRequestFromOrder(OrderLab, &rest.OrderRecord{Bezoeknummer: 1, Ordernummer: 2})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Send(context.Background(), client, rest.Options{}, srv.URL, bundles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Entry) != 1 || res.Entry[0].Response.Status != "201 Created" {
+		t.Errorf("Send() == 1 created entry, got %+v", res)
+	}
+
+	_, err = Send(context.Background(), client, rest.Options{}, srv.URL, &Bundle{ResourceType: "Bundle", Type: "transaction"})
+	var outcomeErr *OutcomeError
+	if !errors.As(err, &outcomeErr) || len(outcomeErr.Issues) != 1 {
+		t.Errorf("Send() == OutcomeError with 1 issue, got %v", err)
+	}
+}
+
+func TestVisitorRecord(t *testing.T) {
+	v := rest.VisitorRecord{
+		Bezoeknummer:  42,
+		Locatie:       "ZKH",
+		Binnenkomst:   at(10, 0),
+		Triage:        at(10, 5),
+		BijArts:       at(10, 30),
+		Vertrek:       at(12, 0),
+		Urgentie:      "U2",
+		Specialisme:   "CHI",
+		Ingangsklacht: "buikpijn",
+		Herkomst:      "5",
+	}
+	e := EncounterFromVisit(&v)
+	e.Location = []EncounterLocation{
+		{Location: Reference{Display: "SEH"}, PhysicalType: &CodeableConcept{Coding: []Coding{{Code: "wa"}}}},
+		{Location: Reference{Display: "K3"}, PhysicalType: &CodeableConcept{Coding: []Coding{{Code: "ro"}}}, Period: period(at(10, 20), nil)},
+	}
+	updated := at(12, 1)
+	e.Meta = &Meta{VersionID: "3", LastUpdated: updated}
+
+	r, err := VisitorRecord(e, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := db.VisitorRecord{
+		Bezoeknummer:     42,
+		MutatieID:        r.MutatieID,
+		Locatie:          "ZKH",
+		Afdeling:         "SEH",
+		Kamer:            "K3",
+		Aangemeld:        *updated,
+		BinnenkomstDatum: "2020-03-01",
+		BinnenkomstTijd:  "10:00",
+		TriageTijd:       "10:05",
+		NaarKamerTijd:    "10:20",
+		BijArtsTijd:      "10:30",
+		VertrekTijd:      "12:00",
+		Mutatiestatus:    "finished",
+		Ingangsklacht:    "buikpijn",
+		Specialisme:      "CHI",
+		Urgentie:         "U2",
+		Herkomst:         "5",
+	}
+	if *r != want {
+		t.Errorf("VisitorRecord() ==\n%+v\ngot\n%+v", want, *r)
+	}
+
+	e.Meta.VersionID = "4"
+	if next, _ := VisitorRecord(e, time.UTC); next.MutatieID == r.MutatieID {
+		t.Errorf("MutatieID should differ between versions, got %d twice", r.MutatieID)
+	}
+
+	if _, err := VisitorRecord(&Encounter{ID: "abc"}, time.UTC); err != ErrNoVisitNumber {
+		t.Errorf("VisitorRecord() == %v, got %v", ErrNoVisitNumber, err)
+	}
+}
+
+func TestSearchEncounters(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/fhir/Encounter" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		encounter := func(id int, hour int) json.RawMessage {
+			bs, _ := json.Marshal(&Encounter{ID: EncounterID(id), Meta: &Meta{LastUpdated: at(hour, 0)}, Status: "arrived"})
+			return bs
+		}
+		b := Bundle{ResourceType: "Bundle", Type: "searchset"}
+		switch r.URL.Query().Get("page") {
+		case "":
+			if got := r.URL.Query().Get("_lastUpdated"); got != "gt2020-03-01T09:00:00Z" {
+				t.Errorf("_lastUpdated == gt2020-03-01T09:00:00Z, got %s", got)
+			}
+			b.Entry = []BundleEntry{{Resource: encounter(1, 10)}, {Resource: []byte(`{"resourceType":"OperationOutcome"}`)}}
+			b.Link = []BundleLink{{Relation: "self", URL: srv.URL + r.URL.String()}, {Relation: "next", URL: srv.URL + "/fhir/Encounter?page=2"}}
+		case "2":
+			b.Entry = []BundleEntry{{Resource: encounter(2, 11)}}
+		}
+		_ = json.NewEncoder(w).Encode(b)
+	}))
+	defer srv.Close()
+
+	client := rest.NewClient()
+	opts := rest.Options{Auth: SourceSettings{Auth: AuthBearer, Token: "secret"}.Authenticator()}
+
+	var ids []string
+	last, err := SearchEncounters(context.Background(), client, opts, srv.URL+"/fhir/", *at(9, 0), func(e *Encounter) error {
+		ids = append(ids, e.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "bezoek-1" || ids[1] != "bezoek-2" {
+		t.Errorf("SearchEncounters() == [bezoek-1 bezoek-2], got %v", ids)
+	}
+	if !last.Equal(*at(11, 0)) {
+		t.Errorf("SearchEncounters() == %v, got %v", at(11, 0), last)
+	}
+
+	_, err = SearchEncounters(context.Background(), client, rest.Options{}, srv.URL+"/fhir", *at(9, 0), func(*Encounter) error { return nil })
+	var outcomeErr *OutcomeError
+	if !errors.As(err, &outcomeErr) || outcomeErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("SearchEncounters() == OutcomeError 401, got %v", err)
+	}
+}
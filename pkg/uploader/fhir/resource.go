@@ -0,0 +1,85 @@
+package fhir
+
+import (
+	"time"
+)
+
+const (
+	// SystemVisit identifies visits by their visit number.
+	SystemVisit = "urn:door2doc:bezoeknummer"
+	// SystemOrder identifies orders by their order number.
+	SystemOrder = "urn:door2doc:ordernummer"
+	// SystemCode is the prefix of the code systems for the hospital specific codes, such as urgency and origin.
+	SystemCode = "urn:door2doc:code:"
+
+	systemActCode     = "http://terminology.hl7.org/CodeSystem/v3-ActCode"
+	systemActPriority = "http://terminology.hl7.org/CodeSystem/v3-ActPriority"
+	systemSNOMED      = "http://snomed.info/sct"
+)
+
+// Resource is a FHIR resource that can be part of a bundle.
+type Resource interface {
+	// ResourceType returns the FHIR resource type, such as Encounter.
+	ResourceType() string
+	// ResourceID returns the logical id of the resource.
+	ResourceID() string
+}
+
+type Meta struct {
+	VersionID   string     `json:"versionId,omitempty"`
+	LastUpdated *time.Time `json:"lastUpdated,omitempty"`
+}
+
+type Identifier struct {
+	System string `json:"system,omitempty"`
+	Value  string `json:"value"`
+}
+
+type Coding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code"`
+	Display string `json:"display,omitempty"`
+}
+
+type CodeableConcept struct {
+	Coding []Coding `json:"coding,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+
+type Reference struct {
+	Reference  string      `json:"reference,omitempty"`
+	Identifier *Identifier `json:"identifier,omitempty"`
+	Display    string      `json:"display,omitempty"`
+}
+
+type Period struct {
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// localCode returns a concept for a hospital specific code, or nil if the code is empty.
+func localCode(field, code string) *CodeableConcept {
+	if code == "" {
+		return nil
+	}
+	return &CodeableConcept{
+		Coding: []Coding{{System: SystemCode + field, Code: code}},
+		Text:   code,
+	}
+}
+
+// localCodes returns localCode as a list, which is empty if the code is empty.
+func localCodes(field, code string) []CodeableConcept {
+	if c := localCode(field, code); c != nil {
+		return []CodeableConcept{*c}
+	}
+	return nil
+}
+
+// period returns the period between start and end, or nil if both are unknown.
+func period(start, end *time.Time) *Period {
+	if start == nil && end == nil {
+		return nil
+	}
+	return &Period{Start: start, End: end}
+}
@@ -0,0 +1,148 @@
+package fhir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/password"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
+)
+
+const (
+	// AuthNone sends requests to the FHIR server without credentials.
+	AuthNone = ""
+	// AuthBasic authenticates to the FHIR server with a username and password.
+	AuthBasic = "basic"
+	// AuthBearer authenticates to the FHIR server with a fixed access token.
+	AuthBearer = "bearer"
+
+	// SearchPageSize is the number of encounters requested per page.
+	SearchPageSize = 100
+	// maxSearchPages limits the number of pages followed in a single search.
+	maxSearchPages = 1000
+)
+
+// ErrSearchLoop indicates that the FHIR server keeps returning next links.
+var ErrSearchLoop = errors.New("FHIR search returned too many pages")
+
+// SourceSettings contain the settings for reading encounters from a FHIR server.
+type SourceSettings struct {
+	// URL is the base URL of the FHIR server, such as https://ehr.example.com/fhir/r4.
+	URL      string            `json:"url,omitempty"`
+	Auth     string            `json:"auth,omitempty"`
+	Username string            `json:"username,omitempty"`
+	Password password.Password `json:"password,omitempty"`
+	Token    password.Password `json:"token,omitempty"`
+}
+
+// Authenticator returns the authenticator for requests to the FHIR server, or nil if requests are not authenticated.
+func (s SourceSettings) Authenticator() rest.Authenticator {
+	switch s.Auth {
+	case AuthBasic:
+		return rest.BasicAuth{Username: s.Username, Password: s.Password.PlainText()}
+	case AuthBearer:
+		return rest.BearerToken{Token: s.Token.PlainText()}
+	default:
+		return nil
+	}
+}
+
+// SearchEncounters searches the FHIR server at base for encounters updated after since, oldest first, and calls f for
+// each of them. All pages of the search result are followed. It returns the most recent update time seen, or since if
+// there were no encounters.
+func SearchEncounters(ctx context.Context, client *rest.Client, opts rest.Options, base string, since time.Time, f func(*Encounter) error) (time.Time, error) {
+	query := url.Values{}
+	query.Set("_lastUpdated", "gt"+since.UTC().Format(time.RFC3339))
+	query.Set("_sort", "_lastUpdated")
+	query.Set("_count", fmt.Sprint(SearchPageSize))
+	next := strings.TrimSuffix(base, "/") + "/Encounter?" + query.Encode()
+
+	last := since
+	for page := 0; next != ""; page++ {
+		if page == maxSearchPages {
+			return last, ErrSearchLoop
+		}
+
+		b, err := search(ctx, client, opts, next)
+		if err != nil {
+			return last, err
+		}
+
+		for _, entry := range b.Entry {
+			var e Encounter
+			if err := json.Unmarshal(entry.Resource, &e); err != nil {
+				return last, fmt.Errorf("invalid encounter %s: %w", entry.FullURL, err)
+			}
+			if err := f(&e); err != nil {
+				return last, err
+			}
+			if e.Meta != nil && e.Meta.LastUpdated != nil && e.Meta.LastUpdated.After(last) {
+				last = *e.Meta.LastUpdated
+			}
+		}
+
+		next = ""
+		for _, l := range b.Link {
+			if l.Relation == "next" {
+				next = l.URL
+			}
+		}
+	}
+	return last, nil
+}
+
+// CheckSearch requests a single encounter from the FHIR server at base, to check whether it can be searched.
+func CheckSearch(ctx context.Context, client *rest.Client, opts rest.Options, base string) error {
+	_, err := search(ctx, client, opts, strings.TrimSuffix(base, "/")+"/Encounter?_count=1")
+	return err
+}
+
+// search requests a single page of search results. Included resources, such as OperationOutcomes, are skipped.
+func search(ctx context.Context, client *rest.Client, opts rest.Options, u string) (*Bundle, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", ContentType)
+
+	res, err := client.Do(ctx, opts, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 64<<20))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, outcomeError(res.StatusCode, body)
+	}
+
+	var b Bundle
+	if err := json.Unmarshal(body, &b); err != nil {
+		return nil, fmt.Errorf("invalid search response: %w", err)
+	}
+	if b.ResourceType != "Bundle" || b.Type != "searchset" {
+		return nil, fmt.Errorf("invalid search response: expected searchset bundle, got %s %s", b.ResourceType, b.Type)
+	}
+
+	entries := b.Entry[:0]
+	for _, entry := range b.Entry {
+		var r struct {
+			ResourceType string `json:"resourceType"`
+		}
+		if err := json.Unmarshal(entry.Resource, &r); err == nil && r.ResourceType == "Encounter" {
+			entries = append(entries, entry)
+		}
+	}
+	b.Entry = entries
+	return &b, nil
+}
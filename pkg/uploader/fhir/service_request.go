@@ -0,0 +1,99 @@
+package fhir
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
+)
+
+// OrderKind distinguishes the order queries.
+type OrderKind string
+
+const (
+	OrderRadiology OrderKind = "radiologie"
+	OrderLab       OrderKind = "lab"
+	OrderConsult   OrderKind = "consult"
+)
+
+// category returns the ServiceRequest category of the kind of order.
+func (k OrderKind) category() CodeableConcept {
+	switch k {
+	case OrderRadiology:
+		return CodeableConcept{Coding: []Coding{{System: systemSNOMED, Code: "363679005", Display: "Imaging"}}}
+	case OrderLab:
+		return CodeableConcept{Coding: []Coding{{System: systemSNOMED, Code: "108252007", Display: "Laboratory procedure"}}}
+	default:
+		return CodeableConcept{Coding: []Coding{{System: systemSNOMED, Code: "11429006", Display: "Consultation"}}}
+	}
+}
+
+// ServiceRequest is a FHIR R4 ServiceRequest, limited to the elements produced for orders.
+type ServiceRequest struct {
+	ID               string            `json:"id"`
+	Identifier       []Identifier      `json:"identifier"`
+	Status           string            `json:"status"`
+	Intent           string            `json:"intent"`
+	Category         []CodeableConcept `json:"category"`
+	Code             *CodeableConcept  `json:"code,omitempty"`
+	Subject          Reference         `json:"subject"`
+	Encounter        *Reference        `json:"encounter,omitempty"`
+	OccurrencePeriod *Period           `json:"occurrencePeriod,omitempty"`
+	PerformerType    *CodeableConcept  `json:"performerType,omitempty"`
+	Note             []Annotation      `json:"note,omitempty"`
+}
+
+type Annotation struct {
+	Text string `json:"text"`
+}
+
+func (s *ServiceRequest) ResourceType() string {
+	return "ServiceRequest"
+}
+
+func (s *ServiceRequest) ResourceID() string {
+	return s.ID
+}
+
+// MarshalJSON adds the resource type.
+func (s *ServiceRequest) MarshalJSON() ([]byte, error) {
+	type serviceRequest ServiceRequest
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		*serviceRequest
+	}{s.ResourceType(), (*serviceRequest)(s)})
+}
+
+// ServiceRequestFromOrder converts an order to a ServiceRequest for the encounter of its visit. Visits are exported
+// without patient data, so the subject only identifies the visit.
+func ServiceRequestFromOrder(kind OrderKind, o *rest.OrderRecord) *ServiceRequest {
+	encounter := &Reference{Reference: "Encounter/" + EncounterID(o.Bezoeknummer)}
+	s := &ServiceRequest{
+		ID:               string(kind) + "-" + strconv.Itoa(o.Ordernummer),
+		Identifier:       []Identifier{{System: SystemOrder + ":" + string(kind), Value: strconv.Itoa(o.Ordernummer)}},
+		Status:           "active",
+		Intent:           "order",
+		Category:         []CodeableConcept{kind.category()},
+		Code:             localCode(string(kind)+":module", o.Module),
+		Subject:          Reference{Identifier: &Identifier{System: SystemVisit, Value: strconv.Itoa(o.Bezoeknummer)}, Display: "Bezoek " + strconv.Itoa(o.Bezoeknummer)},
+		Encounter:        encounter,
+		OccurrencePeriod: period(o.Start, o.Eind),
+		PerformerType:    localCode("specialisme", o.Specialisme),
+	}
+	if o.Eind != nil {
+		s.Status = "completed"
+	}
+	if o.Status != "" {
+		s.Note = []Annotation{{Text: "Status: " + o.Status}}
+	}
+	return s
+}
+
+// ServiceRequestsFromOrders converts orders to service requests.
+func ServiceRequestsFromOrders(kind OrderKind, os []rest.OrderRecord) []Resource {
+	res := make([]Resource, len(os))
+	for i := range os {
+		res[i] = ServiceRequestFromOrder(kind, &os[i])
+	}
+	return res
+}
@@ -0,0 +1,154 @@
+package fhir
+
+import (
+	"errors"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+)
+
+// ErrNoVisitNumber indicates that an encounter has no numeric identifier.
+var ErrNoVisitNumber = errors.New("encounter does not have a numeric identifier")
+
+// VisitorRecord maps an encounter to a visitor record, such that encounters read from a FHIR server can be uploaded
+// like the results of the visitor query. Times are converted to loc.
+//
+// The visit number is the identifier in SystemVisit, or else the first numeric identifier. The status history provides
+// the triage and physician times, and the physical type of each location determines whether it is a ward, room or bed.
+// The birth date is not available, as it is part of the patient.
+func VisitorRecord(e *Encounter, loc *time.Location) (*db.VisitorRecord, error) {
+	visit, ok := visitNumber(e)
+	if !ok {
+		return nil, ErrNoVisitNumber
+	}
+
+	r := &db.VisitorRecord{
+		Bezoeknummer:  visit,
+		MutatieID:     mutationID(e),
+		Mutatiestatus: e.Status,
+		Vervallen:     e.Status == "entered-in-error" || e.Status == "cancelled",
+		Specialisme:   code(e.ServiceType, ""),
+		Urgentie:      code(e.Priority, SystemCode+"urgentie"),
+	}
+	if len(e.ReasonCode) > 0 {
+		r.Ingangsklacht = code(&e.ReasonCode[0], "")
+	}
+	if e.ServiceProvider != nil {
+		r.Locatie = referenceCode(e.ServiceProvider)
+	}
+	if h := e.Hospitalization; h != nil {
+		r.Herkomst = code(h.AdmitSource, "")
+		r.Ontslagbestemming = code(h.DischargeDisposition, "")
+		if h.Destination != nil {
+			r.OpnameAfdeling = referenceCode(h.Destination)
+		}
+	}
+	if e.Meta != nil && e.Meta.LastUpdated != nil {
+		r.Aangemeld = e.Meta.LastUpdated.In(loc)
+	}
+
+	if e.Period != nil {
+		if t := e.Period.Start; t != nil {
+			r.BinnenkomstDatum = t.In(loc).Format("2006-01-02")
+			r.BinnenkomstTijd = clock(t, loc)
+		}
+		r.VertrekTijd = clock(e.Period.End, loc)
+	}
+	for _, s := range e.StatusHistory {
+		switch s.Status {
+		case "triaged":
+			r.TriageTijd = clock(s.Period.Start, loc)
+		case "in-progress":
+			r.BijArtsTijd = clock(s.Period.Start, loc)
+		}
+	}
+
+	for i, l := range e.Location {
+		name := referenceCode(&l.Location)
+		switch code(l.PhysicalType, "") {
+		case "wa":
+			r.Afdeling = name
+		case "ro":
+			r.Kamer = name
+		case "bd":
+			r.Bed = name
+		case "":
+			if i == 0 {
+				r.Afdeling = name
+			}
+		}
+		if l.Period != nil && l.Period.Start != nil && r.NaarKamerTijd == "" && code(l.PhysicalType, "") != "wa" {
+			r.NaarKamerTijd = clock(l.Period.Start, loc)
+		}
+	}
+	return r, nil
+}
+
+// visitNumber returns the visit number of an encounter.
+func visitNumber(e *Encounter) (int, bool) {
+	for _, id := range e.Identifier {
+		if id.System == SystemVisit {
+			n, err := strconv.Atoi(id.Value)
+			return n, err == nil
+		}
+	}
+	for _, id := range e.Identifier {
+		if n, err := strconv.Atoi(id.Value); err == nil {
+			return n, true
+		}
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(e.ID, "bezoek-"))
+	return n, err == nil
+}
+
+// mutationID returns a positive number that identifies this version of the encounter.
+func mutationID(e *Encounter) int {
+	version := ""
+	if e.Meta != nil {
+		version = e.Meta.VersionID
+		if version == "" && e.Meta.LastUpdated != nil {
+			version = e.Meta.LastUpdated.Format(time.RFC3339Nano)
+		}
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(e.ID + "/" + version))
+	return int(h.Sum32() & 0x7fffffff)
+}
+
+// code returns the code in system, or else the first code or the text of a concept.
+func code(c *CodeableConcept, system string) string {
+	if c == nil {
+		return ""
+	}
+	for _, coding := range c.Coding {
+		if system == "" || coding.System == system {
+			return coding.Code
+		}
+	}
+	if len(c.Coding) > 0 {
+		return c.Coding[0].Code
+	}
+	return c.Text
+}
+
+// referenceCode returns the identifier or name of a referenced resource.
+func referenceCode(r *Reference) string {
+	if r.Identifier != nil && r.Identifier.Value != "" {
+		return r.Identifier.Value
+	}
+	if r.Display != "" {
+		return r.Display
+	}
+	return r.Reference
+}
+
+// clock returns the time of day of t in loc, or an empty string if t is nil.
+func clock(t *time.Time, loc *time.Location) string {
+	if t == nil {
+		return ""
+	}
+	return t.In(loc).Format("15:04")
+}
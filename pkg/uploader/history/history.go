@@ -7,10 +7,17 @@ import (
 
 const MaxHistory = 10
 
+// Phases of an event that is in progress. The phase of an event that has finished is empty.
+const (
+	PhaseQuerying  = "querying"
+	PhaseUploading = "uploading"
+)
+
 // History keeps track of recent events.
 type History struct {
-	mu     sync.Mutex
-	events []*Event
+	mu          sync.Mutex
+	events      []*Event
+	subscribers map[chan struct{}]bool
 }
 
 func New() *History {
@@ -21,6 +28,7 @@ func New() *History {
 type Event struct {
 	Type           string
 	Time           time.Time
+	Phase          string
 	QueryDuration  time.Duration
 	UploadDuration time.Duration
 	Size           int
@@ -28,25 +36,88 @@ type Event struct {
 	Error          error
 }
 
+// NewEvent adds an event that is in progress, querying data. Call Finish when it is done.
 func (h *History) NewEvent(typ string) *Event {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	e := &Event{Time: time.Now(), Type: typ}
+	e := &Event{Time: time.Now(), Type: typ, Phase: PhaseQuerying}
 
 	h.events = append([]*Event{e}, h.events...)
 	if len(h.events) > MaxHistory {
 		h.events = h.events[:MaxHistory]
 	}
 
+	h.notify()
 	return e
 }
 
-func (h *History) Events() []*Event {
+// SetPhase updates the phase of an event that is in progress.
+func (h *History) SetPhase(e *Event, phase string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	res := make([]*Event, len(h.events))
-	copy(res, h.events)
+	e.Phase = phase
+	h.notify()
+}
+
+// Finish marks an event as done.
+func (h *History) Finish(e *Event) {
+	h.SetPhase(e, "")
+}
+
+// Events returns copies of the recent events, most recent first.
+func (h *History) Events() []Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	res := make([]Event, len(h.events))
+	for i, e := range h.events {
+		res[i] = *e
+	}
 	return res
 }
+
+// Running returns copies of the events that are in progress, most recent first.
+func (h *History) Running() []Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var res []Event
+	for _, e := range h.events {
+		if e.Phase != "" {
+			res = append(res, *e)
+		}
+	}
+	return res
+}
+
+// Subscribe returns a channel that receives a value when events are added or change phase. Notifications are
+// coalesced if the subscriber is busy. Call cancel to stop receiving notifications.
+func (h *History) Subscribe() (notifications <-chan struct{}, cancel func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := make(chan struct{}, 1)
+	if h.subscribers == nil {
+		h.subscribers = make(map[chan struct{}]bool)
+	}
+	h.subscribers[c] = true
+
+	return c, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.subscribers, c)
+	}
+}
+
+// notify signals all subscribers. It must be called with h.mu held.
+func (h *History) notify() {
+	for c := range h.subscribers {
+		select {
+		case c <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -14,24 +14,29 @@ func TestHistory_Events(t *testing.T) {
 		if e1 == e2 {
 			t.Fatal("did not create new event")
 		}
-		want := []*Event{e2, e1}
+		want := []Event{*e2, *e1}
 		got := h.Events()
 
 		if !reflect.DeepEqual(got, want) {
 			t.Errorf("Events() == %v, got %v", want, got)
 		}
+
+		got[0].Size = 42
+		if e2.Size != 0 {
+			t.Errorf("Events() returns copies, got the events themselves")
+		}
 	})
 
 	t.Run("eleven events", func(t *testing.T) {
 		h := New()
-		var want []*Event
+		var want []Event
 		for i := 0; i <= MaxHistory; i++ {
 			e := h.NewEvent("x")
 			if i == 0 {
 				// first event should be filtered out
 				continue
 			}
-			want = append([]*Event{e}, want...)
+			want = append([]Event{*e}, want...)
 		}
 
 		got := h.Events()
@@ -63,3 +68,38 @@ func TestHistory_Events(t *testing.T) {
 		}
 	})
 }
+
+func TestHistory_Subscribe(t *testing.T) {
+	h := New()
+	c, cancel := h.Subscribe()
+
+	e := h.NewEvent("x")
+	h.SetPhase(e, PhaseUploading)
+	select {
+	case <-c:
+	default:
+		t.Fatal("no notification for a new event")
+	}
+	select {
+	case <-c:
+		t.Fatal("notifications are not coalesced")
+	default:
+	}
+
+	if got := h.Running(); len(got) != 1 || got[0].Phase != PhaseUploading {
+		t.Errorf("Running() == [uploading], got %v", got)
+	}
+	h.Finish(e)
+	<-c
+	if got := h.Running(); len(got) != 0 {
+		t.Errorf("Running() == [], got %v", got)
+	}
+
+	cancel()
+	h.NewEvent("x")
+	select {
+	case <-c:
+		t.Error("notification after cancel")
+	default:
+	}
+}
@@ -0,0 +1,69 @@
+package hl7
+
+import (
+	"strings"
+	"time"
+)
+
+// Acknowledgment codes, as used in MSA-1.
+const (
+	AckAccept = "AA"
+	AckError  = "AE"
+	AckReject = "AR"
+)
+
+// Ack returns an acknowledgment for a message. For errors, text is included in MSA-3 and ERR-8.
+func Ack(m *Message, code, text string, now time.Time) []byte {
+	sep := string(m.field)
+	enc := m.Get("MSH", 2, 1)
+	if enc == "" {
+		enc = `^~\&`
+	}
+	_, event := m.Type()
+	text = m.escapeText(text)
+
+	msh := []string{
+		"MSH", enc,
+		m.rawField("MSH", 5), m.rawField("MSH", 6), // receiving application and facility become sender
+		m.rawField("MSH", 3), m.rawField("MSH", 4),
+		now.Format("20060102150405"),
+		"",
+		"ACK^" + event + "^ACK",
+		m.ControlID() + "-ACK",
+		m.rawField("MSH", 11),
+		m.rawField("MSH", 12),
+	}
+	msa := []string{"MSA", code, m.ControlID()}
+	if text != "" {
+		msa = append(msa, text)
+	}
+	segments := []string{strings.Join(msh, sep), strings.Join(msa, sep)}
+	if code != AckAccept {
+		segments = append(segments, strings.Join([]string{"ERR", "", "", "207", "E", "", "", "", text}, sep))
+	}
+	return []byte(strings.Join(segments, "\r") + "\r")
+}
+
+// rawField returns a field without resolving components or escape sequences.
+func (m *Message) rawField(segment string, field int) string {
+	s := m.Segment(segment)
+	if field >= len(s) {
+		return ""
+	}
+	return s[field]
+}
+
+// escapeText escapes delimiters in free text, and removes line breaks.
+func (m *Message) escapeText(s string) string {
+	esc := string(m.escape)
+	s = strings.ReplaceAll(s, esc, esc+"E"+esc)
+	s = strings.NewReplacer(
+		string(m.field), esc+"F"+esc,
+		string(m.component), esc+"S"+esc,
+		string(m.repetition), esc+"R"+esc,
+		string(m.subcomponent), esc+"T"+esc,
+		"\r", " ",
+		"\n", " ",
+	).Replace(s)
+	return s
+}
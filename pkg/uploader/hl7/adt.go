@@ -0,0 +1,122 @@
+package hl7
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+)
+
+// Events lists the supported ADT trigger events.
+var Events = map[string]string{
+	"A01": "Opname",
+	"A02": "Overplaatsing",
+	"A03": "Ontslag",
+	"A04": "Aanmelding",
+	"A08": "Wijziging",
+	"A11": "Annulering",
+}
+
+// ErrNoVisitNumber indicates that PV1-19 does not contain a numeric visit number.
+var ErrNoVisitNumber = errors.New("PV1-19 does not contain a numeric visit number")
+
+// UnsupportedError indicates that a message is not one of the supported ADT events.
+type UnsupportedError struct {
+	Type  string
+	Event string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("unsupported message type %s^%s", e.Type, e.Event)
+}
+
+// VisitorRecord maps an ADT message to a visitor record. Times without a zone are interpreted in loc.
+//
+// The visit number is taken from PV1-19, the location from PV1-3, and arrival and departure from PV1-44 and PV1-45. The
+// message control id is used as mutation id; if it is not numeric, a hash of it is used instead. A11 messages mark the
+// visit as cancelled.
+func VisitorRecord(m *Message, loc *time.Location) (*db.VisitorRecord, error) {
+	typ, event := m.Type()
+	if _, ok := Events[event]; typ != "ADT" || !ok {
+		return nil, &UnsupportedError{Type: typ, Event: event}
+	}
+	if m.Segment("PV1") == nil {
+		return nil, errors.New("message does not contain a PV1 segment")
+	}
+
+	visit, err := strconv.Atoi(m.Get("PV1", 19, 1))
+	if err != nil {
+		return nil, ErrNoVisitNumber
+	}
+
+	r := &db.VisitorRecord{
+		Bezoeknummer:      visit,
+		MutatieID:         mutationID(m.ControlID()),
+		Mutatiestatus:     event,
+		Afdeling:          m.Get("PV1", 3, 1),
+		Kamer:             m.Get("PV1", 3, 2),
+		Bed:               m.Get("PV1", 3, 3),
+		Locatie:           m.Get("PV1", 3, 4),
+		Specialisme:       m.Get("PV1", 10, 1),
+		Herkomst:          m.Get("PV1", 14, 1),
+		Ontslagbestemming: m.Get("PV1", 36, 1),
+		Ingangsklacht:     m.Get("PV2", 3, 1),
+		Urgentie:          m.Get("PV2", 25, 1),
+		Vervallen:         event == "A11",
+	}
+	if r.Locatie == "" {
+		r.Locatie = m.Get("MSH", 4, 1)
+	}
+
+	recorded, err := ParseTime(m.Get("EVN", 2, 1), loc)
+	if err != nil {
+		return nil, fmt.Errorf("EVN-2: %w", err)
+	}
+	if recorded == nil {
+		recorded, err = ParseTime(m.Get("MSH", 7, 1), loc)
+		if err != nil {
+			return nil, fmt.Errorf("MSH-7: %w", err)
+		}
+	}
+	if recorded != nil {
+		r.Aangemeld = *recorded
+	}
+
+	arrival, err := ParseTime(m.Get("PV1", 44, 1), loc)
+	if err != nil {
+		return nil, fmt.Errorf("PV1-44: %w", err)
+	}
+	if arrival != nil {
+		r.BinnenkomstDatum = arrival.Format("2006-01-02")
+		r.BinnenkomstTijd = arrival.Format("15:04")
+	}
+	departure, err := ParseTime(m.Get("PV1", 45, 1), loc)
+	if err != nil {
+		return nil, fmt.Errorf("PV1-45: %w", err)
+	}
+	if departure != nil {
+		r.VertrekTijd = departure.Format("15:04")
+	}
+
+	birth, err := ParseTime(m.Get("PID", 7, 1), loc)
+	if err != nil {
+		return nil, fmt.Errorf("PID-7: %w", err)
+	}
+	if birth != nil {
+		r.Geboortedatum = *birth
+	}
+	return r, nil
+}
+
+// mutationID returns the numeric message control id, or a positive hash if it is not numeric.
+func mutationID(controlID string) int {
+	if id, err := strconv.Atoi(controlID); err == nil {
+		return id
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(controlID))
+	return int(h.Sum32() & 0x7fffffff)
+}
@@ -0,0 +1,2 @@
+// Package hl7 receives HL7 v2 ADT messages over MLLP, and maps them to visitor records.
+package hl7
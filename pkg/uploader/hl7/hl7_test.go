@@ -0,0 +1,231 @@
+package hl7
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+)
+
+func adt(event, controlID, visit string) string {
+	return strings.Join([]string{
+		`MSH|^~\&|HIX|ZKH|D2D|D2D|20200301103000||ADT^` + event + `^ADT_A01|` + controlID + `|P|2.5`,
+		`EVN|` + event + `|20200301102900`,
+		`PID|||123456||Jansen^Jan||19750615`,
+		segment("PV1", map[int]string{2: "E", 3: "SEH^K3^2^ZKHA", 10: "CHI", 14: "5", 19: visit, 36: "VPL", 44: "20200301101500", 45: "20200301120000"}),
+		segment("PV2", map[int]string{3: `Buikpijn \T\ koorts`, 25: "U2"}),
+	}, "\r")
+}
+
+// segment returns a segment with the given fields set.
+func segment(name string, fields map[int]string) string {
+	var n int
+	for i := range fields {
+		n = max(n, i)
+	}
+	res := make([]string, n+1)
+	res[0] = name
+	for i, f := range fields {
+		res[i] = f
+	}
+	return strings.Join(res, "|")
+}
+
+func TestVisitorRecord(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Parse([]byte(adt("A03", "1234", "42")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := VisitorRecord(m, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := db.VisitorRecord{
+		Bezoeknummer:      42,
+		MutatieID:         1234,
+		Locatie:           "ZKHA",
+		Afdeling:          "SEH",
+		Aangemeld:         time.Date(2020, 3, 1, 10, 29, 0, 0, loc),
+		BinnenkomstDatum:  "2020-03-01",
+		BinnenkomstTijd:   "10:15",
+		VertrekTijd:       "12:00",
+		Mutatiestatus:     "A03",
+		Kamer:             "K3",
+		Bed:               "2",
+		Ingangsklacht:     "Buikpijn & koorts",
+		Specialisme:       "CHI",
+		Urgentie:          "U2",
+		Geboortedatum:     time.Date(1975, 6, 15, 0, 0, 0, 0, loc),
+		Herkomst:          "5",
+		Ontslagbestemming: "VPL",
+	}
+	if *r != want {
+		t.Errorf("VisitorRecord ==\n%+v\ngot\n%+v", want, *r)
+	}
+}
+
+func TestVisitorRecord_Errors(t *testing.T) {
+	for name, test := range map[string]struct {
+		Message     string
+		Unsupported bool
+		Err         error
+	}{
+		"cancel":      {Message: adt("A11", "1", "42")},
+		"unsupported": {Message: adt("A05", "1", "42"), Unsupported: true},
+		"no visit":    {Message: adt("A01", "1", ""), Err: ErrNoVisitNumber},
+		"text visit":  {Message: adt("A01", "1", "V42"), Err: ErrNoVisitNumber},
+		"text id":     {Message: adt("A08", "MSG-1", "42")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			m, err := Parse([]byte(test.Message))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := VisitorRecord(m, time.UTC)
+
+			var unsupported *UnsupportedError
+			if errors.As(err, &unsupported) != test.Unsupported {
+				t.Fatalf("Unsupported == %v, got %v", test.Unsupported, err)
+			}
+			if test.Unsupported {
+				return
+			}
+			if err != test.Err {
+				t.Fatalf("Error == %v, got %v", test.Err, err)
+			}
+			if err != nil {
+				return
+			}
+			if r.Vervallen != (name == "cancel") {
+				t.Errorf("Vervallen == %v, got %v", name == "cancel", r.Vervallen)
+			}
+			if r.MutatieID <= 0 {
+				t.Errorf("MutatieID should be positive, got %d", r.MutatieID)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	for value, want := range map[string]time.Time{
+		"2020":                time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		"20200301":            time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		"202003011030":        time.Date(2020, 3, 1, 10, 30, 0, 0, time.UTC),
+		"20200301103015.1234": time.Date(2020, 3, 1, 10, 30, 15, 0, time.UTC),
+		"202003011030+0100":   time.Date(2020, 3, 1, 9, 30, 0, 0, time.UTC),
+		"20200301103015-0500": time.Date(2020, 3, 1, 15, 30, 15, 0, time.UTC),
+	} {
+		t.Run(value, func(t *testing.T) {
+			got, err := ParseTime(value, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("ParseTime == %v, got %v", want, got)
+			}
+		})
+	}
+
+	if _, err := ParseTime("2020030", time.UTC); err == nil {
+		t.Error("Expected error for invalid timestamp")
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("noise")
+	_ = WriteMessage(&buf, []byte("MSH|first"))
+	_ = WriteMessage(&buf, []byte("MSH|second"))
+	r := bufio.NewReader(&buf)
+
+	for _, want := range []string{"MSH|first", "MSH|second"} {
+		got, err := ReadMessage(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("ReadMessage == %q, got %q", want, got)
+		}
+	}
+
+	if _, err := ReadMessage(bufio.NewReader(strings.NewReader("\x0bMSH|truncated"))); err == nil {
+		t.Error("Expected error for truncated message")
+	}
+}
+
+func TestListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var uploaded []int
+	l := &Listener{
+		Location: time.UTC,
+		Log:      NewLog(),
+		Upload: func(ctx context.Context, rec *db.VisitorRecord) error {
+			if rec.Bezoeknummer == 13 {
+				return errors.New("upload failed")
+			}
+			uploaded = append(uploaded, rec.Bezoeknummer)
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- l.Serve(ctx, ln)
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+
+	for _, test := range []struct {
+		Message string
+		Ack     string
+	}{
+		{adt("A01", "1", "42"), "MSA|AA|1"},
+		{adt("A01", "2", "13"), "MSA|AE|2|upload failed"},
+		{adt("A05", "3", "42"), `MSA|AR|3|unsupported message type ADT\S\A05`},
+	} {
+		if err := WriteMessage(conn, []byte(test.Message)); err != nil {
+			t.Fatal(err)
+		}
+		ack, err := ReadMessage(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segments := strings.Split(string(ack), "\r")
+		if len(segments) < 2 || segments[1] != test.Ack {
+			t.Errorf("Acknowledgment == %q, got %q", test.Ack, ack)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Serve == %v, got %v", context.Canceled, err)
+	}
+	if len(uploaded) != 1 || uploaded[0] != 42 {
+		t.Errorf("Uploaded == [42], got %v", uploaded)
+	}
+	if n := len(l.Log.Entries()); n != 3 {
+		t.Errorf("Log should contain 3 entries, got %d", n)
+	}
+}
@@ -0,0 +1,137 @@
+package hl7
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+)
+
+// ReadTimeout closes connections on which no message has been received for this long.
+const ReadTimeout = 10 * time.Minute
+
+// UploadFunc uploads a visitor record received in a message.
+type UploadFunc func(ctx context.Context, rec *db.VisitorRecord) error
+
+// Listener accepts ADT messages over MLLP. Each message is uploaded before it is acknowledged, such that the sender
+// resends messages that could not be uploaded.
+type Listener struct {
+	Location *time.Location
+	Upload   UploadFunc
+	Log      *Log
+
+	wg sync.WaitGroup
+}
+
+// Serve accepts connections on ln until the context is cancelled. It waits for open connections to close before
+// returning.
+func (l *Listener) Serve(ctx context.Context, ln net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		dlog.Close(ln)
+	}()
+
+	defer l.wg.Wait()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			l.serveConn(ctx, conn)
+		}()
+	}
+}
+
+func (l *Listener) serveConn(ctx context.Context, conn net.Conn) {
+	stop := context.AfterFunc(ctx, func() {
+		dlog.Close(conn)
+	})
+	defer func() {
+		if stop() {
+			dlog.Close(conn)
+		}
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(ReadTimeout))
+		bs, err := ReadMessage(r)
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				dlog.Error("While reading HL7 message from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		ack := l.handle(ctx, conn.RemoteAddr().String(), bs)
+		if ack == nil {
+			continue
+		}
+		if err := WriteMessage(conn, ack); err != nil {
+			dlog.Error("While sending HL7 acknowledgment to %s: %v", conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
+
+// handle processes a single message, and returns its acknowledgment. It returns nil if the message cannot be parsed
+// at all, as there is no control id to acknowledge.
+func (l *Listener) handle(ctx context.Context, remote string, bs []byte) []byte {
+	entry := Entry{Time: time.Now(), Remote: remote}
+	defer func() {
+		if l.Log != nil {
+			l.Log.Add(entry)
+		}
+	}()
+
+	m, err := Parse(bs)
+	if err != nil {
+		entry.Error = err.Error()
+		return nil
+	}
+	entry.ControlID = m.ControlID()
+	entry.Type, entry.Event = m.Type()
+
+	rec, err := VisitorRecord(m, l.Location)
+	var unsupported *UnsupportedError
+	switch {
+	case errors.As(err, &unsupported):
+		entry.Ack = AckReject
+	case err != nil:
+		entry.Ack = AckError
+	default:
+		entry.Visit = rec.Bezoeknummer
+		if err = l.Upload(ctx, rec); err != nil {
+			entry.Ack = AckError
+		} else {
+			entry.Ack = AckAccept
+		}
+	}
+
+	var text string
+	if err != nil {
+		entry.Error = err.Error()
+		text = err.Error()
+	}
+	return Ack(m, entry.Ack, text, entry.Time)
+}
@@ -0,0 +1,53 @@
+package hl7
+
+import (
+	"sync"
+	"time"
+)
+
+// MaxLog is the number of messages kept in the log.
+const MaxLog = 100
+
+// Log keeps track of recently received messages.
+type Log struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewLog returns an empty log.
+func NewLog() *Log {
+	return &Log{}
+}
+
+// Entry describes a single received message, and how it was acknowledged.
+type Entry struct {
+	Time      time.Time
+	Remote    string
+	ControlID string
+	Type      string
+	Event     string
+	Visit     int
+	Ack       string
+	Error     string
+}
+
+// Add adds an entry to the log, removing the oldest entry if the log is full.
+func (l *Log) Add(e Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append([]Entry{e}, l.entries...)
+	if len(l.entries) > MaxLog {
+		l.entries = l.entries[:MaxLog]
+	}
+}
+
+// Entries returns the logged messages, most recent first.
+func (l *Log) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	res := make([]Entry, len(l.entries))
+	copy(res, l.entries)
+	return res
+}
@@ -0,0 +1,144 @@
+package hl7
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNoHeader indicates that a message does not start with an MSH segment.
+var ErrNoHeader = errors.New("message does not start with an MSH segment")
+
+// Message is a parsed HL7 v2 message.
+type Message struct {
+	Segments []Segment
+
+	// delimiters, from MSH-1 and MSH-2
+	field, component, repetition, escape, subcomponent byte
+}
+
+// Segment contains the fields of a single segment. Field 0 is the segment name. For MSH segments, fields are numbered
+// as in the standard, such that MSH-1 is the field separator.
+type Segment []string
+
+// Parse parses a message. Segments are separated by carriage returns, or by newlines.
+func Parse(bs []byte) (*Message, error) {
+	s := strings.TrimSpace(strings.NewReplacer("\r\n", "\r", "\n", "\r").Replace(string(bs)))
+	if !strings.HasPrefix(s, "MSH") || len(s) < 8 {
+		return nil, ErrNoHeader
+	}
+
+	m := &Message{
+		field:        s[3],
+		component:    s[4],
+		repetition:   s[5],
+		escape:       s[6],
+		subcomponent: s[7],
+	}
+	for _, line := range strings.Split(s, "\r") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, string(m.field))
+		if fields[0] == "MSH" {
+			// MSH-1 is the field separator itself
+			fields = append([]string{"MSH", string(m.field)}, fields[1:]...)
+		}
+		m.Segments = append(m.Segments, fields)
+	}
+	return m, nil
+}
+
+// Segment returns the first segment with the given name, or nil if there is none.
+func (m *Message) Segment(name string) Segment {
+	for _, s := range m.Segments {
+		if s[0] == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Get returns a component of a field, such as Get("PV1", 3, 2) for the room in the assigned patient location. Only
+// the first repetition is used, and escape sequences are resolved. Missing values are returned as empty strings.
+func (m *Message) Get(segment string, field, component int) string {
+	s := m.Segment(segment)
+	if field >= len(s) {
+		return ""
+	}
+	value := s[field]
+	if segment == "MSH" && field <= 2 {
+		return value
+	}
+	value, _, _ = strings.Cut(value, string(m.repetition))
+
+	components := strings.Split(value, string(m.component))
+	if component < 1 || component > len(components) {
+		return ""
+	}
+	value, _, _ = strings.Cut(components[component-1], string(m.subcomponent))
+	return m.unescape(value)
+}
+
+// unescape resolves the escape sequences for delimiters.
+func (m *Message) unescape(s string) string {
+	esc := string(m.escape)
+	if !strings.Contains(s, esc) {
+		return s
+	}
+	return strings.NewReplacer(
+		esc+"F"+esc, string(m.field),
+		esc+"S"+esc, string(m.component),
+		esc+"R"+esc, string(m.repetition),
+		esc+"T"+esc, string(m.subcomponent),
+		esc+"E"+esc, esc,
+	).Replace(s)
+}
+
+// ControlID returns the message control id, MSH-10.
+func (m *Message) ControlID() string {
+	return m.Get("MSH", 10, 1)
+}
+
+// Type returns the message type and trigger event, such as ADT and A01.
+func (m *Message) Type() (string, string) {
+	typ, event := m.Get("MSH", 9, 1), m.Get("MSH", 9, 2)
+	if event == "" {
+		event = m.Get("EVN", 1, 1)
+	}
+	return typ, event
+}
+
+// ParseTime parses an HL7 timestamp such as 20200301123000 or 202003011230+0100. Timestamps without a zone are in loc.
+// It returns nil for empty values.
+func ParseTime(s string, loc *time.Location) (*time.Time, error) {
+	if s == "" || s == `""` {
+		return nil, nil
+	}
+
+	value, zone := s, ""
+	if i := strings.IndexAny(s, "+-"); i >= 0 {
+		value, zone = s[:i], s[i:]
+	}
+	value, _, _ = strings.Cut(value, ".")
+
+	layouts := map[int]string{4: "2006", 6: "200601", 8: "20060102", 10: "2006010215", 12: "200601021504", 14: "20060102150405"}
+	layout, ok := layouts[len(value)]
+	if !ok {
+		return nil, fmt.Errorf("invalid HL7 timestamp %q", s)
+	}
+	if zone != "" {
+		t, err := time.Parse(layout+"-0700", value+zone)
+		if err != nil {
+			return nil, err
+		}
+		t = t.In(loc)
+		return &t, nil
+	}
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
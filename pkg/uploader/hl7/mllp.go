@@ -0,0 +1,71 @@
+package hl7
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// MLLP frames each message with a start block, and an end block followed by a carriage return.
+const (
+	startBlock = 0x0b
+	endBlock   = 0x1c
+	cr         = 0x0d
+
+	// maxMessageSize limits the size of a single message.
+	maxMessageSize = 1 << 20
+)
+
+// ErrFraming indicates that data does not follow the MLLP framing.
+var ErrFraming = errors.New("invalid MLLP framing")
+
+// ReadMessage reads the next MLLP framed message. Data before the start block is skipped.
+func ReadMessage(r *bufio.Reader) ([]byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == startBlock {
+			break
+		}
+	}
+
+	var msg []byte
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch b {
+		case endBlock:
+			next, err := r.ReadByte()
+			if err != nil {
+				return nil, io.ErrUnexpectedEOF
+			}
+			if next != cr {
+				return nil, ErrFraming
+			}
+			return msg, nil
+		case startBlock:
+			return nil, ErrFraming
+		}
+		if len(msg) >= maxMessageSize {
+			return nil, ErrFraming
+		}
+		msg = append(msg, b)
+	}
+}
+
+// WriteMessage writes a message in an MLLP frame.
+func WriteMessage(w io.Writer, msg []byte) error {
+	frame := make([]byte, 0, len(msg)+3)
+	frame = append(frame, startBlock)
+	frame = append(frame, msg...)
+	frame = append(frame, endBlock, cr)
+	_, err := w.Write(frame)
+	return err
+}
@@ -0,0 +1,61 @@
+package password
+
+import (
+	"encoding/json"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Hash is a bcrypt hash of a password, for passwords that only need to be verified, such as the passwords of the web
+// interface.
+type Hash string
+
+// NewHash hashes a password in plain text.
+func NewHash(plain string) (Hash, error) {
+	bs, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return Hash(bs), nil
+}
+
+// Check returns true if plain matches the hash. An empty hash does not match any password.
+func (h Hash) Check(plain string) bool {
+	if h == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(h), []byte(plain)) == nil
+}
+
+// UnmarshalJSON reads a hash, or a password in plain text written as {"plain_text": "secret"}, which is hashed.
+func (h *Hash) UnmarshalJSON(bs []byte) error {
+	if len(bs) > 0 && bs[0] == '{' {
+		var plain struct {
+			PlainText string `json:"plain_text"`
+		}
+		if err := json.Unmarshal(bs, &plain); err != nil {
+			return err
+		}
+		if plain.PlainText == "" {
+			*h = ""
+			return nil
+		}
+		hash, err := NewHash(plain.PlainText)
+		if err != nil {
+			return err
+		}
+		*h = hash
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(bs, &s); err != nil {
+		return err
+	}
+	*h = Hash(s)
+	return nil
+}
+
+func (h Hash) String() string {
+	return "[redacted]"
+}
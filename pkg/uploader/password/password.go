@@ -36,12 +36,25 @@ func (p Password) MarshalJSON() ([]byte, error) {
 	return json.Marshal(ciphertext)
 }
 
+// UnmarshalJSON reads an obfuscated password, or a password in plain text written as {"plain_text": "secret"}. The
+// latter allows passwords to be set by hand, or by automated rollouts.
+//
 //goland:noinspection GoMixedReceiverTypes
 func (p *Password) UnmarshalJSON(bs []byte) error {
 	if string(bs) == `""` {
 		*p = ""
 		return nil
 	}
+	if len(bs) > 0 && bs[0] == '{' {
+		var plain struct {
+			PlainText string `json:"plain_text"`
+		}
+		if err := json.Unmarshal(bs, &plain); err != nil {
+			return err
+		}
+		*p = Password(plain.PlainText)
+		return nil
+	}
 
 	var ciphertext []byte
 	if err := json.Unmarshal(bs, &ciphertext); err != nil {
@@ -99,3 +99,55 @@ func TestPassword_PlainText(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestPassword_UnmarshalJSON_plainText(t *testing.T) {
+	var got Password
+	if err := json.Unmarshal([]byte(`{"plain_text": "secret"}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := Password("secret")
+	if got != want {
+		t.Errorf("Unmarshal plain text == %q, want %q", got.PlainText(), want.PlainText())
+	}
+}
+
+func TestHash_Check(t *testing.T) {
+	h, err := NewHash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(h), "secret") {
+		t.Errorf("Hash contains the password, got %q", h)
+	}
+	if !h.Check("secret") {
+		t.Error("Check(secret) == true, got false")
+	}
+	if h.Check("Secret") {
+		t.Error("Check(Secret) == false, got true")
+	}
+	if Hash("").Check("") {
+		t.Error("empty Hash.Check() == false, got true")
+	}
+}
+
+func TestHash_UnmarshalJSON_plainText(t *testing.T) {
+	var h Hash
+	if err := json.Unmarshal([]byte(`{"plain_text": "secret"}`), &h); err != nil {
+		t.Fatal(err)
+	}
+	if !h.Check("secret") {
+		t.Error("Check(secret) == true, got false")
+	}
+
+	bs, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Hash
+	if err := json.Unmarshal(bs, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != h {
+		t.Errorf("Unmarshal() == %q, got %q", h, decoded)
+	}
+}
@@ -0,0 +1,215 @@
+package rest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin determines how long before expiry an access token is refreshed.
+const tokenRefreshMargin = time.Minute
+
+// Authenticator adds credentials to requests, such as those for the door2doc server.
+type Authenticator interface {
+	// Authenticate adds credentials to req. Any requests needed to obtain the credentials are sent by c using opts.
+	Authenticate(ctx context.Context, c *Client, opts Options, req *http.Request) error
+
+	// Invalidate discards the credentials cached by c after the server rejected them. It returns true if the request
+	// should be retried with fresh credentials.
+	Invalidate(c *Client) bool
+}
+
+// BasicAuth authenticates using HTTP basic authentication.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (a BasicAuth) Authenticate(_ context.Context, _ *Client, _ Options, req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+func (a BasicAuth) Invalidate(_ *Client) bool {
+	return false
+}
+
+// BearerToken authenticates using a fixed access token.
+type BearerToken struct {
+	Token string
+}
+
+func (a BearerToken) Authenticate(_ context.Context, _ *Client, _ Options, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	return nil
+}
+
+func (a BearerToken) Invalidate(_ *Client) bool {
+	return false
+}
+
+// ClientCredentials authenticates using access tokens obtained through the OAuth2 client credentials grant. Tokens are
+// cached by the client that sends the requests, until shortly before they expire.
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scope        string
+}
+
+// token is a cached access token. Its mutex ensures that only one request refreshes it at a time.
+type token struct {
+	mu      sync.Mutex
+	secret  string
+	value   string
+	expires time.Time
+}
+
+// tokenCache holds the access tokens obtained by a client, with one token per token endpoint, client and scope.
+type tokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]*token
+}
+
+// key identifies the cached token for these credentials. The secret is not part of it, such that a token obtained
+// with an old secret is replaced when the secret changes.
+func (a ClientCredentials) key() string {
+	return strings.Join([]string{a.TokenURL, a.ClientID, a.Scope}, "\x00")
+}
+
+// secretHash identifies the secret, without keeping the secret in memory twice.
+func (a ClientCredentials) secretHash() string {
+	sum := sha256.Sum256([]byte(a.ClientSecret))
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns the cached token for credentials. Expired tokens are dropped, as is the token for the same client
+// obtained using another secret.
+func (tc *tokenCache) get(a ClientCredentials) *token {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	now := time.Now()
+	for key, t := range tc.tokens {
+		// tokens that are being refreshed are in use
+		if !t.mu.TryLock() {
+			continue
+		}
+		if t.value == "" || now.After(t.expires) {
+			delete(tc.tokens, key)
+		}
+		t.mu.Unlock()
+	}
+
+	key, secret := a.key(), a.secretHash()
+	if t, ok := tc.tokens[key]; ok && t.secret == secret {
+		return t
+	}
+	if tc.tokens == nil {
+		tc.tokens = make(map[string]*token)
+	}
+	t := &token{secret: secret}
+	tc.tokens[key] = t
+	return t
+}
+
+func (a ClientCredentials) Authenticate(ctx context.Context, c *Client, opts Options, req *http.Request) error {
+	t := c.tokens.get(a)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.value == "" || time.Now().Add(tokenRefreshMargin).After(t.expires) {
+		value, lifetime, err := a.fetch(ctx, c, opts)
+		if err != nil {
+			return err
+		}
+		t.value, t.expires = value, time.Now().Add(lifetime)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+t.value)
+	return nil
+}
+
+func (a ClientCredentials) Invalidate(c *Client) bool {
+	t := c.tokens.get(a)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.value = ""
+	return true
+}
+
+// tokenResponse is the response of the token endpoint, as defined in RFC 6749 sections 5.1 and 5.2.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// fetch requests a new access token, and returns it with its lifetime.
+func (a ClientCredentials) fetch(ctx context.Context, c *Client, opts Options) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if a.Scope != "" {
+		form.Set("scope", a.Scope)
+	}
+	req, err := http.NewRequest(http.MethodPost, a.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(a.ClientID), url.QueryEscape(a.ClientSecret))
+
+	// the token endpoint is usually not the door2doc server, so neither its pins nor the client certificate apply
+	opts = Options{Proxy: opts.Proxy, TLS: TLS{CAFile: opts.TLS.CAFile}}
+	res, err := c.Do(ctx, opts, req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	var body tokenResponse
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&body); err != nil && res.StatusCode == http.StatusOK {
+		return "", 0, fmt.Errorf("invalid token response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return "", 0, &TokenError{StatusCode: res.StatusCode, Code: body.Error, Description: body.ErrorDescription}
+	}
+	if !strings.EqualFold(body.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("unsupported token type %q", body.TokenType)
+	}
+
+	lifetime := time.Duration(body.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = time.Hour
+	}
+	return body.AccessToken, lifetime, nil
+}
+
+// TokenError indicates that the token endpoint did not issue an access token.
+type TokenError struct {
+	StatusCode  int
+	Code        string
+	Description string
+}
+
+func (e *TokenError) Error() string {
+	msg := fmt.Sprintf("token request failed: HTTP %d", e.StatusCode)
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	return msg
+}
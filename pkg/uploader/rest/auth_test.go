@@ -0,0 +1,203 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientCredentials(t *testing.T) {
+	var issued int32
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "client" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		n := atomic.AddInt32(&issued, 1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer tokenSrv.Close()
+
+	// the first token is rejected, as if it was revoked
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		bs, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(bs)
+	}))
+	defer srv.Close()
+
+	client := NewClient()
+	auth := ClientCredentials{TokenURL: tokenSrv.URL, ClientID: "client", ClientSecret: "secret", Scope: "upload"}
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := client.Do(context.Background(), Options{Auth: auth}, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bs, _ := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		if res.StatusCode != http.StatusOK || string(bs) != "body" {
+			t.Errorf("Do() == 200 body, got %d %s", res.StatusCode, bs)
+		}
+	}
+	if issued != 2 {
+		t.Errorf("tokens issued == 2, got %d", issued)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth.ClientSecret = "wrong"
+	_, err = client.Do(context.Background(), Options{Auth: auth}, req)
+	var tokenErr *TokenError
+	if !errors.As(err, &tokenErr) || tokenErr.Code != "invalid_client" {
+		t.Errorf("Do() == TokenError invalid_client, got %v", err)
+	}
+	if n := len(client.tokens.tokens); n != 1 {
+		t.Errorf("the token for the old secret is dropped, got %d tokens", n)
+	}
+}
+
+func TestClientCredentials_TLS(t *testing.T) {
+	tokenSrv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "token_type": "Bearer"})
+	}))
+	tokenSrv.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	tokenSrv.StartTLS()
+	defer tokenSrv.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	keyPEM, csrPEM, err := GenerateCSR("uploader", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, certPEM := issue(t, csrPEM)
+	dir := t.TempDir()
+	write := func(name string, bs []byte) string {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, bs, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+
+	// the pins and client certificate of the door2doc server are not used for the token endpoint, its CA is
+	opts := Options{
+		TLS: TLS{
+			CAFile:     write("ca.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tokenSrv.Certificate().Raw})),
+			Pins:       []string{strings.Repeat("A", 43) + "="},
+			ClientCert: write("cert.pem", certPEM),
+			ClientKey:  write("key.pem", keyPEM),
+		},
+		Auth: ClientCredentials{TokenURL: tokenSrv.URL, ClientID: "client", ClientSecret: "secret"},
+	}
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := NewClient().Do(context.Background(), opts, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Do() == 200, got %d", res.StatusCode)
+	}
+}
+
+func TestTokenCache(t *testing.T) {
+	var tc tokenCache
+	a := ClientCredentials{TokenURL: "https://login.example/token", ClientID: "client", ClientSecret: "secret"}
+
+	first := tc.get(a)
+	first.value, first.expires = "token", time.Now().Add(time.Hour)
+	if got := tc.get(a); got != first {
+		t.Error("get() returns the cached token")
+	}
+
+	other := a
+	other.ClientID = "other"
+	expired := tc.get(other)
+	expired.value, expired.expires = "expired", time.Now().Add(-time.Second)
+
+	tc.get(a)
+	if _, ok := tc.tokens[other.key()]; ok {
+		t.Error("expired tokens are dropped")
+	}
+
+	a.ClientSecret = "changed"
+	if got := tc.get(a); got == first || got.value != "" {
+		t.Error("the token is replaced when the secret changes")
+	}
+	if n := len(tc.tokens); n != 1 {
+		t.Errorf("len(tokens) == 1, got %d", n)
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if u, p, _ := r.BasicAuth(); u != "user" || p != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient()
+	for auth, want := range map[BasicAuth]int{
+		{Username: "user", Password: "password"}: http.StatusOK,
+		{Username: "user", Password: "wrong"}:    http.StatusUnauthorized,
+	} {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := client.Do(context.Background(), Options{Auth: auth}, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = res.Body.Close()
+		if res.StatusCode != want {
+			t.Errorf("Do() == %d, got %d", want, res.StatusCode)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("requests == 2, got %d", requests)
+	}
+}
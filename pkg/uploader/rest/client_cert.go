@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// ErrNoPrivateKey indicates that a client certificate was provided without its private key.
+var ErrNoPrivateKey = errors.New("no private key found")
+
+// ErrKeyMismatch indicates that a client certificate does not belong to the private key.
+var ErrKeyMismatch = errors.New("certificate does not match the private key")
+
+// GenerateCSR generates a new private key, and a certificate signing request for it. Both are returned PEM encoded.
+func GenerateCSR(commonName, organization string) (keyPEM, csrPEM []byte, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subject := pkix.Name{CommonName: commonName}
+	if organization != "" {
+		subject.Organization = []string{organization}
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: subject}, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyPEM, err = encodeKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keyPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}), nil
+}
+
+// ParseClientCertificate reads a client certificate in PEM or PKCS#12 format, and returns the certificate chain and
+// private key PEM encoded. If the data does not contain a private key, keyPEM is used, which must then match the
+// certificate. The password is only used for PKCS#12 files.
+func ParseClientCertificate(data []byte, password string, keyPEM []byte) (certPEM, privPEM []byte, err error) {
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		certPEM, privPEM = splitPEM(data)
+	} else {
+		key, cert, chain, err := pkcs12.DecodeChain(data, password)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, c := range append([]*x509.Certificate{cert}, chain...) {
+			certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})...)
+		}
+		if privPEM, err = encodeKey(key); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(certPEM) == 0 {
+		return nil, nil, ErrNoCertificates
+	}
+	if len(privPEM) == 0 {
+		if len(keyPEM) == 0 {
+			return nil, nil, ErrNoPrivateKey
+		}
+		privPEM = keyPEM
+	}
+	if _, err := tls.X509KeyPair(certPEM, privPEM); err != nil {
+		if len(keyPEM) > 0 && bytes.Equal(privPEM, keyPEM) {
+			return nil, nil, ErrKeyMismatch
+		}
+		return nil, nil, err
+	}
+	return certPEM, privPEM, nil
+}
+
+// splitPEM separates the certificates from the private key in PEM encoded data. Other blocks are ignored.
+func splitPEM(data []byte) (certPEM, keyPEM []byte) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certPEM, keyPEM
+		}
+		switch block.Type {
+		case "CERTIFICATE":
+			certPEM = append(certPEM, pem.EncodeToMemory(block)...)
+		case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY":
+			keyPEM = pem.EncodeToMemory(block)
+		}
+	}
+}
+
+func encodeKey(key crypto.PrivateKey) ([]byte, error) {
+	bs, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: bs}), nil
+}
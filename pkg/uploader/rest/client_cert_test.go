@@ -0,0 +1,174 @@
+package rest
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// issue signs a certificate signing request with a throwaway CA, and returns the certificate PEM encoded.
+func issue(t *testing.T, csrPEM []byte) (*x509.CertPool, []byte) {
+	t.Helper()
+
+	caKeyPEM, _, err := GenerateCSR("test CA", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(caKeyPEM)
+	caKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.(crypto.Signer).Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ = pem.Decode(csrPEM)
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      csr.Subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, csr.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	return pool, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestParseClientCertificate(t *testing.T) {
+	keyPEM, csrPEM, err := GenerateCSR("uploader", "hospital")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKeyPEM, _, err := GenerateCSR("other", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, certPEM := issue(t, csrPEM)
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p12, err := pkcs12.Modern.Encode(cert.PrivateKey, cert.Leaf, nil, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		Data     []byte
+		Password string
+		Key      []byte
+		Error    error
+	}{
+		"requested":    {Data: certPEM, Key: keyPEM},
+		"combined pem": {Data: append(append([]byte{}, certPEM...), keyPEM...)},
+		"pkcs12":       {Data: p12, Password: "secret"},
+		"no key":       {Data: certPEM, Error: ErrNoPrivateKey},
+		"other key":    {Data: certPEM, Key: otherKeyPEM, Error: ErrKeyMismatch},
+		"no cert":      {Data: keyPEM, Error: ErrNoCertificates},
+	} {
+		t.Run(name, func(t *testing.T) {
+			gotCert, gotKey, err := ParseClientCertificate(test.Data, test.Password, test.Key)
+			if err != test.Error {
+				t.Fatalf("ParseClientCertificate() error == %v, got %v", test.Error, err)
+			}
+			if err != nil {
+				return
+			}
+			if _, err := tls.X509KeyPair(gotCert, gotKey); err != nil {
+				t.Errorf("ParseClientCertificate() == valid key pair, got %v", err)
+			}
+		})
+	}
+
+	if _, _, err := ParseClientCertificate(p12, "wrong", nil); err == nil {
+		t.Errorf("ParseClientCertificate() with wrong password == error, got nil")
+	}
+}
+
+func TestDo_ClientCertificate(t *testing.T) {
+	keyPEM, csrPEM, err := GenerateCSR("uploader", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, certPEM := issue(t, csrPEM)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	srv.StartTLS()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	write := func(name string, bs []byte) string {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, bs, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	caFile := write("ca.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+	certFile := write("cert.pem", certPEM)
+	keyFile := write("key.pem", keyPEM)
+
+	for name, test := range map[string]struct {
+		TLS   TLS
+		Error bool
+	}{
+		"without certificate": {TLS: TLS{CAFile: caFile}, Error: true},
+		"with certificate":    {TLS: TLS{CAFile: caFile, ClientCert: certFile, ClientKey: keyFile}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := NewClient().Do(context.Background(), Options{TLS: test.TLS}, req)
+			if err == nil {
+				_ = res.Body.Close()
+			}
+			if (err != nil) != test.Error {
+				t.Fatalf("Do() error == %t, got %v", test.Error, err)
+			}
+			if err != nil && !IsTLSError(err) {
+				t.Errorf("IsTLSError(%v) == true, got false", err)
+			}
+		})
+	}
+}
@@ -2,49 +2,186 @@ package rest
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
 	"net/http"
-	"net/url"
+	"strings"
 	"sync"
+	"time"
 )
 
-var (
-	mu           sync.Mutex
-	currentProxy string
-	client       = new(http.Client)
+const (
+	// DialTimeout limits the time spent connecting to the server or proxy.
+	DialTimeout = 15 * time.Second
+	// TLSHandshakeTimeout limits the time spent on the TLS handshake.
+	TLSHandshakeTimeout = 15 * time.Second
+	// ResponseTimeout limits the time spent waiting for response headers after the request has been sent. Uploads
+	// are processed before the server responds, so this is generous.
+	ResponseTimeout = 2 * time.Minute
+	// IdleTimeout determines how long unused connections are kept open for reuse.
+	IdleTimeout = 90 * time.Second
 )
 
-func Do(ctx context.Context, proxy string, req *http.Request) (*http.Response, error) {
-	mu.Lock()
-	defer mu.Unlock()
+// Options determine how requests reach the door2doc server.
+type Options struct {
+	Proxy Proxy
+	TLS   TLS
 
-	if currentProxy != proxy {
-		if err := updateClient(proxy); err != nil {
-			return nil, err
+	// Auth adds credentials to requests. If nil, requests are sent as is.
+	Auth Authenticator
+}
+
+// key identifies the settings that determine the transport, such that it is only rebuilt when these change.
+func (o Options) key() string {
+	p := o.Proxy
+	return strings.Join([]string{p.URL, p.Username, p.Password, p.NoProxy, p.PAC, o.TLS.key()}, "\x00")
+}
+
+// maxTransports limits the number of distinct options for which a Client keeps connections open.
+const maxTransports = 4
+
+// Client sends requests to the door2doc server and the FHIR servers. Connections are reused between requests with
+// equal options. It is safe for concurrent use.
+type Client struct {
+	mu sync.Mutex
+	// transports holds the transports for the most recently used options, most recent first
+	transports []keyedTransport
+
+	// tokens caches the access tokens obtained by ClientCredentials
+	tokens tokenCache
+}
+
+type keyedTransport struct {
+	key       string
+	transport *http.Transport
+}
+
+// NewClient returns a client without open connections.
+func NewClient() *Client {
+	return &Client{}
+}
+
+func newTransport(cfg *tls.Config, proxy Proxy) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:                 proxy.ProxyURL,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       cfg,
+		TLSHandshakeTimeout:   TLSHandshakeTimeout,
+		ResponseHeaderTimeout: ResponseTimeout,
+		IdleConnTimeout:       IdleTimeout,
+		ExpectContinueTimeout: time.Second,
+		MaxIdleConns:          10,
+		ForceAttemptHTTP2:     true,
+	}
+}
+
+// transportFor returns the transport for the options. Transports are kept for the most recently used options, such
+// that requests to different servers do not replace each other's connections. If the settings have changed, the
+// least recently used transport is dropped. Requests in flight on it complete normally, after which its connections
+// are closed once they have been idle for IdleTimeout.
+func (c *Client) transportFor(opts Options) (*http.Transport, error) {
+	key := opts.key()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, kt := range c.transports {
+		if kt.key == key {
+			copy(c.transports[1:i+1], c.transports[:i])
+			c.transports[0] = kt
+			return kt.transport, nil
 		}
 	}
-	return client.Do(req.WithContext(ctx))
+
+	cfg, err := opts.TLS.config()
+	if err != nil {
+		return nil, err
+	}
+	t := newTransport(cfg, opts.Proxy)
+	c.transports = append([]keyedTransport{{key: key, transport: t}}, c.transports...)
+	if len(c.transports) > maxTransports {
+		c.transports[maxTransports].transport.CloseIdleConnections()
+		c.transports = c.transports[:maxTransports]
+	}
+	return t, nil
 }
 
-func updateClient(newProxy string) error {
-	if currentProxy == newProxy {
-		return nil
+// CloseIdleConnections closes all connections that are not in use.
+func (c *Client) CloseIdleConnections() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, kt := range c.transports {
+		kt.transport.CloseIdleConnections()
 	}
+}
 
-	client.CloseIdleConnections()
+// Do sends a request using the given options. If the server rejects the credentials, they are refreshed and the
+// request is retried once.
+func (c *Client) Do(ctx context.Context, opts Options, req *http.Request) (*http.Response, error) {
+	t, err := c.transportFor(opts)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Transport: t}
 
-	if newProxy == "" {
-		client = new(http.Client)
-		return nil
+	if opts.Auth == nil {
+		return client.Do(req.WithContext(ctx))
 	}
 
-	proxyURL, err := url.Parse(newProxy)
+	req = req.Clone(ctx)
+	if err := opts.Auth.Authenticate(ctx, c, opts, req); err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	if (req.Body != nil && req.GetBody == nil) || !opts.Auth.Invalidate(c) {
+		return res, nil
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	retry := req.Clone(ctx)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	if err := opts.Auth.Authenticate(ctx, c, opts, retry); err != nil {
+		return nil, err
+	}
+	return client.Do(retry)
+}
+
+// PeerCertificates returns the certificate chain presented for a URL, without verifying it. This shows whether the
+// connection is intercepted, and which keys can be pinned.
+func PeerCertificates(ctx context.Context, opts Options, u string) ([]*x509.Certificate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	client = &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyURL(proxyURL),
-		},
+
+	t := newTransport(&tls.Config{InsecureSkipVerify: true}, opts.Proxy)
+	defer t.CloseIdleConnections()
+
+	client := &http.Client{Transport: t}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = res.Body.Close()
+
+	if res.TLS == nil {
+		return nil, errors.New("connection does not use TLS")
 	}
-	return nil
+	return res.TLS.PeerCertificates, nil
 }
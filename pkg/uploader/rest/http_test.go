@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestClient_TransportFor(t *testing.T) {
+	c := NewClient()
+	door2doc := Options{}
+	fhirProxy := Options{Proxy: Proxy{URL: "http://proxy:3128"}}
+
+	first, err := c.transportFor(door2doc)
+	if err != nil {
+		t.Fatalf("transportFor failed: %v", err)
+	}
+	if _, err := c.transportFor(fhirProxy); err != nil {
+		t.Fatalf("transportFor failed: %v", err)
+	}
+	if got, _ := c.transportFor(door2doc); got != first {
+		t.Errorf("expected the transport to be kept for options used alternately")
+	}
+
+	for i := 0; i < maxTransports; i++ {
+		if _, err := c.transportFor(Options{Proxy: Proxy{URL: fmt.Sprintf("http://proxy%d:3128", i)}}); err != nil {
+			t.Fatalf("transportFor failed: %v", err)
+		}
+	}
+	if len(c.transports) != maxTransports {
+		t.Errorf("expected %d transports, got %d", maxTransports, len(c.transports))
+	}
+	if got, _ := c.transportFor(door2doc); got == first {
+		t.Errorf("expected the least recently used transport to be dropped")
+	}
+}
@@ -0,0 +1,311 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+const (
+	// pacRefresh determines how long a proxy auto-config file is cached.
+	pacRefresh = time.Hour
+	// pacRetry determines how long a proxy auto-config file that could not be loaded is not downloaded again.
+	pacRetry = 30 * time.Second
+	// pacTimeout limits the time spent downloading or evaluating a proxy auto-config file.
+	pacTimeout = 10 * time.Second
+)
+
+var (
+	pacMu      sync.Mutex
+	pacEntries = make(map[string]*pacEntry)
+
+	// pacClient downloads proxy auto-config files. These are always retrieved without a proxy.
+	pacClient = &http.Client{
+		Transport: &http.Transport{Proxy: nil},
+		Timeout:   pacTimeout,
+	}
+)
+
+// pacEntry caches the proxy auto-config file at one location. It is guarded by pacMu.
+type pacEntry struct {
+	// script is the most recently loaded file, nil until a download has succeeded
+	script *pacScript
+	loaded time.Time
+
+	// err is the reason the most recent download failed, nil if it succeeded
+	err    error
+	failed time.Time
+
+	// loading is closed when the current download completes, nil if there is none
+	loading chan struct{}
+}
+
+// pacScript is a compiled proxy auto-config file. The JavaScript runtime is not safe for concurrent use.
+type pacScript struct {
+	mu   sync.Mutex
+	vm   *goja.Runtime
+	find goja.Callable
+}
+
+// findProxyPAC evaluates the proxy auto-config file at location for a request URL. It returns nil if the request
+// should connect directly.
+func findProxyPAC(ctx context.Context, location string, u *url.URL) (*url.URL, error) {
+	script, err := loadPAC(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	script.mu.Lock()
+	defer script.mu.Unlock()
+
+	timer := time.AfterFunc(pacTimeout, func() {
+		script.vm.Interrupt("timeout")
+	})
+	defer timer.Stop()
+
+	res, err := script.find(goja.Undefined(), script.vm.ToValue(u.String()), script.vm.ToValue(u.Hostname()))
+	script.vm.ClearInterrupt()
+	if err != nil {
+		return nil, fmt.Errorf("while evaluating proxy auto-config: %w", err)
+	}
+	return parsePACResult(res.String())
+}
+
+// loadPAC returns the compiled proxy auto-config file, downloading it if it is not cached or has expired.
+// Files are downloaded in the background, independent of ctx, such that a canceled request does not affect others.
+// The most recently loaded file is used while it is refreshed, and when refreshing fails. Requests that arrive before
+// a file has been loaded wait for the download, and a download that failed is retried after pacRetry.
+func loadPAC(ctx context.Context, location string) (*pacScript, error) {
+	pacMu.Lock()
+	e, ok := pacEntries[location]
+	if !ok {
+		e = &pacEntry{}
+		pacEntries[location] = e
+	}
+	if e.loading == nil && e.due() {
+		e.loading = make(chan struct{})
+		go e.load(location, e.loading)
+	}
+	s, err, loading := e.script, e.err, e.loading
+	pacMu.Unlock()
+
+	if s != nil {
+		return s, nil
+	} else if loading == nil {
+		return nil, err
+	}
+
+	select {
+	case <-loading:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	pacMu.Lock()
+	defer pacMu.Unlock()
+	if e.script != nil {
+		return e.script, nil
+	}
+	return nil, e.err
+}
+
+// due returns true if the file should be downloaded. The caller must hold pacMu.
+func (e *pacEntry) due() bool {
+	if e.err != nil {
+		return time.Since(e.failed) >= pacRetry
+	}
+	return e.script == nil || time.Since(e.loaded) >= pacRefresh
+}
+
+// load downloads and compiles the file, and closes loading when done.
+func (e *pacEntry) load(location string, loading chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), pacTimeout)
+	defer cancel()
+
+	s := &pacScript{}
+	err := s.compile(ctx, location)
+
+	pacMu.Lock()
+	if err != nil {
+		e.err, e.failed = err, time.Now()
+	} else {
+		e.script, e.loaded, e.err = s, time.Now(), nil
+	}
+	e.loading = nil
+	pacMu.Unlock()
+	close(loading)
+}
+
+func (s *pacScript) compile(ctx context.Context, location string) error {
+	src, err := readPAC(ctx, location)
+	if err != nil {
+		return fmt.Errorf("while reading proxy auto-config %s: %w", location, err)
+	}
+
+	s.vm = goja.New()
+	for name, f := range pacFunctions {
+		if err := s.vm.Set(name, f); err != nil {
+			return err
+		}
+	}
+	if _, err := s.vm.RunScript(location, src); err != nil {
+		return fmt.Errorf("while loading proxy auto-config %s: %w", location, err)
+	}
+
+	find, ok := goja.AssertFunction(s.vm.Get("FindProxyForURL"))
+	if !ok {
+		return fmt.Errorf("proxy auto-config %s does not define FindProxyForURL", location)
+	}
+	s.find = find
+	return nil
+}
+
+func readPAC(ctx context.Context, location string) (string, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		bs, err := os.ReadFile(strings.TrimPrefix(location, "file://"))
+		return string(bs), err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := pacClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response: %s", res.Status)
+	}
+	bs, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	return string(bs), err
+}
+
+// parsePACResult interprets the first entry of a FindProxyForURL result, such as "PROXY proxy:8080; DIRECT".
+func parsePACResult(s string) (*url.URL, error) {
+	entry, _, _ := strings.Cut(s, ";")
+	fields := strings.Fields(entry)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	var scheme string
+	switch strings.ToUpper(fields[0]) {
+	case "DIRECT":
+		return nil, nil
+	case "PROXY", "HTTP":
+		scheme = "http"
+	case "HTTPS":
+		scheme = "https"
+	case "SOCKS", "SOCKS5":
+		scheme = "socks5"
+	default:
+		return nil, fmt.Errorf("unsupported proxy auto-config result: %q", s)
+	}
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid proxy auto-config result: %q", s)
+	}
+	return &url.URL{Scheme: scheme, Host: fields[1]}, nil
+}
+
+// pacFunctions are the predefined functions available to proxy auto-config files. Time based functions are not
+// supported.
+var pacFunctions = map[string]interface{}{
+	"isPlainHostName": func(host string) bool {
+		return !strings.Contains(host, ".")
+	},
+	"dnsDomainIs": func(host, domain string) bool {
+		return strings.HasSuffix(strings.ToLower(host), strings.ToLower(domain))
+	},
+	"localHostOrDomainIs": func(host, hostdom string) bool {
+		host, hostdom = strings.ToLower(host), strings.ToLower(hostdom)
+		return host == hostdom || (!strings.Contains(host, ".") && strings.HasPrefix(hostdom, host+"."))
+	},
+	"isResolvable": func(host string) bool {
+		return resolve(host) != ""
+	},
+	"dnsResolve": func(host string) interface{} {
+		if ip := resolve(host); ip != "" {
+			return ip
+		}
+		return nil
+	},
+	"isInNet": func(host, pattern, mask string) bool {
+		ip := net.ParseIP(host)
+		if ip == nil {
+			ip = net.ParseIP(resolve(host))
+		}
+		p, m := net.ParseIP(pattern).To4(), net.ParseIP(mask).To4()
+		if ip == nil || ip.To4() == nil || p == nil || m == nil {
+			return false
+		}
+		return ip.To4().Mask(net.IPMask(m)).Equal(p.Mask(net.IPMask(m)))
+	},
+	"myIpAddress": func() string {
+		addrs, err := net.InterfaceAddrs()
+		if err == nil {
+			for _, a := range addrs {
+				if n, ok := a.(*net.IPNet); ok && !n.IP.IsLoopback() && n.IP.To4() != nil {
+					return n.IP.String()
+				}
+			}
+		}
+		return "127.0.0.1"
+	},
+	"dnsDomainLevels": func(host string) int {
+		return strings.Count(host, ".")
+	},
+	"shExpMatch": func(s, pattern string) bool {
+		re, err := shExpRegexp(pattern)
+		return err == nil && re.MatchString(s)
+	},
+}
+
+func resolve(host string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), pacTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return ""
+	}
+	for _, a := range addrs {
+		if a.IP.To4() != nil {
+			return a.IP.String()
+		}
+	}
+	if len(addrs) > 0 {
+		return addrs[0].IP.String()
+	}
+	return ""
+}
+
+// shExpRegexp converts a shell expression, using * and ? as wildcards, into a regular expression.
+func shExpRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
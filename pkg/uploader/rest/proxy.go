@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Proxy contains the settings for reaching the door2doc server through a proxy server.
+type Proxy struct {
+	// URL of the proxy server, such as http://proxy:8080. If empty, the proxy settings of the environment are used.
+	URL      string
+	Username string
+	Password string
+
+	// NoProxy is a comma or semicolon separated list of hosts that are connected to directly. Entries can be host
+	// names, domains such as .example.com, IP addresses, CIDR ranges, "*" for all hosts, and "<local>" for plain host
+	// names.
+	NoProxy string
+
+	// PAC is the URL or path of a proxy auto-config file. If set, it determines the proxy server, and URL is only
+	// used when the file cannot be evaluated.
+	PAC string
+}
+
+// ProxyURL returns the URL of the proxy server for a request, or nil if the request should not use a proxy.
+func (p Proxy) ProxyURL(req *http.Request) (*url.URL, error) {
+	if p.URL == "" && p.PAC == "" {
+		return http.ProxyFromEnvironment(req)
+	}
+	if bypass(p.NoProxy, req.URL) {
+		return nil, nil
+	}
+
+	var (
+		u   *url.URL
+		err error
+	)
+	if p.PAC != "" {
+		u, err = findProxyPAC(req.Context(), p.PAC, req.URL)
+	}
+	if p.PAC == "" || (err != nil && p.URL != "") {
+		u, err = parseProxyURL(p.URL)
+	}
+	if err != nil || u == nil {
+		return nil, err
+	}
+
+	if p.Username != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+	return u, nil
+}
+
+func parseProxyURL(s string) (*url.URL, error) {
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	return url.Parse(s)
+}
+
+// bypass returns true if u matches one of the entries in noProxy.
+func bypass(noProxy string, u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	ip := net.ParseIP(host)
+
+	for _, entry := range strings.FieldsFunc(noProxy, func(r rune) bool { return r == ',' || r == ';' || r == ' ' }) {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "*":
+			return true
+		case entry == "<local>":
+			if !strings.Contains(host, ".") && ip == nil {
+				return true
+			}
+			continue
+		}
+
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && ipNet.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+
+		switch {
+		case strings.HasPrefix(entry, "*."):
+			entry = entry[1:]
+			fallthrough
+		case strings.HasPrefix(entry, "."):
+			if strings.HasSuffix(host, entry) || host == entry[1:] {
+				return true
+			}
+		case host == entry || strings.HasSuffix(host, "."+entry):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ContentTypeNDJSON is the content type of streamed uploads, which contain one JSON record per line.
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// StreamEncoder writes records as newline-delimited JSON.
+type StreamEncoder struct {
+	enc *json.Encoder
+	n   int
+}
+
+// NewStreamEncoder returns an encoder that writes records to w.
+func NewStreamEncoder(w io.Writer) *StreamEncoder {
+	return &StreamEncoder{enc: json.NewEncoder(w)}
+}
+
+// Encode writes a single record.
+func (e *StreamEncoder) Encode(v interface{}) error {
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	e.n++
+	return nil
+}
+
+// Count returns the number of records written.
+func (e *StreamEncoder) Count() int {
+	return e.n
+}
+
+// NewStreamRequest returns a POST request with a gzip compressed, newline-delimited JSON body that is produced by
+// write while the request is sent. The body is sent using chunked transfer encoding, such that memory use does not
+// depend on the number of records. If write fails, the request fails with the same error.
+//
+// The body can only be read once, so the request is not retried when the server rejects the credentials.
+//
+// Once the request has been sent, wait must be called. It blocks until write has returned, which may be after the
+// request failed, and returns the number of records written.
+func NewStreamRequest(ctx context.Context, url string, write func(*StreamEncoder) error) (req *http.Request, wait func() int, err error) {
+	pr, pw := io.Pipe()
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, pr)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", ContentTypeNDJSON)
+	req.Header.Set("Content-Encoding", "gzip")
+	req.ContentLength = -1
+
+	// the request is the only reader, and closes the body when it is done or has failed
+	body := &streamBody{PipeReader: pr, done: make(chan struct{})}
+	body.start = func() {
+		go func() {
+			defer close(body.done)
+
+			zw := gzip.NewWriter(pw)
+			enc := NewStreamEncoder(zw)
+			err := write(enc)
+			if err == nil {
+				err = zw.Close()
+			}
+			_ = pw.CloseWithError(err)
+			body.count = enc.Count()
+		}()
+	}
+	req.Body = body
+	return req, body.wait, nil
+}
+
+// streamBody starts producing the request body when it is first read, such that nothing is written for requests that
+// are never sent.
+type streamBody struct {
+	*io.PipeReader
+	once  sync.Once
+	start func()
+
+	// done is closed when the writer has returned, count is the number of records it wrote
+	done  chan struct{}
+	count int
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	b.once.Do(b.start)
+	return b.PipeReader.Read(p)
+}
+
+func (b *streamBody) Close() error {
+	// the writer is not started anymore, and fails on its next write if it was
+	b.once.Do(func() { close(b.done) })
+	return b.PipeReader.Close()
+}
+
+// wait blocks until the writer has returned, and returns the number of records it wrote.
+func (b *streamBody) wait() int {
+	// a request that was never sent has no writer
+	b.once.Do(func() { close(b.done) })
+	<-b.done
+	return b.count
+}
@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewStreamRequest(t *testing.T) {
+	var got []map[string]int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != ContentTypeNDJSON || r.Header.Get("Content-Encoding") != "gzip" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		if len(r.TransferEncoding) == 0 || r.TransferEncoding[0] != "chunked" {
+			w.WriteHeader(http.StatusLengthRequired)
+			return
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		scanner := bufio.NewScanner(zr)
+		for scanner.Scan() {
+			var rec map[string]int
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			got = append(got, rec)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient()
+	req, wait, err := NewStreamRequest(context.Background(), srv.URL, func(enc *StreamEncoder) error {
+		for i := 0; i < 1000; i++ {
+			if err := enc.Encode(map[string]int{"id": i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := client.Do(context.Background(), Options{}, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	count := wait()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Do() == 200, got %s", res.Status)
+	}
+	if len(got) != 1000 || count != 1000 || got[999]["id"] != 999 {
+		t.Errorf("received 1000 records, got %d (%d encoded)", len(got), count)
+	}
+
+	failure := errors.New("query failed")
+	req, wait, err = NewStreamRequest(context.Background(), srv.URL, func(enc *StreamEncoder) error {
+		_ = enc.Encode(map[string]int{"id": 1})
+		return failure
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(context.Background(), Options{}, req); !errors.Is(err, failure) {
+		t.Errorf("Do() == %v, got %v", failure, err)
+	}
+	if n := wait(); n != 1 {
+		t.Errorf("wait() == 1, got %d", n)
+	}
+
+	// the writer is joined when the server fails the request before reading all records
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer rejecting.Close()
+
+	var writerErr error
+	req, wait, err = NewStreamRequest(context.Background(), rejecting.URL, func(enc *StreamEncoder) error {
+		for {
+			if writerErr = enc.Encode(map[string]string{"padding": strings.Repeat("x", 1024)}); writerErr != nil {
+				return writerErr
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := client.Do(context.Background(), Options{}, req); err == nil {
+		_ = res.Body.Close()
+	}
+	wait()
+	if writerErr == nil {
+		t.Error("the writer has returned with an error")
+	}
+
+	// a request that is never sent does not wait
+	_, wait, err = NewStreamRequest(context.Background(), srv.URL, func(enc *StreamEncoder) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := wait(); n != 0 {
+		t.Errorf("wait() of an unsent request == 0, got %d", n)
+	}
+}
@@ -0,0 +1,190 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// TLS contains the settings for verifying the door2doc server.
+type TLS struct {
+	// CAFile is a PEM file with certificates that are trusted in addition to the system roots, such as the
+	// certificate of a TLS inspection proxy.
+	CAFile string
+
+	// Pins lists base64 encoded SHA-256 hashes of public keys. If set, the certificate chain of the server must
+	// contain at least one of these keys.
+	Pins []string
+
+	// ClientCert and ClientKey are PEM files with the client certificate and its private key, used when the server
+	// requires certificate-based authentication.
+	ClientCert string
+	ClientKey  string
+}
+
+// key identifies the settings, such that transports can be shared between requests with equal settings.
+func (t TLS) key() string {
+	return strings.Join([]string{t.CAFile, strings.Join(t.Pins, ","), t.ClientCert, t.ClientKey}, "\x00")
+}
+
+// config returns the TLS client configuration for these settings.
+func (t TLS) config() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if t.CAFile != "" {
+		certs, err := ReadCertificates(t.CAFile)
+		if err != nil {
+			return nil, &TLSConfigError{Cause: err}
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		for _, c := range certs {
+			pool.AddCert(c)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if t.ClientCert != "" && t.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			return nil, &TLSConfigError{Cause: err}
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(t.Pins) > 0 {
+		pins := make(map[string]bool, len(t.Pins))
+		for _, p := range t.Pins {
+			p, err := ParsePin(p)
+			if err != nil {
+				return nil, &TLSConfigError{Cause: err}
+			}
+			pins[p] = true
+		}
+
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			var got []string
+			for _, c := range cs.PeerCertificates {
+				pin := PublicKeyPin(c)
+				if pins[pin] {
+					return nil
+				}
+				got = append(got, pin)
+			}
+			return &PinError{Got: got}
+		}
+	}
+	return cfg, nil
+}
+
+// ReadCertificates reads all certificates from a PEM file.
+func ReadCertificates(file string) ([]*x509.Certificate, error) {
+	bs, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCertificates(bs)
+}
+
+// ParseCertificates parses all certificates in PEM encoded data. It fails if there are no certificates.
+func ParseCertificates(bs []byte) ([]*x509.Certificate, error) {
+	var res []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, bs = pem.Decode(bs)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	if len(res) == 0 {
+		return nil, ErrNoCertificates
+	}
+	return res, nil
+}
+
+// PublicKeyPin returns the base64 encoded SHA-256 hash of the public key of a certificate.
+func PublicKeyPin(c *x509.Certificate) string {
+	sum := sha256.Sum256(c.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ParsePin validates a public key pin, optionally prefixed by "sha256/", and returns it without prefix.
+func ParsePin(s string) (string, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "sha256/")
+	bs, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(bs) != sha256.Size {
+		return "", fmt.Errorf("invalid public key pin %q", s)
+	}
+	return s, nil
+}
+
+// ErrNoCertificates indicates that a PEM file does not contain any certificates.
+var ErrNoCertificates = errors.New("no certificates found")
+
+// TLSConfigError indicates that the TLS settings cannot be used.
+type TLSConfigError struct {
+	Cause error
+}
+
+func (e *TLSConfigError) Error() string {
+	return fmt.Sprintf("invalid TLS settings: %v", e.Cause)
+}
+
+func (e *TLSConfigError) Unwrap() error {
+	return e.Cause
+}
+
+// PinError indicates that none of the public keys presented by the server match the pinned keys.
+type PinError struct {
+	Got []string
+}
+
+func (e *PinError) Error() string {
+	return fmt.Sprintf("no pinned public key in certificate chain, got %s", strings.Join(e.Got, ", "))
+}
+
+// IsTLSError returns true if err indicates that the server could not be verified, or that the TLS settings are
+// invalid.
+func IsTLSError(err error) bool {
+	var (
+		configErr    *TLSConfigError
+		pinErr       *PinError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		verifyErr    *tls.CertificateVerificationError
+		headerErr    tls.RecordHeaderError
+	)
+	return errors.As(err, &configErr) ||
+		errors.As(err, &pinErr) ||
+		errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.As(err, &verifyErr) ||
+		errors.As(err, &headerErr) ||
+		IsTLSRejected(err)
+}
+
+// IsTLSRejected returns true if the server aborted the TLS handshake, for example because it did not accept the
+// client certificate.
+func IsTLSRejected(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "remote error"
+}
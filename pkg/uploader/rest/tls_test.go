@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParsePin(t *testing.T) {
+	for name, test := range map[string]struct {
+		Pin   string
+		Want  string
+		Error bool
+	}{
+		"plain":     {Pin: "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", Want: "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+		"prefix":    {Pin: " sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU= ", Want: "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+		"too short": {Pin: "47DEQpj8HBSa+/TImW+5JCeu", Error: true},
+		"invalid":   {Pin: "not a pin", Error: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParsePin(test.Pin)
+			if (err != nil) != test.Error {
+				t.Fatalf("ParsePin(%q) error == %t, got %v", test.Pin, test.Error, err)
+			}
+			if got != test.Want {
+				t.Errorf("ParsePin(%q) == %q, got %q", test.Pin, test.Want, got)
+			}
+		})
+	}
+}
+
+func TestParseCertificates(t *testing.T) {
+	if _, err := ParseCertificates([]byte("no certificates")); err != ErrNoCertificates {
+		t.Errorf("ParseCertificates() == ErrNoCertificates, got %v", err)
+	}
+}
+
+func TestDo_TLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cert := srv.Certificate()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	pin := PublicKeyPin(cert)
+
+	for name, test := range map[string]struct {
+		TLS   TLS
+		Error bool
+	}{
+		"untrusted":   {Error: true},
+		"trusted":     {TLS: TLS{CAFile: caFile}},
+		"pinned":      {TLS: TLS{CAFile: caFile, Pins: []string{"sha256/" + pin}}},
+		"wrong pin":   {TLS: TLS{CAFile: caFile, Pins: []string{strings.Repeat("A", 43) + "="}}, Error: true},
+		"missing ca":  {TLS: TLS{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, Error: true},
+		"invalid pin": {TLS: TLS{Pins: []string{"x"}}, Error: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := NewClient().Do(context.Background(), Options{TLS: test.TLS}, req)
+			if err == nil {
+				_ = res.Body.Close()
+			}
+			if (err != nil) != test.Error {
+				t.Fatalf("Do() error == %t, got %v", test.Error, err)
+			}
+			if err != nil && !IsTLSError(err) {
+				t.Errorf("IsTLSError(%v) == true, got false", err)
+			}
+
+			var pinErr *PinError
+			if name == "wrong pin" && (!errors.As(err, &pinErr) || len(pinErr.Got) == 0 || pinErr.Got[0] != pin) {
+				t.Errorf("Do() == PinError with %s, got %v", pin, err)
+			}
+		})
+	}
+}
+
+func TestClient_Reuse(t *testing.T) {
+	var conns int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	client := NewClient()
+	for _, opts := range []Options{{}, {}, {Proxy: Proxy{NoProxy: "*", URL: "http://proxy.invalid:8080"}}, {Proxy: Proxy{NoProxy: "*", URL: "http://proxy.invalid:8080"}}} {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := client.Do(context.Background(), opts, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}
+	if got := atomic.LoadInt32(&conns); got != 2 {
+		t.Errorf("connections == 2, got %d", got)
+	}
+}
@@ -2,6 +2,9 @@ package uploader
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -10,8 +13,10 @@ import (
 	_ "time/tzdata"
 
 	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/history"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/hl7"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/web"
 	"github.com/kardianos/service"
 )
@@ -20,16 +25,20 @@ import (
 type Service struct {
 	dev      bool
 	version  string
+	listen   string
 	shutdown context.CancelFunc
 	srv      *http.Server
+	redirect *http.Server
 	cfg      *config.Configuration
 }
 
-// NewService creates a new Service instance.
-func NewService(development bool, version string) *Service {
+// NewService creates a new Service instance. If listen is set, the configuration interface is served on that address
+// instead of the configured one.
+func NewService(development bool, version string, listen string) *Service {
 	return &Service{
 		dev:     development,
 		version: version,
+		listen:  listen,
 	}
 }
 
@@ -42,6 +51,7 @@ func (s *Service) Start(svc service.Service) error {
 
 	// set up history
 	h := history.New()
+	messages := hl7.NewLog()
 
 	// load configuration
 	s.cfg = config.NewConfiguration()
@@ -60,22 +70,60 @@ func (s *Service) Start(svc service.Service) error {
 	}
 
 	// create HTTP server for configuration purposes
-	handler, err := web.NewServeMux(s.dev, s.version, s.cfg, h)
+	handler, err := web.NewServeMux(s.dev, s.version, s.cfg, h, messages, location)
 	if err != nil {
 		return err
 	}
 
-	mux := http.DefaultServeMux
-	mux.Handle("/", handler)
+	ws := s.cfg.WebServer()
+	addr := ws.ListenAddress()
+	if s.listen != "" {
+		addr = s.listen
+	} else if ws.Address == "" {
+		// older versions listened on all interfaces by default, so explain why other machines can no longer connect
+		dlog.Info("Configuration server only accepts connections from this machine. To allow other machines, set the listen address under Access, or start the service with -listen :17226")
+	}
+
 	s.srv = &http.Server{
-		Addr:    ":17226",
-		Handler: mux,
+		Addr:    addr,
+		Handler: web.AllowedClients(s.cfg, handler),
 	}
+	s.srv.RegisterOnShutdown(handler.Close)
 
 	// start listening, and fail service start if port is occupied
 	ln, err := net.Listen("tcp", s.srv.Addr)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to serve configuration interface on %s, choose another address using the -listen flag: %w", s.srv.Addr, err)
+	}
+
+	// serve over HTTPS if configured or reachable from other machines, redirecting plain HTTP requests on the same port
+	scheme := "http"
+	if ws.UsesTLS(s.srv.Addr) {
+		cert, err := ws.Certificate()
+		if err != nil {
+			_ = ln.Close()
+			return fmt.Errorf("while loading configuration server certificate: %w", err)
+		}
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+			dlog.Info("Configuration server certificate %s has SHA-256 fingerprint %s", leaf.Subject, config.Fingerprint(leaf))
+		}
+		s.srv.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+		scheme = "https"
+
+		if ws.RedirectsHTTP(s.srv.Addr) {
+			var plainLn net.Listener
+			ln, plainLn = web.SplitListener(ln)
+			s.redirect = &http.Server{Handler: web.RedirectHTTPS()}
+			go func() {
+				// the listener is closed along with the configuration server
+				if err := s.redirect.Serve(plainLn); err != nil && err != http.ErrServerClosed && !errors.Is(err, net.ErrClosed) {
+					dlog.Error("While redirecting to configuration server: %v", err)
+				}
+			}()
+		}
 	}
 
 	// create service context
@@ -88,10 +136,15 @@ func (s *Service) Start(svc service.Service) error {
 		if strings.HasPrefix(addr, ":") {
 			addr = "localhost" + addr
 		}
-		addr = "http://" + addr
+		addr = scheme + "://" + addr
 
 		dlog.Info("Starting configuration server on %s", addr)
-		err := s.srv.Serve(ln)
+		var err error
+		if s.srv.TLSConfig != nil {
+			err = s.srv.ServeTLS(ln, "", "")
+		} else {
+			err = s.srv.Serve(ln)
+		}
 
 		if err != nil && err != http.ErrServerClosed {
 			dlog.Error("While running configuration server: %v", err)
@@ -103,6 +156,8 @@ func (s *Service) Start(svc service.Service) error {
 		// validate configuration
 		s.cfg.UpdateBaseValidation(ctx)
 
+		go s.runHL7(ctx, uploader, messages)
+
 		err := s.run(ctx, uploader)
 		switch {
 		case err == context.Canceled:
@@ -129,6 +184,9 @@ func (s *Service) Stop(svc service.Service) error {
 	defer cancel()
 
 	err := s.srv.Shutdown(ctx)
+	if s.redirect != nil {
+		_ = s.redirect.Close()
+	}
 	if err != nil {
 		dlog.Error("Failed to stop configuration server: %v", err)
 		return err
@@ -148,11 +206,96 @@ func (s *Service) run(ctx context.Context, uploader *Uploader) error {
 			sleep = s.cfg.Interval()
 		}
 
-		// sleep until the next iteration
+		// sleep until the next iteration, running datasets on request in the meantime
+		next := time.After(sleep)
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case dataset := <-s.cfg.RunRequests():
+				s.cfg.Started(dataset)
+				dlog.Info("Running %s upload on request", dataset)
+				uploader.UploadDataset(ctx, dataset)
+			case <-next:
+				break wait
+			}
+		}
+	}
+}
+
+// runHL7 runs the HL7 listener while the configuration is active, HL7 is the visitor source and visits are not
+// paused. The listener is restarted when its address changes.
+func (s *Service) runHL7(ctx context.Context, uploader *Uploader, messages *hl7.Log) {
+	l := &hl7.Listener{
+		Location: uploader.Location,
+		Log:      messages,
+		Upload: func(ctx context.Context, rec *db.VisitorRecord) error {
+			return uploader.UploadVisitors(ctx, config.SourceHL7, []db.VisitorRecord{*rec})
+		},
+	}
+
+	var (
+		addr    string
+		lastErr string
+		stop    context.CancelFunc
+		done    chan struct{}
+	)
+	for {
+		want := ""
+		_, paused := s.cfg.Paused(config.DatasetVisits)
+		if s.cfg.Active() && !paused && s.cfg.VisitorSource() == config.SourceHL7 {
+			want = s.cfg.HL7Address()
+		}
+
+		// restart the listener if it stopped, or if its address has changed
+		if done != nil {
+			select {
+			case <-done:
+				addr = ""
+			default:
+			}
+		}
+		if want != addr {
+			if stop != nil {
+				stop()
+				<-done
+				stop, done = nil, nil
+				dlog.Info("Stopped HL7 listener")
+			}
+			addr = ""
+
+			if want != "" {
+				ln, err := net.Listen("tcp", want)
+				if err != nil {
+					if err.Error() != lastErr {
+						dlog.Error("While starting HL7 listener: %v", err)
+						lastErr = err.Error()
+					}
+				} else {
+					dlog.Info("Receiving HL7 messages on %s", want)
+					addr, lastErr = want, ""
+
+					listenCtx, cancel := context.WithCancel(ctx)
+					stop, done = cancel, make(chan struct{})
+					go func(done chan struct{}) {
+						defer close(done)
+						if err := l.Serve(listenCtx, ln); err != nil && err != context.Canceled {
+							dlog.Error("While receiving HL7 messages: %v", err)
+						}
+					}(done)
+				}
+			}
+		}
+
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(sleep):
+			if stop != nil {
+				stop()
+				<-done
+			}
+			return
+		case <-time.After(5 * time.Second):
 		}
 	}
 }
@@ -8,12 +8,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dropfolder"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/fhir"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/history"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
 )
@@ -23,99 +26,406 @@ type Uploader struct {
 	Location      *time.Location
 	History       *history.History
 
-	mu         sync.Mutex
-	lastDriver string
-	lastDSN    string
-	db         *sql.DB
+	mu             sync.Mutex
+	lastConnection db.ConnectionData
+	db             *sql.DB
+
+	// fhirSince is the most recent update read from the FHIR source at fhirURL
+	fhirURL   string
+	fhirSince time.Time
 }
 
+// fhirLookback determines how far back encounters are read from the FHIR source after the service starts.
+const fhirLookback = 24 * time.Hour
+
+// streamTimeout limits the time spent on a streamed upload, which reads the results of the visitor query while they
+// are uploaded. The query timeout only applies to executing the query.
+const streamTimeout = 4 * time.Hour
+
 // Upload uses a configuration to run a query on the target database, convert the results to JSON, and upload
-// them to the door2doc integration service.
+// them to the door2doc integration service. Datasets that have been paused are skipped.
 func (u *Uploader) Upload(ctx context.Context) {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
-	if err := u.upload(ctx, config.PathVisitorUpload, u.executeVisitorQuery); err != nil {
-		dlog.Error("While processing visitor upload: %v", err)
+	for _, dataset := range config.Datasets {
+		if _, paused := u.Configuration.Paused(dataset); !paused {
+			u.uploadDataset(ctx, dataset)
+		}
 	}
+	if u.Configuration.DropFolder().Folder != "" {
+		if err := u.processDropFolder(ctx, ""); err != nil {
+			dlog.Error("While processing drop folder: %v", err)
+		}
+	}
+}
+
+// UploadDataset uploads a single dataset, including any files for it in the drop folder. It waits for a running
+// upload to complete.
+func (u *Uploader) UploadDataset(ctx context.Context, dataset string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
 
-	if u.Configuration.RadiologieQuery() != "" {
-		if err := u.upload(ctx, config.PathRadiologieUpload, u.executeRadiologieQuery); err != nil {
-			dlog.Error("While processing radiologie upload: %v", err)
+	u.uploadDataset(ctx, dataset)
+	if u.Configuration.DropFolder().Folder != "" {
+		if err := u.processDropFolder(ctx, dataset); err != nil {
+			dlog.Error("While processing drop folder: %v", err)
 		}
 	}
-	if u.Configuration.LabQuery() != "" {
-		if err := u.upload(ctx, config.PathLabUpload, u.executeLabQuery); err != nil {
-			dlog.Error("While processing lab upload: %v", err)
+}
+
+func (u *Uploader) uploadDataset(ctx context.Context, dataset string) {
+	switch dataset {
+	case config.DatasetVisits:
+		switch {
+		case u.Configuration.VisitorSource() == config.SourceFHIR:
+			if err := u.pollFHIR(ctx); err != nil {
+				dlog.Error("While processing visitor upload from FHIR server: %v", err)
+			}
+		case u.Configuration.VisitorSource() != config.SourceDatabase:
+			// visits are received as they happen, or read from the drop folder
+		case u.Configuration.StreamUploads():
+			if err := u.streamVisitors(ctx); err != nil {
+				dlog.Error("While processing visitor upload: %v", err)
+			}
+		default:
+			if err := u.upload(ctx, config.PathVisitorUpload, u.executeVisitorQuery); err != nil {
+				dlog.Error("While processing visitor upload: %v", err)
+			}
+		}
+	case config.DatasetRadiologie:
+		if u.Configuration.RadiologieQuery() != "" {
+			if err := u.upload(ctx, config.PathRadiologieUpload, u.executeRadiologieQuery); err != nil {
+				dlog.Error("While processing radiologie upload: %v", err)
+			}
+		}
+	case config.DatasetLab:
+		if u.Configuration.LabQuery() != "" {
+			if err := u.upload(ctx, config.PathLabUpload, u.executeLabQuery); err != nil {
+				dlog.Error("While processing lab upload: %v", err)
+			}
+		}
+	case config.DatasetConsult:
+		if u.Configuration.ConsultQuery() != "" {
+			if err := u.upload(ctx, config.PathConsultUpload, u.executeConsultQuery); err != nil {
+				dlog.Error("While processing consult upload: %v", err)
+			}
 		}
 	}
-	if u.Configuration.ConsultQuery() != "" {
-		if err := u.upload(ctx, config.PathConsultUpload, u.executeConsultQuery); err != nil {
-			dlog.Error("While processing consult upload: %v", err)
+}
+
+// processDropFolder uploads the CSV files in the drop folder, oldest first. If dataset is set, only files for that
+// dataset are uploaded, otherwise files for paused datasets are skipped. Files that cannot be read are moved to the
+// failed folder. If an upload fails, the file is left in place and the remaining files are tried next time.
+func (u *Uploader) processDropFolder(ctx context.Context, dataset string) error {
+	s := u.Configuration.DropFolder()
+	names, err := dropfolder.Pending(s.Folder, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		d, err := dropfolder.Dataset(name)
+		if err != nil {
+			if err := u.markFailed(s.Folder, name, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, paused := u.Configuration.Paused(d); (dataset == "" && paused) || (dataset != "" && d != dataset) {
+			continue
+		}
+
+		start := time.Now()
+		b, err := dropfolder.Read(s, name, u.Location)
+		var vRecs interface{}
+		if err == nil {
+			vRecs, err = convertBatch(b, u.Location)
+		}
+		if err != nil {
+			if err := u.markFailed(s.Folder, name, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		path := config.DatasetPath(b.Dataset)
+		evt := u.History.NewEvent(config.SourceCSV + ":" + path)
+		evt.QueryDuration = time.Since(start)
+		evt.Size = b.Len()
+		err = u.uploadRecords(ctx, evt, path, vRecs)
+		u.History.Finish(evt)
+		if err != nil {
+			return err
 		}
+		if err := dropfolder.MarkProcessed(s.Folder, name, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markFailed records that a file in the drop folder could not be read, and moves it to the failed folder.
+func (u *Uploader) markFailed(folder, name string, cause error) error {
+	evt := u.History.NewEvent(config.SourceCSV + ":" + name)
+	evt.Error = fmt.Errorf("%s: %w", name, cause)
+	u.History.Finish(evt)
+	return dropfolder.MarkFailed(folder, name, cause, time.Now())
+}
+
+// convertBatch converts the records read from a CSV file for upload.
+func convertBatch(b *dropfolder.Batch, loc *time.Location) (interface{}, error) {
+	switch b.Dataset {
+	case dropfolder.DatasetVisits:
+		return rest.VisitorRecordsFromDB(b.Visits, loc)
+	case dropfolder.DatasetRadiologie:
+		return rest.RadiologieRecordsFromDB(b.Radiologie, loc)
+	case dropfolder.DatasetLab:
+		return rest.LabRecordsFromDB(b.Lab, loc)
+	case dropfolder.DatasetConsult:
+		return rest.ConsultRecordsFromDB(b.Consult, loc)
 	}
+	return nil, dropfolder.ErrUnknownDataset
 }
 
 type queryFunc func(ctx context.Context) (interface{}, int, error)
 
 func (u *Uploader) upload(ctx context.Context, path string, q queryFunc) error {
 	evt := u.History.NewEvent(path)
+	defer u.History.Finish(evt)
 
-	// ensure DB connection
-	if err := u.ensureDB(); err != nil {
+	// run query, retrying transient errors on a fresh connection
+	var (
+		vRecs interface{}
+		size  int
+	)
+	start := time.Now()
+	err := db.Retry(ctx, db.DefaultRetryPolicy, func(ctx context.Context) error {
+		if err := u.ensureDB(ctx); err != nil {
+			return err
+		}
+
+		var err error
+		vRecs, size, err = q(ctx)
+		if db.IsConnectionError(err) {
+			u.closeDB()
+		}
+		return err
+	})
+	if err != nil {
 		evt.Error = err
 		return err
 	}
+	evt.QueryDuration = time.Since(start)
+	evt.Size = size
+
+	return u.uploadRecords(ctx, evt, path, vRecs)
+}
 
-	// run query
+// pollFHIR uploads the encounters that were updated on the FHIR source since the previous poll. The next poll only
+// starts after the last update uploaded, such that failed uploads are retried.
+func (u *Uploader) pollFHIR(ctx context.Context) error {
+	evt := u.History.NewEvent(config.SourceFHIR + ":" + config.PathVisitorUpload)
+	defer u.History.Finish(evt)
+	source := u.Configuration.FHIRSource()
+	if u.fhirSince.IsZero() || u.fhirURL != source.URL {
+		u.fhirURL, u.fhirSince = source.URL, time.Now().Add(-fhirLookback)
+	}
+
+	var recs []db.VisitorRecord
 	start := time.Now()
-	vRecs, size, err := q(ctx)
+	opts := rest.Options{Proxy: u.Configuration.Proxy(), Auth: source.Authenticator()}
+	since, err := fhir.SearchEncounters(ctx, u.Configuration.Client(), opts, source.URL, u.fhirSince, func(e *fhir.Encounter) error {
+		rec, err := fhir.VisitorRecord(e, u.Location)
+		if err != nil {
+			dlog.Error("Skipping encounter %s: %v", e.ID, err)
+			return nil
+		}
+		recs = append(recs, *rec)
+		return nil
+	})
 	if err != nil {
 		evt.Error = err
 		return err
 	}
 	evt.QueryDuration = time.Since(start)
-	evt.Size = size
 
+	if err := u.uploadVisitors(ctx, evt, recs); err != nil {
+		return err
+	}
+	u.fhirSince = since
+	return nil
+}
+
+// UploadVisitors converts visitor records that were received from a source other than the database, and uploads them.
+// It does not use u.mu, such that visits received over HL7 are not held up by a scheduled upload: it only uses the
+// configuration and the history, which are safe for concurrent use, and the records and event of this call.
+func (u *Uploader) UploadVisitors(ctx context.Context, source string, recs []db.VisitorRecord) error {
+	evt := u.History.NewEvent(source + ":" + config.PathVisitorUpload)
+	defer u.History.Finish(evt)
+
+	return u.uploadVisitors(ctx, evt, recs)
+}
+
+func (u *Uploader) uploadVisitors(ctx context.Context, evt *history.Event, recs []db.VisitorRecord) error {
+	vRecs, err := rest.VisitorRecordsFromDB(recs, u.Location)
+	if err != nil {
+		evt.Error = err
+		return err
+	}
+	evt.Size = len(vRecs)
+
+	return u.uploadRecords(ctx, evt, config.PathVisitorUpload, vRecs)
+}
+
+// uploadRecords uploads converted records as JSON, and exports them to the FHIR server if one is configured.
+func (u *Uploader) uploadRecords(ctx context.Context, evt *history.Event, path string, vRecs interface{}) error {
 	buf := new(bytes.Buffer)
 	enc := json.NewEncoder(buf)
 	enc.SetIndent("", "  ")
 	if err := enc.Encode(vRecs); err != nil {
+		evt.Error = err
 		return err
 	}
 	evt.JSON = buf.String()
 
 	// upload JSON to upload service
-	start = time.Now()
+	u.History.SetPhase(evt, history.PhaseUploading)
+	start := time.Now()
 	if err := u.UploadJSON(ctx, buf, path, false); err != nil {
 		evt.Error = err
 		return err
 	}
 	evt.UploadDuration = time.Since(start)
+
+	if server := u.Configuration.FHIRServer(); server != "" {
+		if err := u.exportFHIR(ctx, server, path, vRecs); err != nil {
+			dlog.Error("While exporting %s to FHIR server: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// exportFHIR sends the converted records to a FHIR server, as transaction bundles.
+func (u *Uploader) exportFHIR(ctx context.Context, server, path string, vRecs interface{}) error {
+	var resources []fhir.Resource
+	switch recs := vRecs.(type) {
+	case []rest.VisitorRecord:
+		resources = fhir.EncountersFromVisits(recs)
+	case []rest.OrderRecord:
+		kind := map[string]fhir.OrderKind{
+			config.PathRadiologieUpload: fhir.OrderRadiology,
+			config.PathLabUpload:        fhir.OrderLab,
+			config.PathConsultUpload:    fhir.OrderConsult,
+		}[path]
+		resources = fhir.ServiceRequestsFromOrders(kind, recs)
+	default:
+		return fmt.Errorf("unsupported records %T", vRecs)
+	}
+
+	evt := u.History.NewEvent("fhir-export:" + path)
+	defer u.History.Finish(evt)
+	u.History.SetPhase(evt, history.PhaseUploading)
+	evt.Size = len(resources)
+
+	bundles, err := fhir.Transaction(resources)
+	if err != nil {
+		evt.Error = err
+		return err
+	}
+	start := time.Now()
+	for _, b := range bundles {
+		if _, err := fhir.Send(ctx, u.Configuration.Client(), rest.Options{Proxy: u.Configuration.Proxy()}, server, b); err != nil {
+			evt.Error = err
+			return err
+		}
+	}
+	evt.UploadDuration = time.Since(start)
 	return nil
 }
 
-func (u *Uploader) ensureDB() error {
+// streamVisitors uploads the results of the visitor query while they are read from the database, such that memory use
+// does not depend on the number of records.
+func (u *Uploader) streamVisitors(ctx context.Context) error {
+	evt := u.History.NewEvent(config.PathVisitorUpload)
+	defer u.History.Finish(evt)
+
+	ctx, cancel := context.WithTimeout(ctx, streamTimeout)
+	defer cancel()
+
+	err := db.Retry(ctx, db.DefaultRetryPolicy, u.ensureDB)
+	if err != nil {
+		evt.Error = err
+		return err
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		evt.Error = err
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			dlog.Error("Failure while rolling back transaction: %v", err)
+		}
+	}()
+
+	// records are uploaded while they are read
+	u.History.SetPhase(evt, history.PhaseUploading)
+	// the writer runs concurrently with the request, so evt is only updated once UploadStream has joined it
+	var queryDuration time.Duration
+	start := time.Now()
+	size, err := u.UploadStream(ctx, config.PathVisitorUpload, false, func(enc *rest.StreamEncoder) error {
+		_, err := db.StreamVisitorQuery(ctx, tx, u.Configuration.VisitorQuery(), u.Configuration.Timeout(), func(rec *db.VisitorRecord) error {
+			vRec, err := rest.VisitorRecordFromDB(rec, u.Location)
+			if err != nil {
+				return err
+			}
+			return enc.Encode(vRec)
+		})
+		queryDuration = time.Since(start)
+		return err
+	})
+	evt.QueryDuration, evt.Size = queryDuration, size
+	if err != nil {
+		if db.IsConnectionError(err) {
+			u.closeDB()
+		}
+		evt.Error = err
+		return err
+	}
+	evt.UploadDuration = time.Since(start)
+	return nil
+}
+
+// ensureDB connects to the database if the connection has changed or was lost. The connection stays with a failover
+// host until that host fails as well.
+func (u *Uploader) ensureDB(ctx context.Context) error {
 	conn := u.Configuration.Connection()
-	driver, dsn := conn.Driver, conn.DSN()
-	if driver == u.lastDriver && dsn == u.lastDSN && u.db != nil {
+	if u.db != nil && reflect.DeepEqual(conn, u.lastConnection) {
 		return nil
 	}
+	u.closeDB()
 
-	if u.db != nil {
-		dlog.Close(u.db)
-	}
+	dbCtx, cancel := context.WithTimeout(ctx, u.Configuration.Timeout())
+	defer cancel()
 
-	var err error
-	u.db, err = sql.Open(driver, dsn)
+	d, _, err := db.Open(dbCtx, conn)
 	if err != nil {
 		return err
 	}
-	u.lastDriver = driver
-	u.lastDSN = dsn
+	u.db = d
+	u.lastConnection = conn
 	return nil
 }
 
+func (u *Uploader) closeDB() {
+	if u.db != nil {
+		dlog.Close(u.db)
+		u.db = nil
+	}
+}
+
 func (u *Uploader) executeVisitorQuery(ctx context.Context) (interface{}, int, error) {
 	tx, err := u.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -127,7 +437,7 @@ func (u *Uploader) executeVisitorQuery(ctx context.Context) (interface{}, int, e
 		}
 	}()
 
-	records, err := db.ExecuteVisitorQuery(ctx, tx, u.Configuration.VisitorQuery(), u.Configuration.Timeout())
+	records, err := db.ExecuteVisitorQuery(ctx, tx, u.Configuration.VisitorQuery(), u.Configuration.Timeout(), 0)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -154,7 +464,7 @@ func (u *Uploader) executeRadiologieQuery(ctx context.Context) (interface{}, int
 		}
 	}()
 
-	records, err := db.ExecuteRadiologieQuery(ctx, tx, u.Configuration.RadiologieQuery(), u.Configuration.Timeout())
+	records, err := db.ExecuteRadiologieQuery(ctx, tx, u.Configuration.RadiologieQuery(), u.Configuration.Timeout(), 0)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -181,7 +491,7 @@ func (u *Uploader) executeLabQuery(ctx context.Context) (interface{}, int, error
 		}
 	}()
 
-	records, err := db.ExecuteLabQuery(ctx, tx, u.Configuration.LabQuery(), u.Configuration.Timeout())
+	records, err := db.ExecuteLabQuery(ctx, tx, u.Configuration.LabQuery(), u.Configuration.Timeout(), 0)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -208,7 +518,7 @@ func (u *Uploader) executeConsultQuery(ctx context.Context) (interface{}, int, e
 		}
 	}()
 
-	records, err := db.ExecuteConsultQuery(ctx, tx, u.Configuration.ConsultQuery(), u.Configuration.Timeout())
+	records, err := db.ExecuteConsultQuery(ctx, tx, u.Configuration.ConsultQuery(), u.Configuration.Timeout(), 0)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -225,19 +535,50 @@ func (u *Uploader) executeConsultQuery(ctx context.Context) (interface{}, int, e
 }
 
 func (u *Uploader) UploadJSON(ctx context.Context, json *bytes.Buffer, path string, importMode bool) error {
-	req, err := http.NewRequest(http.MethodPost, config.Server, json)
+	endpoint, err := u.Configuration.Endpoint(path)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, json)
 	if err != nil {
 		return err
 	}
-	req.URL.Path = path
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Connection", "close")
 
 	if importMode {
 		req.URL.RawQuery = "import=true"
 	}
 
+	return u.send(ctx, req)
+}
+
+// UploadStream uploads the records written by write as newline-delimited JSON, while they are produced. It returns
+// the number of records uploaded. It only returns once write has returned, also if the upload fails.
+func (u *Uploader) UploadStream(ctx context.Context, path string, importMode bool, write func(*rest.StreamEncoder) error) (int, error) {
+	endpoint, err := u.Configuration.Endpoint(path)
+	if err != nil {
+		return 0, err
+	}
+
+	req, wait, err := rest.NewStreamRequest(ctx, endpoint, write)
+	if err != nil {
+		return 0, err
+	}
+	if importMode {
+		req.URL.RawQuery = "import=true"
+	}
+
+	err = u.send(ctx, req)
+	n := wait()
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// send sends an upload request, and checks the response.
+func (u *Uploader) send(ctx context.Context, req *http.Request) error {
 	res, err := u.Configuration.Do(ctx, req)
 	if err != nil {
 		return err
@@ -0,0 +1,330 @@
+package uploader
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dropfolder"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/fhir"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/history"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+)
+
+const (
+	demoDatabase = "../../data/sql/sqlite/demo.db"
+	visitsHeader = "sehid;sehmutid;locatie;afdeling;aangemaakt;binnenkomstdatum;binnenkomsttijd;triagetijd;naarkamertijd;eerstecontacttijd;artsklaartijd;gereedopnametijd;vertrektijd;eindtijd;mutatieeindtijd;mutatiestatus;kamer;bed;ingangsklacht;specialisme;triage;vervoerder;geboortedatum;opnameafdeling;opnamespecialisme;herkomst;ontslagbestemming;vervallen\n"
+	visitsRow    = "42;7;ZKH;seh;2020-03-01 10:14:00;2020-03-01 00:00:00;10:15;10:20:00;;;;;12:00;;;A;K3;2;buikpijn;CHI;U2;;1975-06-15 00:00:00;;;5;VPL;False\n"
+)
+
+// door2doc records the number of records uploaded to each path.
+type door2doc struct {
+	*httptest.Server
+	status int
+
+	mu      sync.Mutex
+	uploads map[string][]int
+}
+
+func newDoor2doc(t *testing.T, status int) *door2doc {
+	d := &door2doc{status: status, uploads: make(map[string][]int)}
+	d.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		if r.Header.Get("Content-Type") == rest.ContentTypeNDJSON {
+			for s := bufio.NewScanner(r.Body); s.Scan(); n++ {
+			}
+		} else {
+			var recs []json.RawMessage
+			if err := json.NewDecoder(r.Body).Decode(&recs); err != nil {
+				t.Errorf("Invalid upload to %s: %v", r.URL.Path, err)
+			}
+			n = len(recs)
+		}
+
+		d.mu.Lock()
+		d.uploads[r.URL.Path] = append(d.uploads[r.URL.Path], n)
+		d.mu.Unlock()
+		w.WriteHeader(d.status)
+	}))
+	t.Cleanup(d.Close)
+	return d
+}
+
+// Uploads returns the number of records of each upload to path.
+func (d *door2doc) Uploads(path string) []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.uploads[path]
+}
+
+// newUploader returns an uploader for data, which is used as the active configuration.
+func newUploader(t *testing.T, data config.DataV2) *Uploader {
+	data.Version = 2
+	bs, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewConfiguration()
+	if err := json.Unmarshal(bs, cfg); err != nil {
+		t.Fatal(err)
+	}
+	return &Uploader{Configuration: cfg, Location: time.UTC, History: history.New()}
+}
+
+// writeDropFile writes a file to the drop folder that is old enough to be read.
+func writeDropFile(t *testing.T, folder, name, content string) {
+	file := filepath.Join(folder, name)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(file, old, old); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
+
+func TestUploader_UploadVisitors(t *testing.T) {
+	rec := db.VisitorRecord{
+		Bezoeknummer:     42,
+		MutatieID:        7,
+		Locatie:          "ZKH",
+		Afdeling:         "seh",
+		Aangemeld:        time.Date(2020, 3, 1, 10, 14, 0, 0, time.UTC),
+		BinnenkomstDatum: "2020-03-01",
+		BinnenkomstTijd:  "10:15",
+	}
+	for name, test := range map[string]struct {
+		Status int
+		Error  bool
+	}{
+		"uploaded": {Status: http.StatusOK},
+		"rejected": {Status: http.StatusBadRequest, Error: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := newDoor2doc(t, test.Status)
+			u := newUploader(t, config.DataV2{Server: srv.URL, VisitorSource: config.SourceHL7})
+
+			err := u.UploadVisitors(context.Background(), config.SourceHL7, []db.VisitorRecord{rec})
+			if (err != nil) != test.Error {
+				t.Fatalf("UploadVisitors() error == %t, got %v", test.Error, err)
+			}
+			if got := srv.Uploads(config.PathVisitorUpload); len(got) != 1 || got[0] != 1 {
+				t.Errorf("Expected a single upload of 1 record, got %v", got)
+			}
+
+			evts := u.History.Events()
+			if len(evts) != 1 {
+				t.Fatalf("Expected 1 event, got %d", len(evts))
+			}
+			evt := evts[0]
+			if evt.Type != config.SourceHL7+":"+config.PathVisitorUpload || evt.Phase != "" || evt.Size != 1 || (evt.Error != nil) != test.Error {
+				t.Errorf("Unexpected event %+v", evt)
+			}
+		})
+	}
+}
+
+func TestUploader_PollFHIR(t *testing.T) {
+	updated := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	arrived := updated.Add(-time.Hour)
+	e := fhir.EncounterFromVisit(&rest.VisitorRecord{Bezoeknummer: 42, Locatie: "ZKH", Binnenkomst: &arrived})
+	e.Meta = &fhir.Meta{VersionID: "1", LastUpdated: &updated}
+	resource, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu       sync.Mutex
+		searches []string
+	)
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		since := r.URL.Query().Get("_lastUpdated")
+		mu.Lock()
+		searches = append(searches, since)
+		mu.Unlock()
+
+		b := fhir.Bundle{ResourceType: "Bundle", Type: "searchset"}
+		if since < "gt"+updated.Format(time.RFC3339) {
+			b.Entry = []fhir.BundleEntry{{Resource: resource}}
+		}
+		_ = json.NewEncoder(w).Encode(b)
+	}))
+	defer source.Close()
+
+	srv := newDoor2doc(t, http.StatusOK)
+	u := newUploader(t, config.DataV2{
+		Server:        srv.URL,
+		VisitorSource: config.SourceFHIR,
+		FHIRSource:    fhir.SourceSettings{URL: source.URL},
+	})
+
+	u.Upload(context.Background())
+	u.Upload(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(searches) != 2 || searches[1] != "gt"+updated.Format(time.RFC3339) {
+		t.Errorf("Expected the second poll to start at the last update %v, got %v", updated, searches)
+	}
+	if got := srv.Uploads(config.PathVisitorUpload); len(got) != 2 || got[0] != 1 || got[1] != 0 {
+		t.Errorf("Expected uploads of 1 and 0 records, got %v", got)
+	}
+	if evt := u.History.Events()[1]; evt.Type != config.SourceFHIR+":"+config.PathVisitorUpload || evt.Size != 1 || evt.Error != nil {
+		t.Errorf("Unexpected event %+v", evt)
+	}
+}
+
+func TestUploader_ProcessDropFolder(t *testing.T) {
+	for name, test := range map[string]struct {
+		Status    int
+		Processed []string
+		Failed    []string
+		Pending   []string
+	}{
+		"uploaded": {
+			Status:    http.StatusOK,
+			Processed: []string{"bezoeken-1.csv"},
+			Failed:    []string{"export.csv", "export.csv.error.txt"},
+		},
+		"rejected": {
+			Status:  http.StatusInternalServerError,
+			Pending: []string{"bezoeken-1.csv", "export.csv"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder := t.TempDir()
+			writeDropFile(t, folder, "bezoeken-1.csv", visitsHeader+visitsRow)
+			writeDropFile(t, folder, "export.csv", visitsHeader)
+
+			srv := newDoor2doc(t, test.Status)
+			u := newUploader(t, config.DataV2{
+				Server:        srv.URL,
+				VisitorSource: config.SourceCSV,
+				DropFolder:    dropfolder.Settings{Folder: folder},
+			})
+			u.Upload(context.Background())
+
+			if got := srv.Uploads(config.PathVisitorUpload); len(got) != 1 || got[0] != 1 {
+				t.Errorf("Expected a single upload of 1 record, got %v", got)
+			}
+			for sub, names := range map[string][]string{
+				dropfolder.ProcessedFolder: test.Processed,
+				dropfolder.FailedFolder:    test.Failed,
+				"":                         test.Pending,
+			} {
+				for _, name := range names {
+					if !exists(filepath.Join(folder, sub, name)) {
+						t.Errorf("Expected %s in %q", name, sub)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUploader_UploadDataset(t *testing.T) {
+	folder := t.TempDir()
+	writeDropFile(t, folder, "bezoeken-1.csv", visitsHeader+visitsRow)
+	writeDropFile(t, folder, "export.csv", visitsHeader)
+
+	srv := newDoor2doc(t, http.StatusOK)
+	u := newUploader(t, config.DataV2{
+		Server:        srv.URL,
+		VisitorSource: config.SourceCSV,
+		DropFolder:    dropfolder.Settings{Folder: folder},
+	})
+	u.UploadDataset(context.Background(), config.DatasetLab)
+
+	if got := srv.Uploads(config.PathVisitorUpload); len(got) != 0 {
+		t.Errorf("Expected no visitor uploads, got %v", got)
+	}
+	for _, file := range []string{"bezoeken-1.csv", "failed/export.csv", "failed/export.csv.error.txt"} {
+		if !exists(filepath.Join(folder, file)) {
+			t.Errorf("Expected %s", file)
+		}
+	}
+	if evts := u.History.Events(); len(evts) != 1 || evts[0].Type != config.SourceCSV+":export.csv" || !errors.Is(evts[0].Error, dropfolder.ErrUnknownDataset) {
+		t.Errorf("Expected a failed event for export.csv, got %+v", evts)
+	}
+}
+
+func TestUploader_StreamVisitors(t *testing.T) {
+	srv := newDoor2doc(t, http.StatusOK)
+	u := newUploader(t, config.DataV2{
+		Server:        srv.URL,
+		Connection:    db.ConnectionData{Driver: db.DriverSQLite, Database: demoDatabase},
+		Timeout:       5 * time.Second,
+		StreamUploads: true,
+		VisitorQuery:  `select * from correct where id = 1`,
+	})
+	defer u.closeDB()
+
+	u.Upload(context.Background())
+
+	if got := srv.Uploads(config.PathVisitorUpload); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Expected a single upload of 1 record, got %v", got)
+	}
+	evts := u.History.Events()
+	if len(evts) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(evts))
+	}
+	if evt := evts[0]; evt.Type != config.PathVisitorUpload || evt.Size != 1 || evt.Error != nil || evt.QueryDuration == 0 {
+		t.Errorf("Unexpected event %+v", evt)
+	}
+}
+
+// TestUploader_Concurrent uploads visits while a scheduled upload is running. Run with -race to check that
+// UploadVisitors does not need u.mu.
+func TestUploader_Concurrent(t *testing.T) {
+	folder := t.TempDir()
+	for _, name := range []string{"bezoeken-1.csv", "bezoeken-2.csv", "bezoeken-3.csv"} {
+		writeDropFile(t, folder, name, visitsHeader+visitsRow)
+	}
+
+	srv := newDoor2doc(t, http.StatusOK)
+	u := newUploader(t, config.DataV2{
+		Server:        srv.URL,
+		VisitorSource: config.SourceHL7,
+		DropFolder:    dropfolder.Settings{Folder: folder},
+	})
+	rec := db.VisitorRecord{Bezoeknummer: 42, Locatie: "ZKH", Aangemeld: time.Now()}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		u.Upload(context.Background())
+	}()
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := u.UploadVisitors(context.Background(), config.SourceHL7, []db.VisitorRecord{rec}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := srv.Uploads(config.PathVisitorUpload); len(got) != 6 {
+		t.Errorf("Expected 6 uploads, got %v", got)
+	}
+}
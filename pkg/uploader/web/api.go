@@ -0,0 +1,629 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/history"
+)
+
+const (
+	pathAPI = "/api/v1"
+
+	// maxAPIBody limits the size of request bodies, which contain a configuration or a certificate.
+	maxAPIBody = 1 << 20
+)
+
+var (
+	errUnauthorized = errors.New("missing or invalid API token")
+	errNotFound     = errors.New("not found")
+)
+
+type tokenKey struct{}
+
+// handleAPI registers the routes of the management API. All routes except the OpenAPI description require an API
+// token with the role of the route. Like on the web interface, operators can test and change the queries, and only
+// admins can read the secrets in the configuration.
+func (m *ServeMux) handleAPI() {
+	m.Handle("GET "+pathAPI+"/openapi.json", m.OpenAPIHandler())
+
+	for role, routes := range map[string]map[string]http.HandlerFunc{
+		config.RoleViewer: {
+			"GET " + pathAPI + "/config":         m.apiGetConfig,
+			"GET " + pathAPI + "/config/changes": m.apiGetChanges,
+			"GET " + pathAPI + "/status":         m.apiGetStatus,
+			"GET " + pathAPI + "/history":        m.apiGetHistory,
+			"GET " + pathAPI + "/audit":          m.apiGetAudit,
+			"GET " + pathAPI + "/events":         m.EventsHandler().ServeHTTP,
+		},
+		config.RoleOperator: {
+			"POST " + pathAPI + "/config/apply":                   m.apiApply,
+			"POST " + pathAPI + "/config/discard":                 m.apiDiscard,
+			"POST " + pathAPI + "/config/rollback":                m.apiRollback,
+			"POST " + pathAPI + "/validate":                       m.apiValidate,
+			"POST " + pathAPI + "/datasets/{name}/run":            m.apiRun,
+			"PUT " + pathAPI + "/datasets/{name}/pause":           m.apiPause,
+			"DELETE " + pathAPI + "/datasets/{name}/pause":        m.apiResume,
+			"PUT " + pathAPI + "/datasets/{name}/query":           m.apiPutQuery,
+			"POST " + pathAPI + "/datasets/{name}/query/preview":  m.apiPreviewQuery,
+			"POST " + pathAPI + "/datasets/{name}/query/activate": m.apiActivateQuery,
+		},
+		config.RoleAdmin: {
+			"PUT " + pathAPI + "/config":                 m.apiPutConfig,
+			"GET " + pathAPI + "/config/versions":        m.apiGetVersions,
+			"PUT " + pathAPI + "/certificates/ca":        m.apiPutServerCA,
+			"DELETE " + pathAPI + "/certificates/ca":     m.apiDeleteServerCA,
+			"POST " + pathAPI + "/certificates/csr":      m.apiCreateCSR,
+			"PUT " + pathAPI + "/certificates/client":    m.apiPutClientCertificate,
+			"DELETE " + pathAPI + "/certificates/client": m.apiDeleteClientCertificate,
+		},
+	} {
+		for pattern, h := range routes {
+			m.Handle(pattern, m.APISecured(role, h))
+		}
+	}
+	m.Handle(pathAPI+"/", m.APISecured(config.RoleViewer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, errNotFound)
+	})))
+}
+
+// APISecured only allows requests with a valid API token that has the given role.
+func (m *ServeMux) APISecured(role string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		t, valid := m.cfg.CheckAPIToken(strings.TrimSpace(token))
+		if !ok || !valid {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="Door2doc Upload Service API"`)
+			writeAPIError(w, http.StatusUnauthorized, errUnauthorized)
+			return
+		}
+		if !t.Can(role) {
+			dlog.Info("Denied %s %s to API token %s, which requires role %s", r.Method, r.URL.Path, t.Name, role)
+			writeAPIError(w, http.StatusForbidden, fmt.Errorf("this requires the %s role", role))
+			return
+		}
+
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKey{}, t)))
+	})
+}
+
+// OpenAPIHandler serves the OpenAPI description of the management API.
+func (m *ServeMux) OpenAPIHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, err := m.fs.Open("/openapi.json")
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err)
+			return
+		}
+		defer dlog.Close(f)
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := io.Copy(w, f); err != nil {
+			dlog.Error("Error while writing response: %v", err)
+		}
+	})
+}
+
+// APIError is the response to a request that failed.
+type APIError struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// APIValidation contains the results of validating the configuration.
+type APIValidation struct {
+	Valid    bool         `json:"valid"`
+	Problems []APIProblem `json:"problems"`
+}
+
+// APIProblem describes a check that failed.
+type APIProblem struct {
+	Check   string `json:"check"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// APIChange is a difference between the active and the draft configuration.
+type APIChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// APIConfigResult is the response to a change of the draft configuration.
+type APIConfigResult struct {
+	Changes     []APIChange   `json:"changes"`
+	Validation  APIValidation `json:"validation"`
+	ApplyErrors []string      `json:"apply_errors,omitempty"`
+}
+
+// APIStatus summarizes the state of the service.
+type APIStatus struct {
+	Version        string        `json:"version"`
+	Active         bool          `json:"active"`
+	PendingChanges bool          `json:"pending_changes"`
+	Validation     APIValidation `json:"validation"`
+	Datasets       []APIDataset  `json:"datasets"`
+}
+
+// APIDataset shows whether uploads of a dataset have been paused.
+type APIDataset struct {
+	Name   string        `json:"name"`
+	Paused *config.Pause `json:"paused,omitempty"`
+}
+
+// APIQueryPreview contains the results of test-running a draft query. Records contains at most config.PreviewRows
+// records, as they would be uploaded.
+type APIQueryPreview struct {
+	Valid        bool              `json:"valid"`
+	Error        string            `json:"error,omitempty"`
+	Message      string            `json:"message,omitempty"`
+	QuerySeconds float64           `json:"query_seconds"`
+	Rows         int               `json:"rows"`
+	Truncated    bool              `json:"truncated"`
+	Records      []json.RawMessage `json:"records"`
+}
+
+// APIEvent is an upload that has been attempted.
+type APIEvent struct {
+	Type          string    `json:"type"`
+	Time          time.Time `json:"time"`
+	Phase         string    `json:"phase,omitempty"`
+	QuerySeconds  float64   `json:"query_seconds"`
+	UploadSeconds float64   `json:"upload_seconds"`
+	Size          int       `json:"size"`
+	Error         string    `json:"error,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	bs, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		dlog.Error("Error while encoding response: %v", err)
+		status, bs = http.StatusInternalServerError, []byte(`{"error": "internal server error"}`)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(append(bs, '\n')); err != nil {
+		dlog.Error("Error while writing response: %v", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, APIError{
+		Error:   err.Error(),
+		Message: fmt.Sprint(Humanize(err)),
+	})
+}
+
+// readJSON decodes a request body, rejecting unknown fields such that typing errors do not go unnoticed.
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+// apiValidation lists the checks that failed. The configuration is not valid until it has been validated.
+func apiValidation(v *config.ValidationResult) APIValidation {
+	res := APIValidation{Problems: []APIProblem{}}
+	if v == nil {
+		return res
+	}
+	res.Valid = v.IsValid()
+	for _, c := range []struct {
+		check string
+		err   error
+	}{
+		{"database_connection", v.DatabaseConnection},
+		{"query_timeout", v.QueryTimeout},
+		{"visitor_source", v.VisitorSource},
+		{"drop_folder", v.DropFolder},
+		{"visitor_query", v.VisitorQuery},
+		{"radiologie_query", v.RadiologieQuery},
+		{"lab_query", v.LabQuery},
+		{"consult_query", v.ConsultQuery},
+		{"d2d_connection", v.D2DConnection},
+		{"d2d_credentials", v.D2DCredentials},
+		{"client_certificate", v.ClientCertificate},
+		{"access", v.Access},
+	} {
+		if c.err != nil {
+			res.Problems = append(res.Problems, APIProblem{
+				Check:   c.check,
+				Error:   c.err.Error(),
+				Message: fmt.Sprint(Humanize(c.err)),
+			})
+		}
+	}
+	return res
+}
+
+func apiChanges(changes []config.Change) []APIChange {
+	res := make([]APIChange, len(changes))
+	for i, c := range changes {
+		res[i] = APIChange(c)
+	}
+	return res
+}
+
+func (m *ServeMux) configResult() APIConfigResult {
+	res := APIConfigResult{
+		Changes:    apiChanges(m.cfg.Changes()),
+		Validation: apiValidation(m.cfg.Validate()),
+	}
+	for _, err := range m.cfg.ApplyErrors() {
+		res.ApplyErrors = append(res.ApplyErrors, fmt.Sprint(Humanize(err)))
+	}
+	return res
+}
+
+// apiGetConfig returns the draft configuration, in the format of the configuration file. For admins, secrets are
+// obfuscated, such that the result can be uploaded again. For other roles, secrets are masked.
+func (m *ServeMux) apiGetConfig(w http.ResponseWriter, r *http.Request) {
+	if t, _ := r.Context().Value(tokenKey{}).(config.APIToken); t.Can(config.RoleAdmin) {
+		writeJSON(w, http.StatusOK, m.cfg.Draft())
+		return
+	}
+	writeJSON(w, http.StatusOK, m.cfg.Draft().Masked(secretMask))
+}
+
+// apiPutConfig replaces the draft configuration and validates it. Secrets can be given in plain text, as
+// {"plain_text": "secret"}.
+func (m *ServeMux) apiPutConfig(w http.ResponseWriter, r *http.Request) {
+	var data config.DataV2
+	if !readJSON(w, r, &data) {
+		return
+	}
+	if err := m.cfg.SetDraft(actor(r), data); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	m.cfg.UpdateBaseValidation(r.Context())
+
+	writeJSON(w, http.StatusOK, m.configResult())
+}
+
+func (m *ServeMux) apiGetChanges(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, apiChanges(m.cfg.Changes()))
+}
+
+func (m *ServeMux) apiGetVersions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, m.cfg.Versions())
+}
+
+func (m *ServeMux) apiApply(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Comment string `json:"comment"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	switch err := m.cfg.Apply(r.Context(), actor(r), req.Comment); err {
+	case nil:
+		writeJSON(w, http.StatusOK, m.configResult())
+	case config.ErrDraftInvalid:
+		writeJSON(w, http.StatusUnprocessableEntity, m.configResult())
+	default:
+		writeAPIError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (m *ServeMux) apiDiscard(w http.ResponseWriter, r *http.Request) {
+	m.cfg.Discard(actor(r))
+	m.cfg.UpdateBaseValidation(r.Context())
+
+	writeJSON(w, http.StatusOK, m.configResult())
+}
+
+func (m *ServeMux) apiRollback(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Version int `json:"version"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	switch err := m.cfg.Rollback(r.Context(), actor(r), req.Version); err {
+	case nil:
+		writeJSON(w, http.StatusOK, m.configResult())
+	case config.ErrUnknownVersion:
+		writeAPIError(w, http.StatusNotFound, err)
+	case config.ErrDraftInvalid:
+		writeJSON(w, http.StatusUnprocessableEntity, m.configResult())
+	default:
+		writeAPIError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (m *ServeMux) apiGetStatus(w http.ResponseWriter, r *http.Request) {
+	res := APIStatus{
+		Version:        m.version,
+		Active:         m.cfg.Active(),
+		PendingChanges: m.cfg.HasChanges(),
+		Validation:     apiValidation(m.cfg.Validate()),
+	}
+	for _, name := range config.Datasets {
+		d := APIDataset{Name: name}
+		if p, paused := m.cfg.Paused(name); paused {
+			d.Paused = &p
+		}
+		res.Datasets = append(res.Datasets, d)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (m *ServeMux) apiGetHistory(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, apiEvents(m.history.Events()))
+}
+
+func apiEvents(events []history.Event) []APIEvent {
+	res := []APIEvent{}
+	for _, evt := range events {
+		e := APIEvent{
+			Type:          evt.Type,
+			Time:          evt.Time,
+			Phase:         evt.Phase,
+			QuerySeconds:  evt.QueryDuration.Seconds(),
+			UploadSeconds: evt.UploadDuration.Seconds(),
+			Size:          evt.Size,
+		}
+		if evt.Error != nil {
+			e.Error = evt.Error.Error()
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// apiValidate validates the draft configuration, including the order queries.
+func (m *ServeMux) apiValidate(w http.ResponseWriter, r *http.Request) {
+	m.cfg.UpdateBaseValidation(r.Context())
+	m.cfg.UpdateRadiologieValidation(r.Context())
+	m.cfg.UpdateLabValidation(r.Context())
+	m.cfg.UpdateConsultValidation(r.Context())
+
+	writeJSON(w, http.StatusOK, apiValidation(m.cfg.Validate()))
+}
+
+func (m *ServeMux) apiRun(w http.ResponseWriter, r *http.Request) {
+	switch err := m.cfg.RunNow(r.PathValue("name")); err {
+	case nil:
+		writeJSON(w, http.StatusAccepted, struct{}{})
+	case config.ErrUnknownDataset:
+		writeAPIError(w, http.StatusNotFound, err)
+	default:
+		writeAPIError(w, http.StatusConflict, err)
+	}
+}
+
+func (m *ServeMux) apiPause(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	switch err := m.cfg.Pause(r.PathValue("name"), operator(r), strings.TrimSpace(req.Reason)); err {
+	case nil:
+		p, _ := m.cfg.Paused(r.PathValue("name"))
+		writeJSON(w, http.StatusOK, APIDataset{Name: r.PathValue("name"), Paused: &p})
+	case config.ErrUnknownDataset:
+		writeAPIError(w, http.StatusNotFound, err)
+	case config.ErrPauseReasonMissing:
+		writeAPIError(w, http.StatusBadRequest, err)
+	default:
+		writeAPIError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (m *ServeMux) apiResume(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if config.DatasetPath(name) == "" {
+		writeAPIError(w, http.StatusNotFound, config.ErrUnknownDataset)
+		return
+	}
+	if err := m.cfg.Resume(name); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, APIDataset{Name: name})
+}
+
+// apiQuery describes how the query of a dataset is tested and changed.
+type apiQuery struct {
+	preview func(*config.Configuration, context.Context, string, *time.Location) *config.QueryPreview
+	set     func(*config.Configuration, config.Actor, string)
+	apply   func(d *config.DataV2, query string)
+	comment string
+}
+
+var apiQueries = map[string]apiQuery{
+	config.DatasetVisits: {
+		preview: (*config.Configuration).PreviewVisitorQuery,
+		set:     (*config.Configuration).SetVisitorQuery,
+		apply:   func(d *config.DataV2, query string) { d.VisitorQuery = query },
+		comment: "Activated visitor query",
+	},
+	config.DatasetRadiologie: {
+		preview: (*config.Configuration).PreviewRadiologieQuery,
+		set:     (*config.Configuration).SetRadiologieQuery,
+		apply:   func(d *config.DataV2, query string) { d.RadiologieQuery = query },
+		comment: "Activated radiologie query",
+	},
+	config.DatasetLab: {
+		preview: (*config.Configuration).PreviewLabQuery,
+		set:     (*config.Configuration).SetLabQuery,
+		apply:   func(d *config.DataV2, query string) { d.LabQuery = query },
+		comment: "Activated lab query",
+	},
+	config.DatasetConsult: {
+		preview: (*config.Configuration).PreviewConsultQuery,
+		set:     (*config.Configuration).SetConsultQuery,
+		apply:   func(d *config.DataV2, query string) { d.ConsultQuery = query },
+		comment: "Activated consult query",
+	},
+}
+
+// readQuery reads the query in the request body, for the dataset in the path.
+func readQuery(w http.ResponseWriter, r *http.Request) (apiQuery, string, bool) {
+	q, ok := apiQueries[r.PathValue("name")]
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, config.ErrUnknownDataset)
+		return q, "", false
+	}
+	var req struct {
+		Query string `json:"query"`
+	}
+	if !readJSON(w, r, &req) {
+		return q, "", false
+	}
+	return q, req.Query, true
+}
+
+func apiPreview(p *config.QueryPreview) APIQueryPreview {
+	res := APIQueryPreview{
+		Valid:        p.IsValid(),
+		QuerySeconds: p.Duration.Seconds(),
+		Rows:         p.Len(),
+		Truncated:    p.Truncated,
+		Records:      p.Records,
+	}
+	if res.Records == nil {
+		res.Records = []json.RawMessage{}
+	}
+	if err := errors.Join(p.DatabaseConnection, p.QueryError); err != nil {
+		res.Error = err.Error()
+		res.Message = fmt.Sprint(Humanize(err))
+	}
+	return res
+}
+
+// apiPutQuery changes the query of a dataset in the draft configuration, without testing it first.
+func (m *ServeMux) apiPutQuery(w http.ResponseWriter, r *http.Request) {
+	q, query, ok := readQuery(w, r)
+	if !ok {
+		return
+	}
+	q.set(m.cfg, actor(r), query)
+	m.cfg.UpdateBaseValidation(r.Context())
+
+	writeJSON(w, http.StatusOK, m.configResult())
+}
+
+// apiPreviewQuery test-runs a query, without changing the configuration.
+func (m *ServeMux) apiPreviewQuery(w http.ResponseWriter, r *http.Request) {
+	q, query, ok := readQuery(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, apiPreview(q.preview(m.cfg, r.Context(), query, m.location)))
+}
+
+// apiActivateQuery tests a query and, if it runs successfully, activates it right away, like the query pages of the
+// web interface. An empty query disables the dataset, and is not tested.
+func (m *ServeMux) apiActivateQuery(w http.ResponseWriter, r *http.Request) {
+	q, query, ok := readQuery(w, r)
+	if !ok {
+		return
+	}
+	if query != "" {
+		if p := q.preview(m.cfg, r.Context(), query, m.location); !p.IsValid() {
+			writeJSON(w, http.StatusUnprocessableEntity, apiPreview(p))
+			return
+		}
+	}
+
+	err := m.cfg.ApplySetting(r.Context(), actor(r), q.comment, func(d *config.DataV2) { q.apply(d, query) })
+	switch err {
+	case nil:
+		writeJSON(w, http.StatusOK, m.configResult())
+	case config.ErrDraftInvalid:
+		writeJSON(w, http.StatusUnprocessableEntity, m.configResult())
+	default:
+		writeAPIError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// apiPutServerCA trusts the PEM encoded certificates in the request body for connecting to the door2doc server.
+func (m *ServeMux) apiPutServerCA(w http.ResponseWriter, r *http.Request) {
+	bs, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAPIBody))
+	if err == nil {
+		err = m.cfg.SetServerCA(actor(r), bs)
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	m.cfg.UpdateBaseValidation(r.Context())
+
+	writeJSON(w, http.StatusOK, m.configResult())
+}
+
+func (m *ServeMux) apiDeleteServerCA(w http.ResponseWriter, r *http.Request) {
+	m.cfg.ClearServerCA(actor(r))
+	m.cfg.UpdateBaseValidation(r.Context())
+
+	writeJSON(w, http.StatusOK, m.configResult())
+}
+
+// apiCreateCSR generates a new key and returns the request for a client certificate, in PEM format.
+func (m *ServeMux) apiCreateCSR(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		CommonName   string `json:"common_name"`
+		Organization string `json:"organization"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if err := m.cfg.GenerateClientCSR(actor(r), req.CommonName, req.Organization); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	csr, err := os.ReadFile(config.FilePath(m.cfg.Draft().ClientCSRFile))
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		CSR string `json:"csr"`
+	}{string(csr)})
+}
+
+// apiPutClientCertificate stores the client certificate in the request body. This is either the PEM encoded
+// certificate issued for the most recent request, or a PKCS#12 file with the password in the query string.
+func (m *ServeMux) apiPutClientCertificate(w http.ResponseWriter, r *http.Request) {
+	bs, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAPIBody))
+	if err == nil {
+		err = m.cfg.SetClientCertificate(actor(r), bs, r.URL.Query().Get("password"))
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	m.cfg.UpdateBaseValidation(r.Context())
+
+	writeJSON(w, http.StatusOK, m.configResult())
+}
+
+func (m *ServeMux) apiDeleteClientCertificate(w http.ResponseWriter, r *http.Request) {
+	m.cfg.ClearClientCertificate(actor(r))
+	m.cfg.UpdateBaseValidation(r.Context())
+
+	writeJSON(w, http.StatusOK, m.configResult())
+}
@@ -0,0 +1,122 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/history"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/hl7"
+)
+
+func TestAPI(t *testing.T) {
+	m, err := NewServeMux(false, "testing", config.NewConfiguration(), history.New(), hl7.NewLog(), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		Method string
+		Path   string
+		Token  string
+		Status int
+	}{
+		"openapi":         {Method: http.MethodGet, Path: "/api/v1/openapi.json", Status: http.StatusOK},
+		"missing token":   {Method: http.MethodGet, Path: "/api/v1/status", Status: http.StatusUnauthorized},
+		"invalid token":   {Method: http.MethodGet, Path: "/api/v1/config", Token: "d2d_invalid", Status: http.StatusUnauthorized},
+		"unknown route":   {Method: http.MethodGet, Path: "/api/v1/unknown", Status: http.StatusUnauthorized},
+		"method mismatch": {Method: http.MethodPost, Path: "/api/v1/status", Status: http.StatusUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(test.Method, test.Path, nil)
+			if test.Token != "" {
+				req.Header.Set("Authorization", "Bearer "+test.Token)
+			}
+			rec := httptest.NewRecorder()
+			m.ServeHTTP(rec, req)
+
+			if rec.Code != test.Status {
+				t.Errorf("%s %s == %d, got %d", test.Method, test.Path, test.Status, rec.Code)
+			}
+			var v map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+				t.Errorf("%s %s returned invalid JSON: %v", test.Method, test.Path, err)
+			}
+		})
+	}
+}
+
+func TestAPI_Config(t *testing.T) {
+	cfg := config.NewConfiguration()
+	if err := json.Unmarshal([]byte(`{"version": 2, "password": {"plain_text": "secret"}}`), cfg); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewServeMux(false, "testing", cfg, history.New(), hl7.NewLog(), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for role, masked := range map[string]bool{
+		config.RoleViewer:   true,
+		config.RoleOperator: true,
+		config.RoleAdmin:    false,
+	} {
+		t.Run(role, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/config", nil)
+			req = req.WithContext(context.WithValue(req.Context(), tokenKey{}, config.APIToken{Name: "test", Role: role}))
+			rec := httptest.NewRecorder()
+			m.apiGetConfig(rec, req)
+
+			var v map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+				t.Fatal(err)
+			}
+			if got := v["password"]; (got == secretMask) != masked || got == "" {
+				t.Errorf("GET /config as %s returned password %v", role, got)
+			}
+		})
+	}
+}
+
+func TestAPI_Queries(t *testing.T) {
+	m, err := NewServeMux(false, "testing", config.NewConfiguration(), history.New(), hl7.NewLog(), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		Dataset string
+		Body    string
+		Status  int
+	}{
+		"unknown dataset": {Dataset: "unknown", Body: `{"query": "SELECT 1"}`, Status: http.StatusNotFound},
+		"unknown field":   {Dataset: config.DatasetLab, Body: `{"sql": "SELECT 1"}`, Status: http.StatusBadRequest},
+		"no database":     {Dataset: config.DatasetLab, Body: `{"query": "SELECT 1"}`, Status: http.StatusOK},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/datasets/"+test.Dataset+"/query/preview", strings.NewReader(test.Body))
+			req.SetPathValue("name", test.Dataset)
+			rec := httptest.NewRecorder()
+			m.apiPreviewQuery(rec, req)
+
+			if rec.Code != test.Status {
+				t.Errorf("preview %s == %d, got %d: %s", test.Dataset, test.Status, rec.Code, rec.Body)
+			}
+			if test.Status != http.StatusOK {
+				return
+			}
+			var p APIQueryPreview
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Valid || p.Error == "" || p.Records == nil {
+				t.Errorf("preview without a database == invalid, got %+v", p)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package web
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+)
+
+const (
+	pathAudit = "/audit"
+
+	auditPageSize = 50
+)
+
+// actor returns who makes the changes of the configuration requested by r, for the audit log.
+func actor(r *http.Request) config.Actor {
+	return config.Actor{User: operator(r), Source: clientIP(r)}
+}
+
+type AuditPage struct {
+	*Page
+	Entries    []config.AuditEntry
+	Pagination Pagination
+	Error      error
+}
+
+// AuditHandler shows the changes of the configuration, most recent first. The complete log can be exported as CSV.
+func (m *ServeMux) AuditHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		entries, err := m.cfg.AuditLog().Entries()
+		if err != nil {
+			dlog.Error("Failed to read audit log: %v", err)
+		}
+
+		if r.URL.Query().Get("format") == "csv" {
+			writeAuditCSV(w, entries)
+			return
+		}
+
+		page := AuditPage{
+			Page:       m.page(r.Context(), r.URL.Path),
+			Pagination: newPagination(r, len(entries), auditPageSize),
+			Error:      err,
+		}
+		start := page.Pagination.Index() * auditPageSize
+		for i := len(entries) - 1 - start; i >= 0 && len(page.Entries) < auditPageSize; i-- {
+			page.Entries = append(page.Entries, entries[i])
+		}
+		runTemplate(w, m.audit, page)
+	})
+}
+
+func writeAuditCSV(w http.ResponseWriter, entries []config.AuditEntry) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="door2doc-audit.csv"`)
+
+	out := csv.NewWriter(w)
+	_ = out.Write([]string{"time", "user", "source", "action", "version", "field", "old", "new"})
+	for _, e := range entries {
+		version := ""
+		if e.Version != 0 {
+			version = strconv.Itoa(e.Version)
+		}
+		_ = out.Write([]string{e.Time.Format(time.RFC3339), e.User, e.Source, e.Action, version, e.Field, e.Old, e.New})
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		dlog.Error("Error while writing response: %v", err)
+	}
+}
+
+// APIAuditEntry is the change of a single setting.
+type APIAuditEntry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Source  string    `json:"source"`
+	Action  string    `json:"action"`
+	Version int       `json:"version,omitempty"`
+	Field   string    `json:"field"`
+	Old     string    `json:"old"`
+	New     string    `json:"new"`
+}
+
+func (m *ServeMux) apiGetAudit(w http.ResponseWriter, r *http.Request) {
+	entries, err := m.cfg.AuditLog().Entries()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := make([]APIAuditEntry, len(entries))
+	for i, e := range entries {
+		res[i] = APIAuditEntry(e)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
@@ -1,12 +1,20 @@
 package web
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"html/template"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dropfolder"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/fhir"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
 )
 
 // Humanize turns an error into a human-friendly error message.
@@ -17,8 +25,10 @@ func Humanize(err error) interface{} {
 	case config.ErrD2DCredentialsInvalid:
 		return `Invalid username/password.`
 	case config.ErrD2DConnectionFailed:
-		return template.HTML(`Unable to connect to <a href="https://integration.door2doc.net">integration.door2doc.net</a>. 
-			Please make sure the firewall allows outgoing connections to this server, or set your proxy server`)
+		return `Unable to connect to the door2doc server. 
+			Please make sure the firewall allows outgoing connections to this server, or set your proxy server`
+	case config.ErrInvalidServer:
+		return `Invalid server URL. Please enter a URL such as https://server.example.com/.`
 	case config.ErrQueryNotConfigured:
 		return `Query not configured.`
 	case config.ErrDatabaseNotConfigured:
@@ -27,6 +37,58 @@ func Humanize(err error) interface{} {
 		return `The web interface is freely accessible. Consider setting a username and password.`
 	case config.ErrInvalidTimeout:
 		return `Invalid timeout.`
+	case config.ErrDraftInvalid:
+		return `The pending changes are invalid and have not been applied.`
+	case config.ErrUnknownVersion:
+		return `Unknown configuration version.`
+	case config.ErrUnknownVisitorSource:
+		return `Unknown visitor source. Please select one of the listed sources.`
+	case config.ErrFHIRSourceNotConfigured:
+		return `FHIR server not configured. Please enter the base URL of the FHIR server.`
+	case config.ErrInvalidFHIRSource:
+		return `Invalid FHIR server URL. Please enter a URL such as https://ehr.example.com/fhir/r4.`
+	case config.ErrFHIRCredentialsMissing:
+		return `Credentials for the FHIR server not configured. Please enter a username and password, or an access token.`
+	case config.ErrDropFolderNotConfigured:
+		return `Drop folder not configured. Please enter the folder in which CSV exports are placed.`
+	case config.ErrInvalidHL7Address:
+		return `Invalid HL7 listen address. Please enter a port, such as :2575, optionally preceded by the address of a network interface.`
+	case config.ErrUnknownDataset:
+		return `Unknown dataset.`
+	case config.ErrNotActive:
+		return `The configuration is not active. Please correct the problems shown below first.`
+	case config.ErrDatasetPaused:
+		return `Uploads of this dataset have been paused. Please resume them first.`
+	case config.ErrPauseReasonMissing:
+		return `Please enter the reason for pausing uploads, such that other operators know when they can be resumed.`
+	case config.ErrTokenNameMissing:
+		return `Please enter a name for the API token, such as the system that will use it.`
+	case config.ErrTokenExists:
+		return `An API token with this name already exists. Please choose another name, or revoke the existing token first.`
+	case config.ErrInvalidWebAddress:
+		return `Invalid listen address. Please enter a port, such as :17226, preceded by localhost to only allow access from this machine.`
+	case config.ErrWebKeyMissing:
+		return `The certificate does not contain a private key. Please upload a PKCS#12 file, or a PEM file containing both the certificate and its private key.`
+	case config.ErrUserNameMissing:
+		return `Please enter the name of the user.`
+	case config.ErrUserPasswordMissing:
+		return `Please enter a password for the new user.`
+	case config.ErrUnknownRole:
+		return `Please select the role of the user.`
+	case config.ErrUnknownUser:
+		return `The user does not exist.`
+	case config.ErrLastAdmin:
+		return `At least one user must be an administrator, such that users can still be managed.`
+	case errLoginFailed:
+		return `Invalid username or password.`
+	case errSessionExpired:
+		return `The login form has expired. Please try again.`
+	case errUnauthorized:
+		return `Please provide a valid API token in the Authorization header, as Bearer token.`
+	case rest.ErrNoPrivateKey:
+		return `The certificate does not contain a private key. Please upload a PKCS#12 file, or generate a request first and upload the certificate issued for it.`
+	case rest.ErrKeyMismatch:
+		return `The certificate was not issued for the most recent request. Please upload the certificate issued for the request shown below.`
 	}
 
 	switch e := err.(type) {
@@ -36,10 +98,83 @@ func Humanize(err error) interface{} {
 		return fmt.Sprintf(`Could not connect to the database. The database driver responded with: %s.`, e.Cause)
 	case *config.QueryError:
 		return fmt.Sprintf(`Failed to execute query. The database responsed with: %s.`, e.Cause)
+	case *rest.TokenError:
+		if e.Code == "invalid_client" || e.StatusCode == http.StatusUnauthorized {
+			return `The token endpoint rejected the client ID and/or secret.`
+		}
+		return fmt.Sprintf(`Could not obtain an access token: %v.`, e)
+	case *config.FHIRSourceError:
+		return fmt.Sprintf(`Could not connect to the FHIR server: %s.`, e.Cause)
+	case *LoginLockedError:
+		return fmt.Sprintf(`Too many failed attempts to log in. Please try again in %s.`, e.Remaining.Round(time.Second))
+	case *config.AllowlistError:
+		return fmt.Sprintf(`%s is not an IP address or network. Please enter addresses such as 10.0.0.12, or networks such as 10.1.0.0/16.`, e.Entry)
+	case *config.DropFolderError:
+		if errors.Is(e.Cause, dropfolder.ErrInvalidDelimiter) {
+			return `Invalid CSV delimiter. Please enter a single character, or tab.`
+		}
+		return fmt.Sprintf(`Could not use the drop folder: %v.`, e.Cause)
+	case *fhir.OutcomeError:
+		if e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden {
+			return `The FHIR server rejected the credentials, or does not allow searching encounters.`
+		}
+		return fmt.Sprintf(`The FHIR server could not be searched: %v.`, e)
+	case *config.ClientCertificateExpiryError:
+		if e.Expired {
+			return fmt.Sprintf(`The client certificate expired on %s. Please request a new certificate.`, e.NotAfter.Format("2006-01-02"))
+		}
+		return fmt.Sprintf(`The client certificate expires on %s. Please request a new certificate in time.`, e.NotAfter.Format("2006-01-02"))
+	case *db.ConnectionOptionError:
+		if e.Cause != nil {
+			return fmt.Sprintf(`The database connection setting %s is invalid: %v.`, e.Option, e.Cause)
+		}
+		return fmt.Sprintf(`The database connection setting %s has an invalid value: %q.`, e.Option, e.Value)
 	case *db.SelectionError:
 		missing := strings.Join(e.Missing, "</code></li><li><code>")
 		return template.HTML(fmt.Sprintf(`Query is incomplete. The following columns are missing: <ul><li><code>%s</code></li></ul>`, missing))
 	}
 
+	if msg := humanizeTLS(err); msg != "" {
+		return msg
+	}
+
 	return fmt.Sprintf(`Unexpected error: %v`, err.Error())
 }
+
+// humanizeTLS explains why the door2doc server could not be verified. It returns an empty string for other errors.
+func humanizeTLS(err error) string {
+	var (
+		configErr    *rest.TLSConfigError
+		pinErr       *rest.PinError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		headerErr    tls.RecordHeaderError
+	)
+	switch {
+	case errors.As(err, &configErr):
+		return fmt.Sprintf(`The certificate settings are invalid: %v. Please upload the certificate bundle again, or correct the pinned keys.`, configErr.Cause)
+	case errors.As(err, &pinErr):
+		return `The door2doc server did not present any of the pinned public keys. The connection may be intercepted.
+			If your network inspects TLS connections, remove the pins or pin the key of the inspecting proxy.`
+	case errors.As(err, &authorityErr):
+		issuer := "an unknown authority"
+		if authorityErr.Cert != nil {
+			issuer = authorityErr.Cert.Issuer.String()
+		}
+		return fmt.Sprintf(`The certificate of the door2doc server is signed by %s, which is not trusted.
+			This usually means your network inspects TLS connections. Please upload the certificate of the inspecting proxy under Certificates.`, issuer)
+	case errors.As(err, &hostnameErr):
+		return fmt.Sprintf(`The certificate presented for %s belongs to a different server. Please check the server URL and proxy settings.`, hostnameErr.Host)
+	case errors.As(err, &invalidErr):
+		if invalidErr.Reason == x509.Expired {
+			return `The certificate presented for the door2doc server has expired, or the clock of this machine is wrong.`
+		}
+		return fmt.Sprintf(`The certificate presented for the door2doc server is invalid: %v.`, invalidErr)
+	case rest.IsTLSRejected(err):
+		return fmt.Sprintf(`The door2doc server rejected the connection: %v. If the server requires a client certificate, please check it under Certificates.`, err)
+	case errors.As(err, &headerErr):
+		return `The door2doc server did not respond with TLS. Please check that the server URL starts with https:// and the proxy settings are correct.`
+	}
+	return ""
+}
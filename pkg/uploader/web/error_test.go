@@ -1,20 +1,26 @@
 package web
 
 import (
+	"fmt"
 	"html/template"
 	"reflect"
 	"testing"
 
 	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/fhir"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
 )
 
 func TestHumanize(t *testing.T) {
 	for err, want := range map[error]interface{}{
-		config.ErrD2DCredentialsNotConfigured:                   `Username and/or password not configured.`,
-		config.D2DCredentialsStatusError{StatusCode: 404}:       `Could not verify credentials: the server returned HTTP 404. Please contact door2doc support.`,
-		&config.DatabaseInvalidError{Cause: `argh`}:             `Could not connect to the database. The database driver responded with: argh.`,
-		&db.SelectionError{Missing: []string{"hello", "world"}}: template.HTML(`Query is incomplete. The following columns are missing: <ul><li><code>hello</code></li><li><code>world</code></li></ul>`),
+		config.ErrD2DCredentialsNotConfigured:                                      `Username and/or password not configured.`,
+		config.D2DCredentialsStatusError{StatusCode: 404}:                          `Could not verify credentials: the server returned HTTP 404. Please contact door2doc support.`,
+		&config.DatabaseInvalidError{Cause: `argh`}:                                `Could not connect to the database. The database driver responded with: argh.`,
+		&db.ConnectionOptionError{Option: "sslmode", Value: "x"}:                   `The database connection setting sslmode has an invalid value: "x".`,
+		&db.SelectionError{Missing: []string{"hello", "world"}}:                    template.HTML(`Query is incomplete. The following columns are missing: <ul><li><code>hello</code></li><li><code>world</code></li></ul>`),
+		&fhir.OutcomeError{StatusCode: 401}:                                        `The FHIR server rejected the credentials, or does not allow searching encounters.`,
+		fmt.Errorf("get: %w", &rest.TLSConfigError{Cause: rest.ErrNoCertificates}): `The certificate settings are invalid: no certificates found. Please upload the certificate bundle again, or correct the pinned keys.`,
 	} {
 		t.Run(err.Error(), func(t *testing.T) {
 			got := Humanize(err)
@@ -0,0 +1,147 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/history"
+)
+
+const (
+	pathEvents = "/events"
+
+	// eventsInterval determines how often the elapsed time of running uploads and the validation results are checked.
+	eventsInterval = time.Second
+
+	// eventsKeepAlive is the maximum time between writes, such that proxies do not close idle streams.
+	eventsKeepAlive = 30 * time.Second
+)
+
+// APIRun is an upload that is in progress.
+type APIRun struct {
+	Dataset        string    `json:"dataset,omitempty"`
+	Type           string    `json:"type"`
+	Phase          string    `json:"phase"`
+	Started        time.Time `json:"started"`
+	ElapsedSeconds float64   `json:"elapsed_seconds"`
+}
+
+func apiRuns(events []history.Event, now time.Time) []APIRun {
+	res := []APIRun{}
+	for _, e := range events {
+		// events of other sources are prefixed by the source, such as csv:/services/v3/upload/orders/lab
+		path := e.Type
+		if _, p, ok := strings.Cut(e.Type, ":"); ok {
+			path = p
+		}
+		res = append(res, APIRun{
+			Dataset:        config.PathDataset(path),
+			Type:           e.Type,
+			Phase:          e.Phase,
+			Started:        e.Time,
+			ElapsedSeconds: now.Sub(e.Time).Truncate(time.Second).Seconds(),
+		})
+	}
+	return res
+}
+
+// eventStream writes server-sent events, skipping events that are identical to the previous event of the same name.
+type eventStream struct {
+	w         http.ResponseWriter
+	f         http.Flusher
+	last      map[string][]byte
+	lastWrite time.Time
+}
+
+func (s *eventStream) send(name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(s.last[name], data) {
+		return nil
+	}
+	s.last[name] = data
+
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+		return err
+	}
+	s.f.Flush()
+	s.lastWrite = time.Now()
+	return nil
+}
+
+func (s *eventStream) keepAlive() error {
+	if time.Since(s.lastWrite) < eventsKeepAlive {
+		return nil
+	}
+	if _, err := fmt.Fprint(s.w, ": keep-alive\n\n"); err != nil {
+		return err
+	}
+	s.f.Flush()
+	s.lastWrite = time.Now()
+	return nil
+}
+
+// EventsHandler streams the status of the service as server-sent events. A "history" event with the recent events is
+// sent whenever an upload starts, changes phase or finishes, a "run" event with the uploads in progress and their
+// elapsed time, and a "validation" event whenever the results of validating the configuration change.
+func (m *ServeMux) EventsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		notifications, cancel := m.history.Subscribe()
+		defer cancel()
+		ticker := time.NewTicker(eventsInterval)
+		defer ticker.Stop()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		f.Flush()
+
+		s := &eventStream{w: w, f: f, last: make(map[string][]byte), lastWrite: time.Now()}
+		for {
+			err := s.send("history", apiEvents(m.history.Events()))
+			if err == nil {
+				err = s.send("run", apiRuns(m.history.Running(), time.Now()))
+			}
+			if err == nil {
+				err = s.send("validation", apiValidation(m.cfg.Validate()))
+			}
+			if err == nil {
+				err = s.keepAlive()
+			}
+			if err != nil {
+				// the client has gone away
+				return
+			}
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-m.done:
+				return
+			case <-notifications:
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Close ends all event streams, such that the server can shut down.
+func (m *ServeMux) Close() {
+	m.closeOnce.Do(func() {
+		close(m.done)
+	})
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/history"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/hl7"
+)
+
+func TestApiRuns(t *testing.T) {
+	now := time.Now()
+	got := apiRuns([]history.Event{
+		{Type: config.PathLabUpload, Phase: history.PhaseQuerying, Time: now.Add(-1500 * time.Millisecond)},
+		{Type: config.SourceCSV + ":" + config.PathConsultUpload, Phase: history.PhaseUploading, Time: now},
+		{Type: "fhir-export:" + config.PathVisitorUpload, Phase: history.PhaseUploading, Time: now},
+	}, now)
+
+	for i, want := range []APIRun{
+		{Dataset: config.DatasetLab, Phase: history.PhaseQuerying, ElapsedSeconds: 1},
+		{Dataset: config.DatasetConsult, Phase: history.PhaseUploading},
+		{Dataset: config.DatasetVisits, Phase: history.PhaseUploading},
+	} {
+		if got[i].Dataset != want.Dataset || got[i].Phase != want.Phase || got[i].ElapsedSeconds != want.ElapsedSeconds {
+			t.Errorf("apiRuns()[%d] == %+v, got %+v", i, want, got[i])
+		}
+	}
+}
+
+func TestEventsHandler(t *testing.T) {
+	h := history.New()
+	m, err := NewServeMux(false, "testing", config.NewConfiguration(), h, hl7.NewLog(), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(m.EventsHandler())
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type == text/event-stream, got %s", ct)
+	}
+
+	events := make(chan [2]string)
+	go func() {
+		defer close(events)
+		var name string
+		s := bufio.NewScanner(res.Body)
+		for s.Scan() {
+			if n, ok := strings.CutPrefix(s.Text(), "event: "); ok {
+				name = n
+			} else if data, ok := strings.CutPrefix(s.Text(), "data: "); ok {
+				events <- [2]string{name, data}
+			}
+		}
+	}()
+	next := func(name string) string {
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					t.Fatalf("stream closed while waiting for %s", name)
+				}
+				if e[0] == name {
+					return e[1]
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timeout while waiting for %s", name)
+			}
+		}
+	}
+
+	if got := next("history"); got != "[]" {
+		t.Errorf("initial history == [], got %s", got)
+	}
+	if got := next("run"); got != "[]" {
+		t.Errorf("initial run == [], got %s", got)
+	}
+
+	evt := h.NewEvent(config.PathLabUpload)
+	var runs []APIRun
+	if err := json.Unmarshal([]byte(next("run")), &runs); err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Dataset != config.DatasetLab || runs[0].Phase != history.PhaseQuerying {
+		t.Errorf("run == [lab querying], got %+v", runs)
+	}
+
+	h.Finish(evt)
+	var hist []APIEvent
+	for len(hist) == 0 || hist[0].Phase != "" {
+		if err := json.Unmarshal([]byte(next("history")), &hist); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m.Close()
+	for range events {
+	}
+}
@@ -3,13 +3,18 @@ package web
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	"fmt"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/password"
 	"html/template"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/pprof"
+	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,7 +22,11 @@ import (
 	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dropfolder"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/fhir"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/history"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/hl7"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/rest"
 )
 
 const (
@@ -28,15 +37,53 @@ const (
 	pathRadiology = "/orders/radiology"
 	pathLab       = "/orders/lab"
 	pathConsult   = "/orders/consult"
+	pathChanges   = "/changes"
+	pathCerts     = "/certificates"
+	pathHL7       = "/hl7"
+
+	actionTest     = "test"
+	actionActivate = "activate"
+	actionDiscard  = "discard"
+	actionApply    = "apply"
+	actionRollback = "rollback"
+	actionPause    = "pause"
+	actionResume   = "resume"
+	actionRun      = "run"
+
+	actionSetUser       = "set-user"
+	actionRemoveUser    = "remove-user"
+	actionCreateToken   = "create-token"
+	actionRevokeToken   = "revoke-token"
+	actionWebAddress    = "web-address"
+	actionWebTLS        = "web-tls"
+	actionWebCert       = "web-cert"
+	actionRemoveWebCert = "remove-web-cert"
+
+	previewPageSize = 25
+	// previewTimeout determines how long the draft of a query page is kept after it was last tested.
+	previewTimeout = sessionIdleTimeout
 )
 
 type ServeMux struct {
 	*http.ServeMux
 
-	fs      http.FileSystem
-	version string
-	cfg     *config.Configuration
-	history *history.History
+	fs       http.FileSystem
+	version  string
+	cfg      *config.Configuration
+	history  *history.History
+	messages *hl7.Log
+	location *time.Location
+
+	sessions *sessions
+	logins   *loginLimiter
+
+	// closed to end event streams when the server shuts down
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// draft queries that have been tested but not activated, by session and path
+	previewMu sync.Mutex
+	previews  map[previewKey]draftPreview
 
 	mu        sync.RWMutex
 	err       error
@@ -48,6 +95,11 @@ type ServeMux struct {
 	radiology *template.Template
 	lab       *template.Template
 	consult   *template.Template
+	changes   *template.Template
+	certs     *template.Template
+	hl7       *template.Template
+	login     *template.Template
+	audit     *template.Template
 }
 
 func (m *ServeMux) load(templates ...string) *template.Template {
@@ -93,13 +145,18 @@ func (m *ServeMux) initTemplates() {
 	defer m.mu.Unlock()
 
 	m.database = m.load("/database.html", "/_layout.html")
-	m.query = m.load("/query.html", "/_layout.html")
+	m.query = m.load("/query.html", "/_preview.html", "/_layout.html")
 	m.status = m.load("/status.html", "/_layout.html")
 	m.upload = m.load("/upload.html", "/_layout.html")
 	m.access = m.load("/access.html", "/_layout.html")
-	m.radiology = m.load("/orders-radiology.html", "/_layout.html")
-	m.lab = m.load("/orders-lab.html", "/_layout.html")
-	m.consult = m.load("/orders-consult.html", "/_layout.html")
+	m.radiology = m.load("/orders-radiology.html", "/_preview.html", "/_layout.html")
+	m.lab = m.load("/orders-lab.html", "/_preview.html", "/_layout.html")
+	m.consult = m.load("/orders-consult.html", "/_preview.html", "/_layout.html")
+	m.changes = m.load("/changes.html", "/_layout.html")
+	m.certs = m.load("/certificates.html", "/_layout.html")
+	m.hl7 = m.load("/hl7.html", "/_layout.html")
+	m.login = m.load("/login.html")
+	m.audit = m.load("/audit.html", "/_layout.html")
 }
 
 func runTemplate(w http.ResponseWriter, tmpl *template.Template, data interface{}) {
@@ -118,13 +175,19 @@ func runTemplate(w http.ResponseWriter, tmpl *template.Template, data interface{
 }
 
 // NewServeMux generates the toplevel http mux for managing the service.
-func NewServeMux(dev bool, version string, cfg *config.Configuration, h *history.History) (*ServeMux, error) {
+func NewServeMux(dev bool, version string, cfg *config.Configuration, h *history.History, messages *hl7.Log, loc *time.Location) (*ServeMux, error) {
 	res := &ServeMux{
 		ServeMux: http.NewServeMux(),
 		fs:       assets.FS(dev),
 		version:  version,
 		cfg:      cfg,
 		history:  h,
+		messages: messages,
+		location: loc,
+		previews: make(map[previewKey]draftPreview),
+		sessions: newSessions(),
+		logins:   newLoginLimiter(),
+		done:     make(chan struct{}),
 	}
 
 	res.initTemplates()
@@ -144,29 +207,41 @@ func NewServeMux(dev bool, version string, cfg *config.Configuration, h *history
 	}
 
 	res.Handle("/assets/", http.FileServer(res.fs))
-	res.Handle("/", res.Secured(res.StatusHandler()))
-	res.Handle(pathDatabase, res.Secured(res.DatabaseHandler()))
-	res.Handle(pathQuery, res.Secured(res.VisitorQueryHandler()))
-	res.Handle(pathUpload, res.Secured(res.UploadHandler()))
-	res.Handle(pathAccess, res.Secured(res.AccessHandler()))
-	res.Handle(pathRadiology, res.Secured(res.RadiologyQueryHandler()))
-	res.Handle(pathLab, res.Secured(res.LabQueryHandler()))
-	res.Handle(pathConsult, res.Secured(res.ConsultQueryHandler()))
-	res.HandleFunc("/debug/pprof/", pprof.Index)
-	res.HandleFunc("/debug/pprof/profile", pprof.Profile)
-	res.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-	res.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	res.Handle("/", res.Secured(config.RoleViewer, config.RoleOperator, res.StatusHandler()))
+	res.Handle(pathDatabase, res.Secured(config.RoleViewer, config.RoleAdmin, res.DatabaseHandler()))
+	res.Handle(pathQuery, res.Secured(config.RoleViewer, config.RoleOperator, res.VisitorQueryHandler()))
+	res.Handle(pathUpload, res.Secured(config.RoleViewer, config.RoleAdmin, res.UploadHandler()))
+	res.Handle(pathAccess, res.Secured(config.RoleAdmin, config.RoleAdmin, res.AccessHandler()))
+	res.Handle(pathRadiology, res.Secured(config.RoleViewer, config.RoleOperator, res.RadiologyQueryHandler()))
+	res.Handle(pathLab, res.Secured(config.RoleViewer, config.RoleOperator, res.LabQueryHandler()))
+	res.Handle(pathConsult, res.Secured(config.RoleViewer, config.RoleOperator, res.ConsultQueryHandler()))
+	res.Handle(pathChanges, res.Secured(config.RoleViewer, config.RoleOperator, res.ChangesHandler()))
+	res.Handle(pathCerts, res.Secured(config.RoleViewer, config.RoleAdmin, res.CertificatesHandler()))
+	res.Handle(pathHL7, res.Secured(config.RoleViewer, config.RoleViewer, res.HL7Handler()))
+	res.Handle(pathAudit, res.Secured(config.RoleViewer, config.RoleAdmin, res.AuditHandler()))
+	res.Handle(pathEvents, res.Secured(config.RoleViewer, config.RoleViewer, res.EventsHandler()))
+	res.Handle(pathLogin, res.LoginHandler())
+	res.Handle(pathLogout, res.Secured(config.RoleViewer, config.RoleViewer, res.LogoutHandler()))
+	res.handleAPI()
+	res.Handle("/debug/pprof/", res.Profiling(http.HandlerFunc(pprof.Index)))
+	res.Handle("/debug/pprof/profile", res.Profiling(http.HandlerFunc(pprof.Profile)))
+	res.Handle("/debug/pprof/symbol", res.Profiling(http.HandlerFunc(pprof.Symbol)))
+	res.Handle("/debug/pprof/trace", res.Profiling(http.HandlerFunc(pprof.Trace)))
 	return res, nil
 }
 
 type Page struct {
 	Version       string
 	Path          string
+	User          config.User
+	CanEdit       bool
+	CSRFToken     string
 	Problems      map[string]bool
 	Warnings      map[string]bool
 	GlobalError   error
 	Validation    *config.ValidationResult
 	Configuration *config.Configuration
+	Changes       []config.Change
 }
 
 func (m *ServeMux) page(ctx context.Context, path string) *Page {
@@ -174,19 +249,26 @@ func (m *ServeMux) page(ctx context.Context, path string) *Page {
 		Version: m.version,
 		Path:    path,
 	}
+	if a := authorizationFrom(ctx); a != nil {
+		p.User = a.User
+		p.CanEdit = a.User.Can(a.Edit)
+		p.CSRFToken = a.Session.CSRF
+	}
 
 	p.Configuration = m.cfg
 	p.Validation = p.Configuration.Validate()
+	p.Changes = p.Configuration.Changes()
 	p.Problems = map[string]bool{
-		"Database": p.Validation.DatabaseConnection != nil,
+		"Database": p.Validation.DatabaseConnection != nil || p.Validation.VisitorSource != nil || p.Validation.DropFolder != nil,
 		"Query":    p.Validation.VisitorQuery != nil,
 		"Upload":   p.Validation.D2DCredentials != nil,
 	}
 	p.Warnings = map[string]bool{
-		"Access":    p.Validation.Access != nil,
-		"Radiology": p.Validation.RadiologieQuery != nil,
-		"Lab":       p.Validation.LabQuery != nil,
-		"Consult":   p.Validation.ConsultQuery != nil,
+		"Access":       p.Validation.Access != nil,
+		"Certificates": p.Validation.ClientCertificate != nil,
+		"Radiology":    p.Validation.RadiologieQuery != nil,
+		"Lab":          p.Validation.LabQuery != nil,
+		"Consult":      p.Validation.ConsultQuery != nil,
 	}
 
 	return p
@@ -194,7 +276,24 @@ func (m *ServeMux) page(ctx context.Context, path string) *Page {
 
 type StatusPage struct {
 	*Page
-	History *history.History
+	History  *history.History
+	Datasets []DatasetStatus
+	Error    error
+}
+
+// DatasetStatus shows whether uploads of a dataset have been paused.
+type DatasetStatus struct {
+	Name   string
+	Title  string
+	Paused bool
+	Pause  config.Pause
+}
+
+var datasetTitles = map[string]string{
+	config.DatasetVisits:     "Visits",
+	config.DatasetRadiologie: "Radiology orders",
+	config.DatasetLab:        "Lab orders",
+	config.DatasetConsult:    "Consult orders",
 }
 
 func (m *ServeMux) StatusHandler() http.Handler {
@@ -202,20 +301,76 @@ func (m *ServeMux) StatusHandler() http.Handler {
 		m.mu.RLock()
 		defer m.mu.RUnlock()
 
+		var err error
+		if r.Method == http.MethodPost {
+			dataset := r.FormValue("dataset")
+			switch r.FormValue("action") {
+			case actionPause:
+				err = m.cfg.Pause(dataset, operator(r), strings.TrimSpace(r.FormValue("reason")))
+			case actionResume:
+				err = m.cfg.Resume(dataset)
+			case actionRun:
+				err = m.cfg.RunNow(dataset)
+			}
+			if err == nil {
+				w.Header().Set("Location", "/")
+				w.WriteHeader(http.StatusFound)
+				return
+			}
+		}
+
+		datasets := make([]DatasetStatus, len(config.Datasets))
+		for i, name := range config.Datasets {
+			datasets[i] = DatasetStatus{Name: name, Title: datasetTitles[name]}
+			datasets[i].Pause, datasets[i].Paused = m.cfg.Paused(name)
+		}
+
 		runTemplate(w, m.status, StatusPage{
-			Page:    m.page(r.Context(), r.URL.Path),
-			History: m.history,
+			Page:     m.page(r.Context(), r.URL.Path),
+			History:  m.history,
+			Datasets: datasets,
+			Error:    err,
 		})
 	})
 }
 
+// operator identifies the user making a request, such that changes can be attributed. This is the name of the API
+// token or the username if access is secured, or the address of the client otherwise.
+func operator(r *http.Request) string {
+	if t, ok := r.Context().Value(tokenKey{}).(config.APIToken); ok {
+		return "API token " + t.Name
+	}
+	if a := authorizationFrom(r.Context()); a != nil && a.User.Name != "" {
+		return a.User.Name
+	}
+	return clientIP(r)
+}
+
+// clientIP returns the IP address of the client that sent a request.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 type DatabasePage struct {
 	*Page
 
-	Config       db.ConnectionData
-	Timeout      string
-	Error        error
-	TimeoutError error
+	Source          string
+	HL7Address      string
+	FHIRSource      fhir.SourceSettings
+	SourceError     error
+	DropFolder      dropfolder.Settings
+	DropFolderError error
+	Config          db.ConnectionData
+	FailoverHosts   string
+	EncryptModes    []string
+	SSLModes        []string
+	Timeout         string
+	Error           error
+	TimeoutError    error
 }
 
 func (m *ServeMux) DatabaseHandler() http.Handler {
@@ -233,45 +388,92 @@ func (m *ServeMux) DatabaseHandler() http.Handler {
 				Username: r.FormValue("username"),
 				Password: password.Password(r.FormValue("password")),
 				Params:   r.FormValue("params"),
+
+				FailoverHosts: splitLines(r.FormValue("failover_hosts")),
+
+				Encrypt:                r.FormValue("encrypt"),
+				TrustServerCertificate: r.FormValue("trust_server_certificate") != "",
+				HostNameInCertificate:  r.FormValue("host_name_in_certificate"),
+				SSLMode:                r.FormValue("sslmode"),
+				CAFile:                 r.FormValue("ca_file"),
 			}
-			m.cfg.SetConnection(c)
+			m.cfg.SetConnection(actor(r), c)
+			m.cfg.SetVisitorSource(actor(r), r.FormValue("visitor_source"))
+			m.cfg.SetHL7Address(actor(r), strings.TrimSpace(r.FormValue("hl7_address")))
+			m.cfg.SetFHIRSource(actor(r), fhir.SourceSettings{
+				URL:      strings.TrimSpace(r.FormValue("fhir_url")),
+				Auth:     r.FormValue("fhir_auth"),
+				Username: r.FormValue("fhir_username"),
+				Password: password.Password(r.FormValue("fhir_password")),
+				Token:    password.Password(r.FormValue("fhir_token")),
+			})
+			m.cfg.SetDropFolder(actor(r), dropfolder.Settings{
+				Folder:     strings.TrimSpace(r.FormValue("drop_folder")),
+				Delimiter:  r.FormValue("csv_delimiter"),
+				TimeLayout: strings.TrimSpace(r.FormValue("csv_time_layout")),
+			})
 
 			timeoutStr := r.FormValue("timeout")
 			t, err := strconv.Atoi(timeoutStr)
 			if err == nil {
-				m.cfg.SetTimeout(time.Duration(t) * time.Second)
+				m.cfg.SetTimeout(actor(r), time.Duration(t)*time.Second)
 			}
 
 			m.cfg.UpdateBaseValidation(r.Context())
 
-			if m.cfg.Validate().IsValid() {
-				if err := m.cfg.Save(); err != nil {
-					dlog.Error("While saving credentials: %v", err)
-				}
-			}
 			w.Header().Set("Location", pathDatabase)
 			w.WriteHeader(http.StatusFound)
 			return
 		}
 
-		connectionData := m.cfg.Connection()
+		draft := m.cfg.Draft()
+		draft.Connection.Password = password.Password(secret(r, draft.Connection.Password.PlainText()))
+		draft.FHIRSource.Password = password.Password(secret(r, draft.FHIRSource.Password.PlainText()))
+		draft.FHIRSource.Token = password.Password(secret(r, draft.FHIRSource.Token.PlainText()))
 		runTemplate(w, m.database, DatabasePage{
-			Page:         m.page(r.Context(), r.URL.Path),
-			Config:       connectionData,
-			Timeout:      fmt.Sprintf("%d", m.cfg.Timeout()/time.Second),
-			Error:        m.cfg.Validate().DatabaseConnection,
-			TimeoutError: m.cfg.Validate().QueryTimeout,
+			Page:            m.page(r.Context(), r.URL.Path),
+			Source:          draft.Source(),
+			HL7Address:      draft.HL7Address,
+			FHIRSource:      draft.FHIRSource,
+			SourceError:     m.cfg.Validate().VisitorSource,
+			DropFolder:      draft.DropFolder,
+			DropFolderError: m.cfg.Validate().DropFolder,
+			Config:          draft.Connection,
+			FailoverHosts:   strings.Join(draft.Connection.FailoverHosts, "\n"),
+			EncryptModes:    db.EncryptModes,
+			SSLModes:        db.SSLModes,
+			Timeout:         fmt.Sprintf("%d", draft.Timeout/time.Second),
+			Error:           m.cfg.Validate().DatabaseConnection,
+			TimeoutError:    m.cfg.Validate().QueryTimeout,
 		})
 	})
 }
 
+// splitLines splits text into its lines, ignoring empty lines.
+func splitLines(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, "\n") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
 type UploadPage struct {
 	*Page
 
-	Username string
-	Password string
-	Proxy    string
-	Error    error
+	Username     string
+	Password     string
+	AuthMethod   string
+	OAuth2       rest.ClientCredentials
+	Environments []config.Environment
+	Environment  string
+	Server       string
+	Proxy        rest.Proxy
+	Stream       bool
+	FHIRServer   string
+	Error        error
 }
 
 func (m *ServeMux) UploadHandler() http.Handler {
@@ -280,31 +482,53 @@ func (m *ServeMux) UploadHandler() http.Handler {
 		defer m.mu.RUnlock()
 
 		if r.Method == http.MethodPost {
-			m.cfg.SetCredentials(r.FormValue("username"), r.FormValue("password"))
-			m.cfg.SetProxy(r.FormValue("proxy"))
+			m.cfg.SetCredentials(actor(r), r.FormValue("username"), r.FormValue("password"))
+			m.cfg.SetClientCredentials(actor(r), r.FormValue("auth_method"), rest.ClientCredentials{
+				TokenURL:     r.FormValue("token_url"),
+				ClientID:     r.FormValue("client_id"),
+				ClientSecret: r.FormValue("client_secret"),
+				Scope:        r.FormValue("scope"),
+			})
+			m.cfg.SetServer(actor(r), config.ServerOf(r.FormValue("environment"), r.FormValue("server")))
+			m.cfg.SetProxy(actor(r), rest.Proxy{
+				URL:      r.FormValue("proxy"),
+				Username: r.FormValue("proxy_username"),
+				Password: r.FormValue("proxy_password"),
+				NoProxy:  r.FormValue("no_proxy"),
+				PAC:      r.FormValue("proxy_pac"),
+			})
+			m.cfg.SetStreamUploads(actor(r), r.FormValue("stream") != "")
+			m.cfg.SetFHIRServer(actor(r), r.FormValue("fhir_server"))
 			m.cfg.UpdateBaseValidation(r.Context())
-			if err := m.cfg.Save(); err != nil {
-				dlog.Error("While saving credentials: %v", err)
-			}
 
 			w.Header().Set("Location", pathUpload)
 			w.WriteHeader(http.StatusFound)
 			return
 		}
 
-		username, password := m.cfg.Credentials()
-		proxy := m.cfg.Proxy()
+		draft := m.cfg.Draft()
 		err := m.cfg.Validate().D2DCredentials
 		if err == nil {
 			err = m.cfg.Validate().D2DConnection
 		}
+		oauth2 := draft.ClientCredentials()
+		oauth2.ClientSecret = secret(r, oauth2.ClientSecret)
+		proxy := draft.ProxySettings()
+		proxy.Password = secret(r, proxy.Password)
 
 		runTemplate(w, m.upload, UploadPage{
-			Page:     m.page(r.Context(), r.URL.Path),
-			Username: username,
-			Password: password,
-			Proxy:    proxy,
-			Error:    err,
+			Page:         m.page(r.Context(), r.URL.Path),
+			Username:     draft.Username,
+			Password:     secret(r, draft.Password.PlainText()),
+			AuthMethod:   draft.AuthMethod,
+			OAuth2:       oauth2,
+			Environments: config.Environments,
+			Environment:  config.EnvironmentOf(draft.Server),
+			Server:       draft.Server,
+			Proxy:        proxy,
+			Stream:       draft.StreamUploads,
+			FHIRServer:   draft.FHIRServer,
+			Error:        err,
 		})
 	})
 }
@@ -316,6 +540,143 @@ type QueryPage struct {
 	Columns       []db.Column
 	QueryDuration time.Duration
 	QueryResults  config.QueryResult
+
+	// Draft contains the results of the most recently tested query, if it has not been activated yet.
+	Draft      *config.QueryPreview
+	Pagination Pagination
+}
+
+// Pagination describes the currently shown page of a paginated result. Page numbers start at 1.
+type Pagination struct {
+	Number int
+	Count  int
+	Size   int
+}
+
+func newPagination(r *http.Request, length, size int) Pagination {
+	p := Pagination{Number: 1, Size: size}
+	p.Count = (length + size - 1) / size
+	if p.Count == 0 {
+		p.Count = 1
+	}
+	if n, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && n >= 1 && n <= p.Count {
+		p.Number = n
+	}
+	return p
+}
+
+// Index returns the zero-based index of the current page.
+func (p Pagination) Index() int {
+	return p.Number - 1
+}
+
+func (p Pagination) HasPrevious() bool {
+	return p.Number > 1
+}
+
+func (p Pagination) HasNext() bool {
+	return p.Number < p.Count
+}
+
+func (p Pagination) Previous() int {
+	return p.Number - 1
+}
+
+func (p Pagination) Next() int {
+	return p.Number + 1
+}
+
+// previewKey identifies the draft of a query page. Drafts are kept per session, such that users do not see or replace
+// each other's drafts.
+type previewKey struct {
+	session string
+	path    string
+}
+
+type draftPreview struct {
+	preview *config.QueryPreview
+	expires time.Time
+}
+
+func newPreviewKey(r *http.Request, path string) previewKey {
+	k := previewKey{path: path}
+	// the CSRF token identifies the session, also when the browser has not logged in and the session is not stored
+	if a := authorizationFrom(r.Context()); a != nil && a.Session != nil {
+		k.session = a.Session.CSRF
+	}
+	return k
+}
+
+func (m *ServeMux) preview(r *http.Request, path string) *config.QueryPreview {
+	m.previewMu.Lock()
+	defer m.previewMu.Unlock()
+
+	d, ok := m.previews[newPreviewKey(r, path)]
+	if !ok || time.Now().After(d.expires) {
+		return nil
+	}
+	return d.preview
+}
+
+// setPreview replaces the draft of a query page, removing expired drafts.
+func (m *ServeMux) setPreview(r *http.Request, path string, p *config.QueryPreview) {
+	m.previewMu.Lock()
+	defer m.previewMu.Unlock()
+
+	now := time.Now()
+	for k, d := range m.previews {
+		if now.After(d.expires) {
+			delete(m.previews, k)
+		}
+	}
+
+	k := newPreviewKey(r, path)
+	if p == nil {
+		delete(m.previews, k)
+		return
+	}
+	m.previews[k] = draftPreview{preview: p, expires: now.Add(previewTimeout)}
+}
+
+// queryPost handles the test, activate and discard actions of the query pages. It returns true if the submitted
+// query should be activated.
+func (m *ServeMux) queryPost(r *http.Request, path string, preview func(context.Context, string, *time.Location) *config.QueryPreview) bool {
+	query := r.FormValue("query")
+
+	switch r.FormValue("action") {
+	case actionDiscard:
+		m.setPreview(r, path, nil)
+		return false
+	case actionActivate:
+		// an empty query disables the dataset, and does not need testing
+		if query == "" {
+			m.setPreview(r, path, nil)
+			return true
+		}
+		p := preview(r.Context(), query, m.location)
+		if p.IsValid() {
+			m.setPreview(r, path, nil)
+			return true
+		}
+		m.setPreview(r, path, p)
+		return false
+	default:
+		m.setPreview(r, path, preview(r.Context(), query, m.location))
+		return false
+	}
+}
+
+// withDraft adds the current draft of the query page, if any.
+func (m *ServeMux) withDraft(r *http.Request, path string, page QueryPage) QueryPage {
+	p := m.preview(r, path)
+	if p == nil {
+		return page
+	}
+
+	page.Query = p.Query
+	page.Draft = p
+	page.Pagination = newPagination(r, p.Len(), previewPageSize)
+	return page
 }
 
 func (m *ServeMux) VisitorQueryHandler() http.Handler {
@@ -324,11 +685,13 @@ func (m *ServeMux) VisitorQueryHandler() http.Handler {
 		defer m.mu.RUnlock()
 
 		if r.Method == http.MethodPost {
-			m.cfg.SetVisitorQuery(r.FormValue("query"))
-			m.cfg.UpdateBaseValidation(r.Context())
-			if m.cfg.Validate().IsValid() {
-				if err := m.cfg.Save(); err != nil {
-					dlog.Error("While saving query: %v", err)
+			if m.queryPost(r, pathQuery, m.cfg.PreviewVisitorQuery) {
+				query := r.FormValue("query")
+				err := m.cfg.ApplySetting(r.Context(), actor(r), "Activated visitor query", func(d *config.DataV2) {
+					d.VisitorQuery = query
+				})
+				if err != nil {
+					dlog.Error("While activating query: %v", err)
 				}
 			}
 
@@ -338,14 +701,14 @@ func (m *ServeMux) VisitorQueryHandler() http.Handler {
 		}
 
 		v := m.cfg.Validate()
-		runTemplate(w, m.query, QueryPage{
+		runTemplate(w, m.query, m.withDraft(r, pathQuery, QueryPage{
 			Page:          m.page(r.Context(), r.URL.Path),
-			Query:         m.cfg.VisitorQuery(),
+			Query:         m.cfg.Draft().VisitorQuery,
 			Error:         v.VisitorQuery,
 			Columns:       db.VisitorColumns,
 			QueryDuration: v.VisitorQueryDuration,
 			QueryResults:  v.VisitorQueryResults,
-		})
+		}))
 	})
 }
 
@@ -355,11 +718,13 @@ func (m *ServeMux) RadiologyQueryHandler() http.Handler {
 		defer m.mu.RUnlock()
 
 		if r.Method == http.MethodPost {
-			m.cfg.SetRadiologieQuery(r.FormValue("query"))
-			m.cfg.UpdateRadiologieValidation(r.Context())
-			if m.cfg.Validate().IsValid() {
-				if err := m.cfg.Save(); err != nil {
-					dlog.Error("While saving query: %v", err)
+			if m.queryPost(r, pathRadiology, m.cfg.PreviewRadiologieQuery) {
+				query := r.FormValue("query")
+				err := m.cfg.ApplySetting(r.Context(), actor(r), "Activated radiologie query", func(d *config.DataV2) {
+					d.RadiologieQuery = query
+				})
+				if err != nil {
+					dlog.Error("While activating query: %v", err)
 				}
 			}
 
@@ -369,14 +734,14 @@ func (m *ServeMux) RadiologyQueryHandler() http.Handler {
 		}
 
 		v := m.cfg.Validate()
-		runTemplate(w, m.radiology, QueryPage{
+		runTemplate(w, m.radiology, m.withDraft(r, pathRadiology, QueryPage{
 			Page:          m.page(r.Context(), r.URL.Path),
-			Query:         m.cfg.RadiologieQuery(),
+			Query:         m.cfg.Draft().RadiologieQuery,
 			Error:         v.RadiologieQuery,
 			Columns:       db.RadiologieColumns,
 			QueryDuration: v.RadiologieQueryDuration,
 			QueryResults:  v.RadiologieQueryResults,
-		})
+		}))
 	})
 }
 
@@ -386,11 +751,13 @@ func (m *ServeMux) LabQueryHandler() http.Handler {
 		defer m.mu.RUnlock()
 
 		if r.Method == http.MethodPost {
-			m.cfg.SetLabQuery(r.FormValue("query"))
-			m.cfg.UpdateLabValidation(r.Context())
-			if m.cfg.Validate().IsValid() {
-				if err := m.cfg.Save(); err != nil {
-					dlog.Error("While saving query: %v", err)
+			if m.queryPost(r, pathLab, m.cfg.PreviewLabQuery) {
+				query := r.FormValue("query")
+				err := m.cfg.ApplySetting(r.Context(), actor(r), "Activated lab query", func(d *config.DataV2) {
+					d.LabQuery = query
+				})
+				if err != nil {
+					dlog.Error("While activating query: %v", err)
 				}
 			}
 
@@ -400,14 +767,14 @@ func (m *ServeMux) LabQueryHandler() http.Handler {
 		}
 
 		v := m.cfg.Validate()
-		runTemplate(w, m.lab, QueryPage{
+		runTemplate(w, m.lab, m.withDraft(r, pathLab, QueryPage{
 			Page:          m.page(r.Context(), r.URL.Path),
-			Query:         m.cfg.LabQuery(),
+			Query:         m.cfg.Draft().LabQuery,
 			Error:         v.LabQuery,
 			Columns:       db.LabColumns,
 			QueryDuration: v.LabQueryDuration,
 			QueryResults:  v.LabQueryResults,
-		})
+		}))
 	})
 }
 
@@ -417,11 +784,13 @@ func (m *ServeMux) ConsultQueryHandler() http.Handler {
 		defer m.mu.RUnlock()
 
 		if r.Method == http.MethodPost {
-			m.cfg.SetConsultQuery(r.FormValue("query"))
-			m.cfg.UpdateConsultValidation(r.Context())
-			if m.cfg.Validate().IsValid() {
-				if err := m.cfg.Save(); err != nil {
-					dlog.Error("While saving query: %v", err)
+			if m.queryPost(r, pathConsult, m.cfg.PreviewConsultQuery) {
+				query := r.FormValue("query")
+				err := m.cfg.ApplySetting(r.Context(), actor(r), "Activated consult query", func(d *config.DataV2) {
+					d.ConsultQuery = query
+				})
+				if err != nil {
+					dlog.Error("While activating query: %v", err)
 				}
 			}
 
@@ -431,69 +800,403 @@ func (m *ServeMux) ConsultQueryHandler() http.Handler {
 		}
 
 		v := m.cfg.Validate()
-		runTemplate(w, m.consult, QueryPage{
+		runTemplate(w, m.consult, m.withDraft(r, pathConsult, QueryPage{
 			Page:          m.page(r.Context(), r.URL.Path),
-			Query:         m.cfg.ConsultQuery(),
+			Query:         m.cfg.Draft().ConsultQuery,
 			Error:         v.ConsultQuery,
 			Columns:       db.ConsultColumns,
 			QueryDuration: v.ConsultQueryDuration,
 			QueryResults:  v.ConsultQueryResults,
-		})
+		}))
 	})
 }
 
-type AccessPage struct {
+type ChangesPage struct {
 	*Page
-	Username string
-	Password string
-	Error    error
+	Versions []VersionChanges
 }
 
-func (m *ServeMux) AccessHandler() http.Handler {
+// VersionChanges contains an applied configuration, and the changes with respect to the version before.
+type VersionChanges struct {
+	config.Version
+	Changes []config.Change
+}
+
+func (m *ServeMux) ChangesHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		m.mu.RLock()
 		defer m.mu.RUnlock()
 
 		if r.Method == http.MethodPost {
-			m.cfg.SetAccessCredentials(r.FormValue("username"), r.FormValue("password"))
-			m.cfg.UpdateBaseValidation(r.Context())
-			if m.cfg.Validate().IsValid() {
-				if err := m.cfg.Save(); err != nil {
-					dlog.Error("While saving access credentials: %v", err)
+			switch r.FormValue("action") {
+			case actionApply:
+				if err := m.cfg.Apply(r.Context(), actor(r), r.FormValue("comment")); err != nil {
+					dlog.Error("While applying configuration: %v", err)
+				}
+			case actionDiscard:
+				m.cfg.Discard(actor(r))
+				m.cfg.UpdateBaseValidation(r.Context())
+			case actionRollback:
+				number, err := strconv.Atoi(r.FormValue("version"))
+				if err == nil {
+					err = m.cfg.Rollback(r.Context(), actor(r), number)
+				}
+				if err != nil {
+					dlog.Error("While rolling back configuration: %v", err)
 				}
 			}
-			w.Header().Set("Location", pathAccess)
+
+			w.Header().Set("Location", pathChanges)
 			w.WriteHeader(http.StatusFound)
 			return
 		}
 
-		v := m.cfg.Validate()
-		username, password := m.cfg.AccessCredentials()
-		runTemplate(w, m.access, AccessPage{
+		versions := m.cfg.Versions()
+		res := make([]VersionChanges, len(versions))
+		for i, v := range versions {
+			res[i].Version = v
+			if i+1 < len(versions) {
+				res[i].Changes = config.Diff(versions[i+1].Data, v.Data)
+			}
+		}
+
+		runTemplate(w, m.changes, ChangesPage{
 			Page:     m.page(r.Context(), r.URL.Path),
-			Username: username,
-			Password: password,
-			Error:    v.Access,
+			Versions: res,
 		})
 	})
 }
 
-func (m *ServeMux) Secured(handler http.Handler) http.Handler {
+// Certificate summarizes an X.509 certificate.
+type Certificate struct {
+	Subject  string
+	Issuer   string
+	NotAfter time.Time
+	Expired  bool
+	Pin      string
+}
+
+func summarizeCertificates(certs []*x509.Certificate) []Certificate {
+	res := make([]Certificate, len(certs))
+	for i, c := range certs {
+		res[i] = Certificate{
+			Subject:  c.Subject.String(),
+			Issuer:   c.Issuer.String(),
+			NotAfter: c.NotAfter,
+			Expired:  time.Now().After(c.NotAfter),
+			Pin:      rest.PublicKeyPin(c),
+		}
+	}
+	return res
+}
+
+type CertificatesPage struct {
+	*Page
+	CACertificates []Certificate
+	Pins           string
+	Inspected      bool
+	ServerChain    []Certificate
+
+	ClientCertificates []Certificate
+	ClientCSR          string
+	CommonName         string
+	Error              error
+}
+
+func (m *ServeMux) CertificatesHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		m.mu.RLock()
-		m.mu.RUnlock()
-
-		username, password := m.cfg.AccessCredentials()
-		if username != "" && password != "" {
-			// access required
-			u, p, _ := r.BasicAuth()
-			if username != u || password != p {
-				w.Header().Set("WWW-Authenticate", `Basic realm="Door2doc Upload Service Configuration"`)
-				w.WriteHeader(http.StatusUnauthorized)
+		defer m.mu.RUnlock()
+
+		var err error
+		if r.Method == http.MethodPost {
+			switch r.FormValue("action") {
+			case "upload":
+				err = m.uploadServerCA(r)
+			case "remove":
+				m.cfg.ClearServerCA(actor(r))
+			case "csr":
+				err = m.cfg.GenerateClientCSR(actor(r), r.FormValue("common_name"), r.FormValue("organization"))
+			case "client":
+				err = m.uploadClientCertificate(r)
+			case "remove-client":
+				m.cfg.ClearClientCertificate(actor(r))
+			default:
+				err = m.cfg.SetServerPins(actor(r), splitLines(r.FormValue("pins")))
+			}
+			if err == nil {
+				m.cfg.UpdateBaseValidation(r.Context())
+
+				w.Header().Set("Location", pathCerts)
+				w.WriteHeader(http.StatusFound)
 				return
 			}
 		}
 
-		handler.ServeHTTP(w, r)
+		draft := m.cfg.Draft()
+		page := CertificatesPage{
+			Page:  m.page(r.Context(), r.URL.Path),
+			Pins:  strings.Join(draft.ServerPins, "\n"),
+			Error: err,
+		}
+		if connErr := m.cfg.Validate().D2DConnection; page.Error == nil && rest.IsTLSError(connErr) {
+			page.Error = connErr
+		}
+		if draft.ServerCAFile != "" {
+			certs, err := rest.ReadCertificates(config.FilePath(draft.ServerCAFile))
+			if err != nil && page.Error == nil {
+				page.Error = &rest.TLSConfigError{Cause: err}
+			}
+			page.CACertificates = summarizeCertificates(certs)
+		}
+		if draft.ClientCertFile != "" {
+			certs, err := rest.ReadCertificates(config.FilePath(draft.ClientCertFile))
+			if err != nil && page.Error == nil {
+				page.Error = &rest.TLSConfigError{Cause: err}
+			}
+			page.ClientCertificates = summarizeCertificates(certs)
+		}
+		if draft.ClientCSRFile != "" {
+			csr, err := ioutil.ReadFile(config.FilePath(draft.ClientCSRFile))
+			if err != nil && page.Error == nil {
+				page.Error = err
+			}
+			page.ClientCSR = string(csr)
+		}
+		page.CommonName = draft.Username
+		if r.FormValue("inspect") != "" {
+			page.Inspected = true
+			endpoint, err := config.Endpoint(draft.Server, config.PathPing)
+			if err == nil {
+				ctx, cancel := context.WithTimeout(r.Context(), draft.Timeout)
+				var certs []*x509.Certificate
+				certs, err = rest.PeerCertificates(ctx, draft.Options(), endpoint)
+				cancel()
+				page.ServerChain = summarizeCertificates(certs)
+			}
+			if err != nil && page.Error == nil {
+				page.Error = err
+			}
+		}
+
+		runTemplate(w, m.certs, page)
+	})
+}
+
+// uploadServerCA stores the certificate bundle uploaded in the request.
+func (m *ServeMux) uploadServerCA(r *http.Request) error {
+	f, _, err := r.FormFile("ca")
+	if err != nil {
+		return err
+	}
+	defer dlog.Close(f)
+
+	bs, err := ioutil.ReadAll(io.LimitReader(f, 1<<20))
+	if err != nil {
+		return err
+	}
+	return m.cfg.SetServerCA(actor(r), bs)
+}
+
+// uploadClientCertificate sets the client certificate uploaded in the request.
+func (m *ServeMux) uploadClientCertificate(r *http.Request) error {
+	f, _, err := r.FormFile("certificate")
+	if err != nil {
+		return err
+	}
+	defer dlog.Close(f)
+
+	bs, err := ioutil.ReadAll(io.LimitReader(f, 1<<20))
+	if err != nil {
+		return err
+	}
+	return m.cfg.SetClientCertificate(actor(r), bs, r.FormValue("certificate_password"))
+}
+
+type AccessPage struct {
+	*Page
+	Users []config.User
+	Roles []string
+	Error error
+
+	Tokens     []config.APIToken
+	NewToken   string
+	TokenError error
+
+	WebServer      config.WebServer
+	Allowlist      string
+	AddressError   error
+	WebCertificate *Certificate
+	Fingerprint    string
+	WebError       error
+}
+
+type HL7Page struct {
+	*Page
+	Listening bool
+	Address   string
+	Messages  []hl7.Entry
+}
+
+func (m *ServeMux) HL7Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		var messages []hl7.Entry
+		if m.messages != nil {
+			messages = m.messages.Entries()
+		}
+		runTemplate(w, m.hl7, HL7Page{
+			Page:      m.page(r.Context(), r.URL.Path),
+			Listening: m.cfg.Active() && m.cfg.VisitorSource() == config.SourceHL7,
+			Address:   m.cfg.HL7Address(),
+			Messages:  messages,
+		})
+	})
+}
+
+func (m *ServeMux) AccessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		var (
+			accessErr  error
+			token      string
+			tokenErr   error
+			addressErr error
+			webErr     error
+		)
+		if r.Method == http.MethodPost {
+			switch r.FormValue("action") {
+			case actionCreateToken:
+				token, tokenErr = m.cfg.CreateAPIToken(actor(r), r.FormValue("token_name"), r.FormValue("token_role"))
+			case actionRevokeToken:
+				tokenErr = m.cfg.RevokeAPIToken(actor(r), r.FormValue("token_name"))
+			case actionWebAddress:
+				addressErr = m.cfg.SetWebAddress(actor(r), strings.TrimSpace(r.FormValue("address")), strings.Split(r.FormValue("allowlist"), "\n"))
+			case actionWebTLS:
+				m.cfg.SetWebTLS(actor(r), r.FormValue("tls") != "", r.FormValue("redirect_http") != "")
+			case actionWebCert:
+				webErr = m.uploadWebCertificate(r)
+			case actionRemoveWebCert:
+				m.cfg.ClearWebCertificate(actor(r))
+			case actionSetUser:
+				accessErr = m.cfg.SetUser(actor(r), r.FormValue("username"), r.FormValue("password"), r.FormValue("role"))
+				m.cfg.UpdateBaseValidation(r.Context())
+			case actionRemoveUser:
+				accessErr = m.cfg.RemoveUser(actor(r), r.FormValue("username"))
+				m.cfg.UpdateBaseValidation(r.Context())
+			}
+
+			// a new token is only shown once, so the page is not reloaded
+			if accessErr == nil && tokenErr == nil && addressErr == nil && webErr == nil && token == "" {
+				w.Header().Set("Location", pathAccess)
+				w.WriteHeader(http.StatusFound)
+				return
+			}
+		}
+
+		v := m.cfg.Validate()
+		draft := m.cfg.Draft()
+		page := AccessPage{
+			Page:         m.page(r.Context(), r.URL.Path),
+			Users:        m.cfg.Users(),
+			Roles:        config.Roles,
+			Error:        v.Access,
+			Tokens:       m.cfg.APITokens(),
+			NewToken:     token,
+			TokenError:   tokenErr,
+			WebServer:    draft.WebServer,
+			Allowlist:    strings.Join(draft.WebServer.Allowlist, "\n"),
+			AddressError: addressErr,
+			WebError:     webErr,
+		}
+		if accessErr != nil {
+			page.Error = accessErr
+		}
+
+		// show the certificate that is used once HTTPS is enabled, generating it if required
+		cert, err := draft.WebServer.Certificate()
+		if err == nil {
+			var leaf *x509.Certificate
+			if leaf, err = x509.ParseCertificate(cert.Certificate[0]); err == nil {
+				page.WebCertificate = &summarizeCertificates([]*x509.Certificate{leaf})[0]
+				page.Fingerprint = config.Fingerprint(leaf)
+			}
+		}
+		if err != nil && page.WebError == nil {
+			page.WebError = err
+		}
+
+		runTemplate(w, m.access, page)
+	})
+}
+
+// uploadWebCertificate stores the certificate for serving the configuration interface uploaded in the request.
+func (m *ServeMux) uploadWebCertificate(r *http.Request) error {
+	f, _, err := r.FormFile("web_certificate")
+	if err != nil {
+		return err
+	}
+	defer dlog.Close(f)
+
+	bs, err := ioutil.ReadAll(io.LimitReader(f, 1<<20))
+	if err != nil {
+		return err
+	}
+	return m.cfg.SetWebCertificate(actor(r), bs, r.FormValue("web_certificate_password"))
+}
+
+// Secured requires users to log in if users have been configured. Viewing a page requires the view role, while
+// posting forms requires the edit role and the CSRF token of the session.
+func (m *ServeMux) Secured(view, edit string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess, err := m.session(w, r)
+		if err != nil {
+			dlog.Error("Failed to start session: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		// sessions of a user that has been removed are no longer valid, and roles are checked on every request
+		user := config.User{Role: config.RoleAdmin}
+		if m.cfg.AccessConfigured() {
+			var ok bool
+			if user, ok = m.cfg.User(sess.User); !ok || sess.User == "" {
+				http.Redirect(w, r, pathLogin+"?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+				return
+			}
+		}
+
+		required := view
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if !sess.validCSRF(r) {
+				http.Error(w, "The form has expired. Please reload the page and try again.", http.StatusForbidden)
+				return
+			}
+			required = edit
+		}
+		if !user.Can(required) {
+			dlog.Info("Denied %s %s to %s, which requires role %s", r.Method, r.URL.Path, user.Name, required)
+			http.Error(w, fmt.Sprintf("This requires the %s role.", required), http.StatusForbidden)
+			return
+		}
+
+		a := &authorization{Session: sess, User: user, Edit: edit}
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authorizationKey{}, a)))
 	})
 }
+
+// Profiling serves profiling data to administrators, once users have to log in, as it exposes the internals of the
+// service.
+func (m *ServeMux) Profiling(handler http.Handler) http.Handler {
+	return m.Secured(config.RoleAdmin, config.RoleAdmin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.cfg.AccessConfigured() {
+			http.NotFound(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+}
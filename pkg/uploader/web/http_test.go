@@ -2,13 +2,17 @@ package web
 
 import (
 	"context"
+	"encoding/json"
 	"html/template"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/db"
 	"github.com/door2doc/d2d-uploader/pkg/uploader/history"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/hl7"
 )
 
 func TestTemplatesDontFail(t *testing.T) {
@@ -18,7 +22,11 @@ func TestTemplatesDontFail(t *testing.T) {
 	cfg := config.NewConfiguration()
 	cfg.UpdateBaseValidation(ctx)
 
-	m, err := NewServeMux(false, "testing", cfg, history.New())
+	h := history.New()
+	h.Finish(h.NewEvent(config.PathVisitorUpload))
+	h.NewEvent(config.PathLabUpload)
+
+	m, err := NewServeMux(false, "testing", cfg, h, hl7.NewLog(), time.UTC)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -30,7 +38,8 @@ func TestTemplatesDontFail(t *testing.T) {
 		"status": {
 			Template: m.status,
 			Page: StatusPage{
-				Page: m.page(ctx, "/"),
+				Page:    m.page(ctx, "/"),
+				History: h,
 			},
 		},
 		"query": {
@@ -39,12 +48,105 @@ func TestTemplatesDontFail(t *testing.T) {
 				Page: m.page(ctx, "/"),
 			},
 		},
+		"query draft": {
+			Template: m.query,
+			Page: QueryPage{
+				Page: m.page(ctx, "/"),
+				Draft: &config.QueryPreview{
+					Results: db.VisitorRecords{{Bezoeknummer: 1}, {Bezoeknummer: 2}},
+					Records: []json.RawMessage{[]byte(`{"bezoeknummer":1}`), []byte(`{"bezoeknummer":2}`)},
+				},
+				Pagination: Pagination{Number: 2, Count: 2, Size: 1},
+			},
+		},
+		"query draft truncated": {
+			Template: m.radiology,
+			Page: QueryPage{
+				Page: m.page(ctx, "/"),
+				Draft: &config.QueryPreview{
+					Results:   db.RadiologieOrders{{Bezoeknummer: 1}},
+					Records:   []json.RawMessage{[]byte(`{"bezoeknummer":1}`)},
+					Truncated: true,
+				},
+				Pagination: Pagination{Number: 1, Count: 1, Size: 1},
+			},
+		},
+		"query draft error": {
+			Template: m.lab,
+			Page: QueryPage{
+				Page: m.page(ctx, "/"),
+				Draft: &config.QueryPreview{
+					QueryError: config.ErrQueryNotConfigured,
+				},
+			},
+		},
+		"changes": {
+			Template: m.changes,
+			Page: ChangesPage{
+				Page: m.page(ctx, "/changes"),
+				Versions: []VersionChanges{
+					{Version: config.Version{Number: 2}, Changes: []config.Change{{Field: "Proxy", New: "proxy"}}},
+					{Version: config.Version{Number: 1}},
+				},
+			},
+		},
 		"database": {
 			Template: m.database,
 			Page: DatabasePage{
 				Page: m.page(ctx, "/"),
 			},
 		},
+		"hl7": {
+			Template: m.hl7,
+			Page: HL7Page{
+				Page: m.page(ctx, "/hl7"),
+				Messages: []hl7.Entry{
+					{Time: time.Now(), ControlID: "1", Type: "ADT", Event: "A01", Visit: 42, Ack: hl7.AckAccept},
+					{Time: time.Now(), ControlID: "2", Type: "ADT", Event: "A05", Ack: hl7.AckReject, Error: "unsupported"},
+				},
+			},
+		},
+		"access": {
+			Template: m.access,
+			Page: AccessPage{
+				Page:     m.page(ctx, "/access"),
+				Users:    []config.User{{Name: "admin", Role: config.RoleAdmin}, {Name: "support", Role: config.RoleViewer}},
+				Roles:    config.Roles,
+				Tokens:   []config.APIToken{{Name: "rollout", Created: time.Now(), CreatedBy: "admin"}},
+				NewToken: "d2d_token",
+			},
+		},
+		"audit": {
+			Template: m.audit,
+			Page: AuditPage{
+				Page: m.page(ctx, "/audit"),
+				Entries: []config.AuditEntry{
+					{Time: time.Now(), User: "admin", Source: "127.0.0.1", Action: config.AuditApplied, Version: 2, Field: "Timeout", Old: "5s", New: "10s"},
+					{Time: time.Now(), User: "admin", Source: "127.0.0.1", Action: config.AuditDraft, Field: "Timeout", Old: "5s", New: "10s"},
+				},
+				Pagination: Pagination{Number: 1, Count: 2, Size: 50},
+			},
+		},
+		"login": {
+			Template: m.login,
+			Page: LoginPage{
+				Version:   "testing",
+				CSRFToken: "token",
+				Next:      "/",
+				Error:     &LoginLockedError{Remaining: time.Minute},
+			},
+		},
+		"status datasets": {
+			Template: m.status,
+			Page: StatusPage{
+				Page: m.page(ctx, "/"),
+				Datasets: []DatasetStatus{
+					{Name: config.DatasetVisits, Title: "Visits"},
+					{Name: config.DatasetLab, Title: "Lab orders", Paused: true, Pause: config.Pause{Since: time.Now(), By: "admin", Reason: "migration"}},
+				},
+				Error: config.ErrDatasetPaused,
+			},
+		},
 		"upload": {
 			Template: m.upload,
 			Page: UploadPage{
@@ -64,3 +166,58 @@ func TestTemplatesDontFail(t *testing.T) {
 		})
 	}
 }
+
+func TestPagination(t *testing.T) {
+	for name, test := range map[string]struct {
+		URL    string
+		Length int
+		Want   Pagination
+	}{
+		"empty":         {URL: "/query", Length: 0, Want: Pagination{Number: 1, Count: 1, Size: 10}},
+		"first page":    {URL: "/query", Length: 25, Want: Pagination{Number: 1, Count: 3, Size: 10}},
+		"last page":     {URL: "/query?page=3", Length: 25, Want: Pagination{Number: 3, Count: 3, Size: 10}},
+		"out of bounds": {URL: "/query?page=4", Length: 25, Want: Pagination{Number: 1, Count: 3, Size: 10}},
+		"invalid":       {URL: "/query?page=x", Length: 25, Want: Pagination{Number: 1, Count: 3, Size: 10}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, test.URL, nil)
+			if got := newPagination(r, test.Length, 10); got != test.Want {
+				t.Errorf("newPagination() == %v, got %v", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestServeMux_Preview(t *testing.T) {
+	m, err := NewServeMux(false, "testing", config.NewConfiguration(), history.New(), hl7.NewLog(), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(id string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, pathQuery, nil)
+		return r.WithContext(context.WithValue(r.Context(), authorizationKey{}, &authorization{Session: &session{CSRF: id}}))
+	}
+	alice, bob := request("alice"), request("bob")
+
+	p := &config.QueryPreview{Query: "select 1"}
+	m.setPreview(alice, pathQuery, p)
+	if got := m.preview(alice, pathQuery); got != p {
+		t.Errorf("preview() == %v, got %v", p, got)
+	}
+	if got := m.preview(alice, pathLab); got != nil {
+		t.Errorf("preview() of another page == nil, got %v", got)
+	}
+	if got := m.preview(bob, pathQuery); got != nil {
+		t.Errorf("preview() of another session == nil, got %v", got)
+	}
+
+	// expired drafts are not shown, and are removed when another draft is tested
+	m.previews[newPreviewKey(alice, pathQuery)] = draftPreview{preview: p, expires: time.Now().Add(-time.Second)}
+	if got := m.preview(alice, pathQuery); got != nil {
+		t.Errorf("preview() of an expired draft == nil, got %v", got)
+	}
+	m.setPreview(bob, pathQuery, p)
+	if n := len(m.previews); n != 1 {
+		t.Errorf("len(previews) == 1, got %d", n)
+	}
+}
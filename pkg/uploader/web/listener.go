@@ -0,0 +1,143 @@
+package web
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+)
+
+// sniffTimeout limits the time spent waiting for the first byte of a connection.
+const sniffTimeout = 10 * time.Second
+
+// recordTypeHandshake is the first byte sent by TLS clients.
+const recordTypeHandshake = 0x16
+
+// SplitListener separates the connections accepted by ln into TLS connections and plain HTTP connections, based on the
+// first byte sent by the client. This allows plain HTTP requests on the HTTPS port to be redirected. Closing either
+// listener closes ln.
+func SplitListener(ln net.Listener) (tlsLn, plainLn net.Listener) {
+	s := &splitter{
+		ln:    ln,
+		tls:   make(chan net.Conn),
+		plain: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+	go s.accept()
+	return &splitListener{s: s, conns: s.tls}, &splitListener{s: s, conns: s.plain}
+}
+
+type splitter struct {
+	ln         net.Listener
+	tls, plain chan net.Conn
+
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+func (s *splitter) accept() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			s.close(err)
+			return
+		}
+		go s.sniff(conn)
+	}
+}
+
+// sniff reads the first byte of a connection, and passes it on to the matching listener.
+func (s *splitter) sniff(conn net.Conn) {
+	br := bufio.NewReader(conn)
+	_ = conn.SetReadDeadline(time.Now().Add(sniffTimeout))
+	first, err := br.Peek(1)
+	_ = conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	target := s.plain
+	if first[0] == recordTypeHandshake {
+		target = s.tls
+	}
+	select {
+	case target <- &peekedConn{Conn: conn, r: br}:
+	case <-s.done:
+		_ = conn.Close()
+	}
+}
+
+func (s *splitter) close(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.done)
+		_ = s.ln.Close()
+	})
+}
+
+type splitListener struct {
+	s     *splitter
+	conns chan net.Conn
+}
+
+func (l *splitListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.s.done:
+		return nil, l.s.err
+	}
+}
+
+func (l *splitListener) Close() error {
+	l.s.close(net.ErrClosed)
+	return nil
+}
+
+func (l *splitListener) Addr() net.Addr {
+	return l.s.ln.Addr()
+}
+
+// peekedConn returns the bytes read while sniffing before reading from the connection itself.
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *peekedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// RedirectHTTPS redirects all requests to the same URL using HTTPS.
+func RedirectHTTPS() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := *r.URL
+		u.Scheme = "https"
+		u.Host = r.Host
+		http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+	})
+}
+
+// AllowedClients rejects requests from clients that are not on the allowlist of the web server configuration. The
+// active configuration is used, such that changes to the allowlist take effect once applied.
+func AllowedClients(cfg *config.Configuration, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if !cfg.WebServer().Allowed(net.ParseIP(host)) {
+			dlog.Info("Rejected request for %s from %s, which is not on the allowlist", r.URL.Path, host)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,44 @@
+package web
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSplitListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsLn, plainLn := SplitListener(ln)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.RequestURI())
+	}))
+	ts.Listener = tlsLn
+	ts.StartTLS()
+	defer ts.Close()
+	go func() {
+		_ = http.Serve(plainLn, RedirectHTTPS())
+	}()
+
+	res, err := ts.Client().Get("http://" + ln.Addr().String() + "/status?page=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Request.URL.Scheme != "https" {
+		t.Errorf("scheme == https, got %s", res.Request.URL.Scheme)
+	}
+	if string(body) != "/status?page=2" {
+		t.Errorf("body == /status?page=2, got %s", body)
+	}
+}
@@ -0,0 +1,184 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/dlog"
+)
+
+const (
+	pathLogin  = "/login"
+	pathLogout = "/logout"
+
+	// maxLoginFailures is the number of failed logins from a client before it is locked out. The lockout starts at
+	// loginLockout, and doubles with every further failure up to maxLoginLockout.
+	maxLoginFailures = 5
+	loginLockout     = time.Minute
+	maxLoginLockout  = time.Hour
+)
+
+var (
+	errLoginFailed    = errors.New("invalid username or password")
+	errSessionExpired = errors.New("session expired")
+)
+
+// LoginLockedError indicates that a client has been locked out after too many failed logins.
+type LoginLockedError struct {
+	Remaining time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed logins, locked for %s", e.Remaining)
+}
+
+// loginLimiter locks out clients after too many failed logins.
+type loginLimiter struct {
+	mu      sync.Mutex
+	clients map[string]*loginFailures
+}
+
+type loginFailures struct {
+	count int
+	last  time.Time
+	until time.Time
+}
+
+func newLoginLimiter() *loginLimiter {
+	return &loginLimiter{clients: make(map[string]*loginFailures)}
+}
+
+// locked returns how long a client remains locked out.
+func (l *loginLimiter) locked(client string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if f, ok := l.clients[client]; ok && now.Before(f.until) {
+		return f.until.Sub(now)
+	}
+	return 0
+}
+
+// fail records a failed login. Failures are forgotten once a client has not failed for maxLoginLockout.
+func (l *loginLimiter) fail(client string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for c, f := range l.clients {
+		if now.Sub(f.last) > maxLoginLockout && now.After(f.until) {
+			delete(l.clients, c)
+		}
+	}
+
+	f, ok := l.clients[client]
+	if !ok {
+		f = new(loginFailures)
+		l.clients[client] = f
+	}
+	f.count++
+	f.last = now
+	if f.count >= maxLoginFailures {
+		lockout := maxLoginLockout
+		if shift := f.count - maxLoginFailures; shift < 6 {
+			lockout = min(loginLockout<<shift, maxLoginLockout)
+		}
+		f.until = now.Add(lockout)
+	}
+}
+
+// succeed forgets the failed logins of a client.
+func (l *loginLimiter) succeed(client string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.clients, client)
+}
+
+type LoginPage struct {
+	Version   string
+	CSRFToken string
+	Username  string
+	Next      string
+	Error     error
+}
+
+// LoginHandler shows the login form, and starts a new session for users that log in successfully.
+func (m *ServeMux) LoginHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+
+		next := r.FormValue("next")
+		if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+			next = "/"
+		}
+		if !m.cfg.AccessConfigured() {
+			http.Redirect(w, r, next, http.StatusFound)
+			return
+		}
+
+		sess, err := m.session(w, r)
+		if err != nil {
+			dlog.Error("Failed to start session: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		username := r.FormValue("username")
+		if r.Method == http.MethodPost {
+			now := time.Now()
+			client := clientIP(r)
+			if remaining := m.logins.locked(client, now); remaining > 0 {
+				err = &LoginLockedError{Remaining: remaining}
+			} else if !sess.validCSRF(r) {
+				err = errSessionExpired
+			} else if _, ok := m.cfg.CheckAccess(username, r.FormValue("password")); !ok {
+				m.logins.fail(client, now)
+				dlog.Info("Failed login for %s from %s", username, client)
+				err = errLoginFailed
+			} else {
+				m.logins.succeed(client)
+				if sess.ID != "" {
+					m.sessions.delete(sess.ID)
+				}
+				if _, err = m.startSession(w, r, username); err == nil {
+					dlog.Info("%s logged in from %s", username, client)
+					http.Redirect(w, r, next, http.StatusFound)
+					return
+				}
+			}
+		}
+
+		runTemplate(w, m.login, LoginPage{
+			Version:   m.version,
+			CSRFToken: sess.CSRF,
+			Username:  username,
+			Next:      next,
+			Error:     err,
+		})
+	})
+}
+
+// LogoutHandler ends the session of the user. It only accepts the logout form, such that other sites cannot end the
+// session with a link or a forged form.
+func (m *ServeMux) LogoutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		a := authorizationFrom(r.Context())
+		if a == nil || !a.Session.validCSRF(r) {
+			http.Error(w, "The form has expired. Please reload the page and try again.", http.StatusForbidden)
+			return
+		}
+		m.sessions.delete(a.Session.ID)
+		http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/", MaxAge: -1})
+		http.Redirect(w, r, pathLogin, http.StatusFound)
+	})
+}
@@ -0,0 +1,245 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/history"
+	"github.com/door2doc/d2d-uploader/pkg/uploader/hl7"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	l := newLoginLimiter()
+	now := time.Now()
+
+	for i := 1; i < maxLoginFailures; i++ {
+		l.fail("10.0.0.1", now)
+	}
+	if got := l.locked("10.0.0.1", now); got != 0 {
+		t.Errorf("locked() before %d failures == 0, got %s", maxLoginFailures, got)
+	}
+
+	l.fail("10.0.0.1", now)
+	if got := l.locked("10.0.0.1", now); got != loginLockout {
+		t.Errorf("locked() == %s, got %s", loginLockout, got)
+	}
+	if got := l.locked("10.0.0.2", now); got != 0 {
+		t.Errorf("locked() for other client == 0, got %s", got)
+	}
+
+	now = now.Add(loginLockout)
+	l.fail("10.0.0.1", now)
+	if got := l.locked("10.0.0.1", now); got != 2*loginLockout {
+		t.Errorf("locked() after further failure == %s, got %s", 2*loginLockout, got)
+	}
+	for i := 0; i < 10; i++ {
+		l.fail("10.0.0.1", now)
+	}
+	if got := l.locked("10.0.0.1", now); got != maxLoginLockout {
+		t.Errorf("locked() == %s, got %s", maxLoginLockout, got)
+	}
+
+	l.succeed("10.0.0.1")
+	if got := l.locked("10.0.0.1", now); got != 0 {
+		t.Errorf("locked() after success == 0, got %s", got)
+	}
+}
+
+var csrfPattern = regexp.MustCompile(`name="csrf_token" value="([^"]+)"`)
+
+func TestSecured(t *testing.T) {
+	cfg := config.NewConfiguration()
+	if err := json.Unmarshal([]byte(`{"version": 2, "users": [
+		{"name": "admin", "password_hash": {"plain_text": "secret"}, "role": "admin"},
+		{"name": "support", "password_hash": {"plain_text": "support"}, "role": "viewer"}
+	]}`), cfg); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewServeMux(false, "testing", cfg, history.New(), hl7.NewLog(), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	do := func(method, path string, cookie *http.Cookie, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+		return rec
+	}
+	cookieOf := func(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+		for _, c := range rec.Result().Cookies() {
+			if c.Name == name {
+				return c
+			}
+		}
+		t.Fatalf("no %s cookie", name)
+		return nil
+	}
+	sessionOf := func(rec *httptest.ResponseRecorder) *http.Cookie {
+		return cookieOf(rec, sessionCookie)
+	}
+
+	// pages require a login
+	rec := do(http.MethodGet, "/database", nil, nil)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/login?next=%2Fdatabase" {
+		t.Fatalf("GET /database == 302 /login?next=%%2Fdatabase, got %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+	rec = do(http.MethodGet, "/debug/pprof/", nil, nil)
+	if rec.Code != http.StatusFound {
+		t.Errorf("GET /debug/pprof/ == 302, got %d", rec.Code)
+	}
+
+	// the login form contains a CSRF token, which is kept in a cookie until the user has logged in
+	rec = do(http.MethodGet, "/login", nil, nil)
+	anonymous := cookieOf(rec, csrfCookie)
+	match := csrfPattern.FindStringSubmatch(rec.Body.String())
+	if match == nil || match[1] != anonymous.Value {
+		t.Fatal("login form does not contain the CSRF token of the cookie")
+	}
+	if n := len(m.sessions.byID); n != 0 {
+		t.Errorf("sessions are not stored before logging in, got %d", n)
+	}
+
+	rec = do(http.MethodPost, "/login", anonymous, url.Values{"username": {"admin"}, "password": {"secret"}})
+	if !strings.Contains(rec.Body.String(), "expired") {
+		t.Errorf("POST /login without CSRF token fails")
+	}
+	rec = do(http.MethodPost, "/login", anonymous, url.Values{"csrf_token": {match[1]}, "username": {"admin"}, "password": {"wrong"}})
+	if !strings.Contains(rec.Body.String(), "Invalid username or password") {
+		t.Errorf("POST /login with wrong password fails")
+	}
+	rec = do(http.MethodPost, "/login", anonymous, url.Values{"csrf_token": {match[1]}, "username": {"admin"}, "password": {"secret"}, "next": {"/database"}})
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/database" {
+		t.Fatalf("POST /login == 302 /database, got %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+	session := sessionOf(rec)
+	if n := len(m.sessions.byID); n != 1 {
+		t.Errorf("login stores a session, got %d", n)
+	}
+
+	// pages can be used after logging in, but forms require the CSRF token
+	rec = do(http.MethodGet, "/debug/pprof/", session, nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /debug/pprof/ == 200, got %d", rec.Code)
+	}
+	csrf := m.sessions.get(session.Value, time.Now()).CSRF
+	rec = do(http.MethodPost, "/", session, url.Values{"action": {"resume"}, "dataset": {config.DatasetLab}})
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("POST / without CSRF token == 403, got %d", rec.Code)
+	}
+	rec = do(http.MethodPost, "/", session, url.Values{"csrf_token": {csrf}, "action": {"resume"}, "dataset": {config.DatasetLab}})
+	if rec.Code != http.StatusFound {
+		t.Errorf("POST / == 302, got %d", rec.Code)
+	}
+
+	// logging out ends the session, but only using the logout form
+	rec = do(http.MethodGet, "/logout", session, nil)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /logout == 405, got %d", rec.Code)
+	}
+	rec = do(http.MethodPost, "/logout", session, nil)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("POST /logout without CSRF token == 403, got %d", rec.Code)
+	}
+	if m.sessions.get(session.Value, time.Now()) == nil {
+		t.Errorf("POST /logout without CSRF token keeps the session")
+	}
+	rec = do(http.MethodPost, "/logout", session, url.Values{"csrf_token": {csrf}})
+	if rec.Code != http.StatusFound {
+		t.Errorf("POST /logout == 302, got %d", rec.Code)
+	}
+	rec = do(http.MethodGet, "/database", session, nil)
+	if rec.Code != http.StatusFound {
+		t.Errorf("GET /database after logout == 302, got %d", rec.Code)
+	}
+
+	// viewers cannot make changes
+	rec = do(http.MethodGet, "/login", nil, nil)
+	anonymous = cookieOf(rec, csrfCookie)
+	match = csrfPattern.FindStringSubmatch(rec.Body.String())
+	rec = do(http.MethodPost, "/login", anonymous, url.Values{"csrf_token": {match[1]}, "username": {"support"}, "password": {"support"}})
+	if rec.Code != http.StatusFound {
+		t.Fatalf("POST /login == 302, got %d", rec.Code)
+	}
+	session = sessionOf(rec)
+	csrf = m.sessions.get(session.Value, time.Now()).CSRF
+	for _, test := range []struct {
+		Method, Path string
+		Status       int
+	}{
+		{Method: http.MethodPost, Path: "/", Status: http.StatusForbidden},
+		{Method: http.MethodGet, Path: "/access", Status: http.StatusForbidden},
+		{Method: http.MethodGet, Path: "/debug/pprof/", Status: http.StatusForbidden},
+		{Method: http.MethodPost, Path: "/logout", Status: http.StatusFound},
+	} {
+		rec = do(test.Method, test.Path, session, url.Values{"csrf_token": {csrf}, "action": {"resume"}, "dataset": {config.DatasetLab}})
+		if rec.Code != test.Status {
+			t.Errorf("%s %s as viewer == %d, got %d", test.Method, test.Path, test.Status, rec.Code)
+		}
+	}
+}
+
+func TestSessions(t *testing.T) {
+	s := newSessions()
+	now := time.Now()
+
+	var first *session
+	for i := 0; i < maxSessions+1; i++ {
+		sess, err := s.create("admin", now.Add(time.Duration(i)*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = sess
+		}
+	}
+	if n := len(s.byID); n != maxSessions {
+		t.Errorf("len(sessions) == %d, got %d", maxSessions, n)
+	}
+	if s.get(first.ID, now) != nil {
+		t.Error("the least recently used session ends once the limit is reached")
+	}
+
+	// expired sessions are removed when a session is created
+	if _, err := s.create("admin", now.Add(sessionMaxAge+time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.byID); n != 1 {
+		t.Errorf("len(sessions) == 1, got %d", n)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	for name, test := range map[string]struct {
+		Role string
+		Want string
+	}{
+		"admin":    {Role: config.RoleAdmin, Want: "secret"},
+		"operator": {Role: config.RoleOperator, Want: secretMask},
+		"viewer":   {Role: config.RoleViewer, Want: secretMask},
+	} {
+		t.Run(name, func(t *testing.T) {
+			a := &authorization{User: config.User{Name: name, Role: test.Role}}
+			r := httptest.NewRequest(http.MethodGet, "/upload", nil)
+			r = r.WithContext(context.WithValue(r.Context(), authorizationKey{}, a))
+			if got := secret(r, "secret"); got != test.Want {
+				t.Errorf("secret() == %s, got %s", test.Want, got)
+			}
+			if got := secret(r, ""); got != "" {
+				t.Errorf("secret() of empty secret is empty, got %s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/door2doc/d2d-uploader/pkg/uploader/config"
+)
+
+const (
+	sessionCookie = "d2d_session"
+	// csrfCookie contains the CSRF token of browsers that have not logged in.
+	csrfCookie = "d2d_csrf"
+
+	// sessionIdleTimeout and sessionMaxAge limit how long a session can be used.
+	sessionIdleTimeout = 30 * time.Minute
+	sessionMaxAge      = 12 * time.Hour
+	// maxSessions limits the number of sessions kept in memory. When it is reached, the least recently used session
+	// ends.
+	maxSessions = 1000
+
+	// csrfField is the form field that contains the CSRF token of the session. All forms that are posted must contain
+	// it.
+	csrfField = "csrf_token"
+)
+
+// session is created for each browser using the web interface. Only sessions of users that have logged in are stored;
+// the sessions of other browsers have no ID, and User is empty.
+type session struct {
+	ID      string
+	User    string
+	CSRF    string
+	Created time.Time
+	Seen    time.Time
+}
+
+// authorization is attached to requests that passed Secured.
+type authorization struct {
+	Session *session
+
+	// User is the user that has logged in. If users do not have to log in, it is an anonymous administrator.
+	User config.User
+
+	// Edit is the role required for making changes on the requested page.
+	Edit string
+}
+
+type authorizationKey struct{}
+
+// authorizationFrom returns the authorization of a request that passed Secured, or nil.
+func authorizationFrom(ctx context.Context) *authorization {
+	a, _ := ctx.Value(authorizationKey{}).(*authorization)
+	return a
+}
+
+// can returns true if the user that sent the request has role. Requests that did not pass Secured have no role.
+func can(r *http.Request, role string) bool {
+	a := authorizationFrom(r.Context())
+	return a != nil && a.User.Can(role)
+}
+
+// secretMask is shown instead of secrets to users that may not see them.
+const secretMask = "********"
+
+// secret returns s if the user that sent the request is an administrator, and a mask otherwise.
+func secret(r *http.Request, s string) string {
+	if s == "" || can(r, config.RoleAdmin) {
+		return s
+	}
+	return secretMask
+}
+
+// sessions keeps all sessions in memory, such that users have to log in again after the service restarts.
+type sessions struct {
+	mu   sync.Mutex
+	byID map[string]*session
+}
+
+func newSessions() *sessions {
+	return &sessions{byID: make(map[string]*session)}
+}
+
+// create starts a new session, removing expired sessions. Sessions are only created when users log in.
+func (s *sessions) create(user string, now time.Time) (*session, error) {
+	id, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	csrf, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	sess := &session{ID: id, User: user, CSRF: csrf, Created: now, Seen: now}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var oldest *session
+	for id, other := range s.byID {
+		if other.expired(now) {
+			delete(s.byID, id)
+		} else if oldest == nil || other.Seen.Before(oldest.Seen) {
+			oldest = other
+		}
+	}
+	if len(s.byID) >= maxSessions {
+		delete(s.byID, oldest.ID)
+	}
+	s.byID[sess.ID] = sess
+	return sess, nil
+}
+
+// get returns a copy of the session with the given ID, or nil if it does not exist or has expired.
+func (s *sessions) get(id string, now time.Time) *session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.byID[id]
+	if !ok {
+		return nil
+	}
+	if sess.expired(now) {
+		delete(s.byID, id)
+		return nil
+	}
+	sess.Seen = now
+	res := *sess
+	return &res
+}
+
+func (s *sessions) delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.byID, id)
+}
+
+func (s *session) expired(now time.Time) bool {
+	return now.Sub(s.Seen) > sessionIdleTimeout || now.Sub(s.Created) > sessionMaxAge
+}
+
+// validCSRF returns true if the request contains the CSRF token of the session.
+func (s *session) validCSRF(r *http.Request) bool {
+	return subtle.ConstantTimeCompare([]byte(r.FormValue(csrfField)), []byte(s.CSRF)) == 1
+}
+
+func randomToken() (string, error) {
+	bs := make([]byte, 32)
+	if _, err := rand.Read(bs); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bs), nil
+}
+
+// session returns the session of the browser. Browsers that have not logged in get a session that is not stored,
+// such that requests without a cookie cannot fill the memory. Its CSRF token is kept in a cookie instead, which forms
+// must repeat: other sites can post forms, but cannot read the cookie.
+func (m *ServeMux) session(w http.ResponseWriter, r *http.Request) (*session, error) {
+	now := time.Now()
+	if c, err := r.Cookie(sessionCookie); err == nil {
+		if sess := m.sessions.get(c.Value, now); sess != nil {
+			return sess, nil
+		}
+	}
+	if c, err := r.Cookie(csrfCookie); err == nil && c.Value != "" {
+		return &session{CSRF: c.Value, Created: now, Seen: now}, nil
+	}
+
+	csrf, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    csrf,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return &session{CSRF: csrf, Created: now, Seen: now}, nil
+}
+
+// startSession starts a new session for user, replacing the session of the browser.
+func (m *ServeMux) startSession(w http.ResponseWriter, r *http.Request, user string) (*session, error) {
+	sess, err := m.sessions.create(user, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    sess.ID,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return sess, nil
+}